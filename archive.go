@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// confirmArchive asks before archiving items: downloading them, then deleting
+// from Dropbox each file whose download checks out. It needs allow_archive,
+// since it deletes.
+func (m Model) confirmArchive(items []FileItem) (tea.Model, tea.Cmd) {
+	switch {
+	case m.config.link != nil:
+		return m, linkReadOnlyCmd()
+	case !m.config.AllowArchive:
+		m.error = "Archiving deletes files from Dropbox; set allow_archive: true in the settings to use it"
+		m.errorTime = time.Now()
+		return m, nil
+	case m.job != "":
+		m.error = "Wait for the current " + m.job + " to finish"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.pendingArchive = items
+	m.openPrompt(promptConfirmArchive)
+	return m, nil
+}
+
+// answerArchive handles y/n at the archive confirmation prompt. Other keys
+// leave the prompt open.
+func (m Model) answerArchive(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		items := m.pendingArchive
+		m.pendingArchive = nil
+		m.closePrompt()
+		m.deselect(items)
+		return m, func() tea.Msg {
+			return DownloadMsg{Files: items, Archive: true}
+		}
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
+
+// archiveFiles downloads fileItems like any download, then deletes from
+// Dropbox each file that's now here, one at a time, once archiveFile has
+// checked the local copy against it. A file that failed to download, or that
+// doesn't check out, is left in Dropbox with an error saying why. Folders are
+// left in place, as are files a .dboxignore leaves out. Archived files are
+// recorded in the download index, so a later download of another file with
+// the same local path is renamed rather than saved over the only copy left;
+// if the index can't be read, nothing is downloaded or deleted.
+func archiveFiles(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress) DownloadCompleteMsg {
+	idx, err := loadDownloadIndex()
+	if err != nil {
+		// Without the index, files earlier archives left here could be
+		// overwritten.
+		return DownloadCompleteMsg{Errors: []ItemError{{Err: "Archived nothing: " + err.Error()}}}
+	}
+	result := downloadFiles(dbx, fileItems, config, progress, idx)
+	cwd, _ := os.Getwd()
+	ignore, err := loadIgnoreRules(config.DownloadPath, cwd)
+	if err != nil {
+		// Without the patterns, ignored files can't be told apart.
+		result.Errors = append(result.Errors, ItemError{Err: "Archived nothing: " + err.Error()})
+		return result
+	}
+	result.ArchivedRevs = map[string]string{}
+	for _, items := range [][]FileItem{result.Downloaded, result.Skipped} {
+		for _, item := range items {
+			if item.IsFolder || ignore.matches(item) {
+				continue
+			}
+			localPath := result.localPath(config, item)
+			file, err := archiveFile(dbx, item, localPath)
+			if err != nil {
+				result.Errors = append(result.Errors, ItemError{Item: item, Err: fmt.Sprintf("Kept %s in Dropbox: %v", item.Name, err)})
+				continue
+			}
+			item.ContentHash = file.ContentHash
+			idx.record(localPath, item)
+			result.Archived = append(result.Archived, item)
+			result.ArchivedRevs[item.Path] = file.Rev
+		}
+	}
+	if err := idx.save(); err != nil {
+		result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to update download index: %v", err)})
+	}
+	return result
+}
+
+// archiveFile deletes item from Dropbox once the file at localPath is known
+// to be the same: its content hash has to match the one Dropbox has for the
+// file now, and the delete only goes ahead if the file is still at that
+// revision. It returns the file as it was deleted, whose revision undo
+// restores.
+func archiveFile(dbx files.Client, item FileItem, localPath string) (*files.FileMetadata, error) {
+	if item.Exportable {
+		return nil, errors.New("Paper docs are exported, so their download can't be verified")
+	}
+	if localPath == "" {
+		return nil, errors.New("no local copy")
+	}
+	localHash, err := dropboxContentHash(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't hash the local copy: %w", err)
+	}
+	meta, err := dbx.GetMetadata(files.NewGetMetadataArg(item.Path))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't check it in Dropbox: %w", err)
+	}
+	file, ok := meta.(*files.FileMetadata)
+	if !ok {
+		return nil, errors.New("it's no longer a file")
+	}
+	if file.ContentHash == "" || file.ContentHash != localHash {
+		return nil, errors.New("the local copy doesn't match it")
+	}
+	arg := files.NewDeleteArg(item.Path)
+	arg.ParentRev = file.Rev
+	if _, err := dbx.DeleteV2(arg); err != nil {
+		return nil, fmt.Errorf("delete failed: %w", err)
+	}
+	return file, nil
+}
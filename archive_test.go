@@ -0,0 +1,159 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeArchiveClient serves downloads from contents by path, reports hashes
+// for GetMetadata (the real hash of the contents, unless overridden in
+// hashes), and records deletes. Any other method panics via the nil embedded
+// interface.
+type fakeArchiveClient struct {
+	files.Client
+	contents map[string]string
+	hashes   map[string]string
+	deleted  []*files.DeleteArg
+}
+
+func (f *fakeArchiveClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	body, ok := f.contents[arg.Path]
+	if !ok {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return &files.FileMetadata{Size: uint64(len(body))}, io.NopCloser(strings.NewReader(body)), nil
+}
+
+func (f *fakeArchiveClient) GetMetadata(arg *files.GetMetadataArg) (files.IsMetadata, error) {
+	hash, ok := f.hashes[arg.Path]
+	if !ok {
+		hash = contentHashOf(f.contents[arg.Path])
+	}
+	return &files.FileMetadata{Metadata: files.Metadata{PathLower: arg.Path}, ContentHash: hash, Rev: "rev-" + arg.Path}, nil
+}
+
+func (f *fakeArchiveClient) DeleteV2(arg *files.DeleteArg) (*files.DeleteResult, error) {
+	f.deleted = append(f.deleted, arg)
+	return &files.DeleteResult{}, nil
+}
+
+// contentHashOf returns the Dropbox content hash of s.
+func contentHashOf(s string) string {
+	f, _ := os.CreateTemp("", "hash")
+	defer os.Remove(f.Name())
+	f.WriteString(s)
+	f.Close()
+	hash, _ := dropboxContentHash(f.Name())
+	return hash
+}
+
+func TestArchiveFiles(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	dbx := &fakeArchiveClient{
+		contents: map[string]string{"/a.txt": "hello", "/b.txt": "world"},
+		hashes:   map[string]string{"/b.txt": "changed-since"},
+	}
+	items := []FileItem{
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+		{Name: "b.txt", Path: "/b.txt", Size: 5},
+		{Name: "gone.txt", Path: "/gone.txt", Size: 5}, // fails to download
+	}
+	result := archiveFiles(dbx, items, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()))
+
+	if len(dbx.deleted) != 1 || dbx.deleted[0].Path != "/a.txt" || dbx.deleted[0].ParentRev != "rev-/a.txt" {
+		t.Fatalf("deleted = %+v, want only a.txt, at the revision that was checked", dbx.deleted)
+	}
+	if len(result.Archived) != 1 || result.ArchivedRevs["/a.txt"] != "rev-/a.txt" {
+		t.Errorf("archived = %+v, revs %v", result.Archived, result.ArchivedRevs)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "hello" {
+		t.Errorf("a.txt = %q, want the local copy kept", data)
+	}
+	var errs []string
+	for _, e := range result.Errors {
+		errs = append(errs, e.Err)
+	}
+	if got := strings.Join(errs, "\n"); !strings.Contains(got, "Kept b.txt in Dropbox: the local copy doesn't match it") ||
+		!strings.Contains(got, "Failed to download gone.txt") {
+		t.Errorf("errors = %s", got)
+	}
+
+	// Paper docs can't be compared, so they're never deleted.
+	if _, err := archiveFile(dbx, FileItem{Name: "doc.paper", Path: "/doc.paper", Exportable: true}, filepath.Join(dir, "a.txt")); err == nil {
+		t.Error("a Paper doc shouldn't be archived")
+	}
+}
+
+func TestArchiveKeepsEarlierArchives(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	dir := t.TempDir()
+	config := &Config{DownloadPath: dir, OrganizeByExtension: true}
+	dbx := &fakeArchiveClient{contents: map[string]string{"/2023/report.pdf": "old", "/2024/report.pdf": "new"}}
+
+	// Both land in pdf/, and the first is gone from Dropbox once archived, so
+	// the second mustn't be saved over it.
+	archiveFiles(dbx, []FileItem{{Name: "report.pdf", Path: "/2023/report.pdf", Size: 3}}, config, newDownloadProgress(time.Now()))
+	result := archiveFiles(dbx, []FileItem{{Name: "report.pdf", Path: "/2024/report.pdf", Size: 3}}, config, newDownloadProgress(time.Now()))
+
+	if len(result.Errors) > 0 || len(dbx.deleted) != 2 {
+		t.Fatalf("errors = %+v, deleted %+v", result.Errors, dbx.deleted)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "pdf", "report.pdf")); string(data) != "old" {
+		t.Errorf("report.pdf = %q, want the first archive kept", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "pdf", "report (2024).pdf")); string(data) != "new" {
+		t.Errorf("report (2024).pdf = %q, want the second archive", data)
+	}
+
+	// Without the index, earlier archives can't be told apart.
+	os.WriteFile(filepath.Join(state, "dbox", "downloads.json"), []byte("{oops"), 0644)
+	dbx.contents["/2025/report.pdf"] = "newer"
+	result = archiveFiles(dbx, []FileItem{{Name: "report.pdf", Path: "/2025/report.pdf", Size: 5}}, config, newDownloadProgress(time.Now()))
+	if len(result.Downloaded) > 0 || len(dbx.deleted) != 2 || len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0].Err, "Archived nothing") {
+		t.Errorf("an unreadable index should stop the archive, got %+v", result)
+	}
+}
+
+func TestConfirmArchive(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a.txt", Path: "/a.txt"}})
+
+	updated, _ := m.runAction(actionArchive, 1)
+	if m = updated.(Model); m.prompt != promptNone || !strings.Contains(m.error, "allow_archive") {
+		t.Fatalf("archiving should need allow_archive: prompt %v, error %q", m.prompt, m.error)
+	}
+
+	m.config.AllowArchive = true
+	updated, _ = m.runAction(actionArchive, 1)
+	if m = updated.(Model); m.prompt != promptConfirmArchive || !strings.Contains(m.promptLabel(), "archive 1 item") {
+		t.Fatalf("prompt = %v, label %q", m.prompt, m.promptLabel())
+	}
+	updated, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m = updated.(Model); m.prompt != promptNone || m.pendingArchive != nil || cmd == nil {
+		t.Error("n should cancel the archive")
+	}
+
+	updated, _ = m.runAction(actionArchive, 1)
+	m = updated.(Model)
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if msg, ok := cmd().(DownloadMsg); !ok || !msg.Archive || len(msg.Files) != 1 {
+		t.Errorf("y should start an archive job, got %#v", cmd())
+	}
+
+	// Retrying what an archive job failed on mustn't delete without asking.
+	m.lastDownload = DownloadMsg{Files: m.files, Archive: true}
+	m.failed = m.files
+	_, cmd = m.retryFailed()
+	if msg, ok := cmd().(DownloadMsg); !ok || msg.Archive || len(msg.Files) != 1 {
+		t.Errorf("a retry should be a plain download, got %#v", cmd())
+	}
+}
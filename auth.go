@@ -3,25 +3,32 @@ package main
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 )
 
 const (
-	authURL      = "https://www.dropbox.com/oauth2/authorize"
-	tokenURL     = "https://api.dropboxapi.com/oauth2/token"
-	loopbackAddr = "127.0.0.1:53682"
-	redirectURL  = "http://localhost:53682/"
+	authURL       = "https://www.dropbox.com/oauth2/authorize"
+	tokenURL      = "https://api.dropboxapi.com/oauth2/token"
+	loopbackAddr  = "127.0.0.1:53682"
+	appConsoleURL = "https://www.dropbox.com/developers/apps"
+	redirectURL   = "http://localhost:53682/"
 
 	envAppKey       = "DROPBOX_APP_KEY"
 	envAppSecret    = "DROPBOX_APP_SECRET"
 	envRefreshToken = "DROPBOX_REFRESH_TOKEN"
+	envTeamMemberID = "DROPBOX_TEAM_MEMBER_ID"
+	envTeamAdminID  = "DROPBOX_TEAM_ADMIN_ID"
 )
 
 // oauthConfig builds the OAuth2 config for the Dropbox confidential client.
@@ -40,41 +47,124 @@ func oauthConfig(appKey, appSecret string) *oauth2.Config {
 
 // credentials reads the Dropbox credentials from the environment. They are
 // typically sourced from an encrypted store (e.g. `. <(pass …)`); nothing is
-// read from or written to disk.
+// read from or written to disk. Any that aren't set are looked up in the OS
+// keyring (see `dbox login --keyring`). Stray whitespace or quotes around a
+// value are ignored (see credentialWarnings). The app secret is optional: a
+// refresh token from a PKCE login renews with the app key alone.
 func credentials() (appKey, appSecret, refreshToken string, err error) {
-	appKey = os.Getenv(envAppKey)
-	appSecret = os.Getenv(envAppSecret)
-	refreshToken = os.Getenv(envRefreshToken)
-	if appKey == "" || appSecret == "" || refreshToken == "" {
-		return "", "", "", fmt.Errorf(
-			"missing Dropbox credentials; set %s, %s, and %s (run \"dbox login\" once to obtain them)",
-			envAppKey, envAppSecret, envRefreshToken)
+	appKey = credentialValue(envAppKey)
+	appSecret = credentialValue(envAppSecret)
+	refreshToken = credentialValue(envRefreshToken)
+
+	var missing []string
+	for _, c := range []struct{ name, value string }{
+		{envAppKey, appKey},
+		{envRefreshToken, refreshToken},
+	} {
+		if c.value == "" {
+			missing = append(missing, c.name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", "", "", missingCredentialsError(missing)
 	}
 	return appKey, appSecret, refreshToken, nil
 }
 
+// credentialValue reads the credential named name from the environment,
+// falling back to the OS keyring when it isn't set there.
+func credentialValue(name string) string {
+	if v, _ := cleanCredential(os.Getenv(name)); v != "" {
+		return v
+	}
+	return keyringLookup(name)
+}
+
+// missingCredentialsError explains which variables are unset and how to obtain
+// them, since a bare "not set" leaves new users stuck.
+func missingCredentialsError(missing []string) error {
+	return fmt.Errorf(`missing Dropbox credentials: %s not set.
+
+To set up dbox:
+  1. Create an app (or open your existing one) in the Dropbox App Console:
+       %s
+  2. From its Settings tab, export the App key:
+       export %s='...'
+     (and the App secret as %s, if you'd rather not use PKCE)
+  3. Run "dbox login" to authorize dbox; it prints the %s to export.
+     Or run "dbox login --keyring" to keep them in the OS keyring instead.`,
+		strings.Join(missing, ", "), appConsoleURL, envAppKey, envAppSecret, envRefreshToken)
+}
+
+// cleanCredential strips surrounding whitespace and one matching pair of
+// quotes, a common mistake when pasting a value into an export line (e.g.
+// export KEY="'abc'"). It reports whether anything was removed.
+func cleanCredential(v string) (string, bool) {
+	cleaned := strings.TrimSpace(v)
+	if n := len(cleaned); n >= 2 {
+		if q := cleaned[0]; (q == '"' || q == '\'') && cleaned[n-1] == q {
+			cleaned = strings.TrimSpace(cleaned[1 : n-1])
+		}
+	}
+	return cleaned, cleaned != v
+}
+
+// credentialWarnings lists credentials whose values had stray whitespace or
+// quotes stripped, so the user can fix how they're exported.
+func credentialWarnings() []string {
+	var warnings []string
+	for _, name := range []string{envAppKey, envAppSecret, envRefreshToken} {
+		if _, changed := cleanCredential(os.Getenv(name)); changed {
+			warnings = append(warnings, fmt.Sprintf("warning: ignoring whitespace or quotes around %s; check how it's exported", name))
+		}
+	}
+	return warnings
+}
+
 // formatCredentialExports renders the credentials as sourceable shell exports.
+// The app secret is left out when there isn't one (a PKCE login).
 func formatCredentialExports(appKey, appSecret, refreshToken string) string {
-	return fmt.Sprintf("export %s='%s'\nexport %s='%s'\nexport %s='%s'\n",
-		envAppKey, appKey,
-		envAppSecret, appSecret,
-		envRefreshToken, refreshToken)
+	s := fmt.Sprintf("export %s='%s'\n", envAppKey, appKey)
+	if appSecret != "" {
+		s += fmt.Sprintf("export %s='%s'\n", envAppSecret, appSecret)
+	}
+	return s + fmt.Sprintf("export %s='%s'\n", envRefreshToken, refreshToken)
 }
 
-// runLogin performs the one-time OAuth flow and prints sourceable credential
-// exports to stdout (status messages go to stderr). It writes nothing to disk,
-// so the output can be piped straight into an encrypted store.
-func runLogin() error {
-	appKey := os.Getenv(envAppKey)
-	appSecret := os.Getenv(envAppSecret)
-	if appKey == "" || appSecret == "" {
-		return fmt.Errorf("set %s and %s (from your Dropbox app's Settings) before running \"dbox login\"", envAppKey, envAppSecret)
+// runLogin implements `dbox login [--keyring]`: it performs the one-time OAuth
+// flow and prints sourceable credential exports to stdout (status messages go
+// to stderr). It writes nothing to disk, so the output can be piped straight
+// into an encrypted store. With --keyring the credentials are stored in the
+// OS keyring instead, where dbox finds them without any exports.
+//
+// The flow uses PKCE (RFC 7636), so only the app key is needed; the app
+// secret is sent too when it's set.
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	useKeyring := fs.Bool("keyring", false, "store the credentials in the OS keyring instead of printing exports")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox login [--keyring]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	appKey := credentialValue(envAppKey)
+	appSecret := credentialValue(envAppSecret)
+	if appKey == "" {
+		return fmt.Errorf("set %s (from your app's Settings in the App Console, %s) before running \"dbox login\"",
+			envAppKey, appConsoleURL)
 	}
 
 	state, err := randomState()
 	if err != nil {
 		return err
 	}
+	verifier, err := pkceVerifier()
+	if err != nil {
+		return err
+	}
 
 	cfg := oauthConfig(appKey, appSecret)
 
@@ -109,7 +199,10 @@ func runLogin() error {
 	go srv.Serve(listener)
 	defer srv.Close()
 
-	url := cfg.AuthCodeURL(state, oauth2.SetAuthURLParam("token_access_type", "offline"))
+	url := cfg.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("token_access_type", "offline"),
+		oauth2.SetAuthURLParam("code_challenge", pkceChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"))
 	fmt.Fprintln(os.Stderr, "Opening your browser to authorize dbox…")
 	fmt.Fprintf(os.Stderr, "If it doesn't open, visit:\n\n  %s\n\n", url)
 	if err := openBrowser(url); err != nil {
@@ -126,7 +219,7 @@ func runLogin() error {
 		return res.err
 	}
 
-	tok, err := cfg.Exchange(context.Background(), res.code)
+	tok, err := cfg.Exchange(context.Background(), res.code, oauth2.SetAuthURLParam("code_verifier", verifier))
 	if err != nil {
 		return fmt.Errorf("token exchange failed: %w", err)
 	}
@@ -134,11 +227,44 @@ func runLogin() error {
 		return fmt.Errorf("Dropbox did not return a refresh token (was token_access_type=offline honored?)")
 	}
 
+	if *useKeyring {
+		for _, c := range []struct{ name, value string }{
+			{envAppKey, appKey},
+			{envAppSecret, appSecret},
+			{envRefreshToken, tok.RefreshToken},
+		} {
+			if c.value == "" {
+				continue
+			}
+			if err := writeKeyring(c.name, c.value); err != nil {
+				return fmt.Errorf("could not store %s in the keyring: %w", c.name, err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "\nLogged in. The credentials are in the OS keyring under %q.\n", keyringService)
+		return nil
+	}
 	fmt.Fprintln(os.Stderr, "\nLogged in. Store these securely (e.g. with pass) and source them before running dbox:")
 	fmt.Print(formatCredentialExports(appKey, appSecret, tok.RefreshToken))
 	return nil
 }
 
+// pkceVerifier returns a random PKCE code verifier: 32 random bytes,
+// base64url-encoded without padding (43 characters).
+func pkceVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge derives the S256 code challenge sent with the authorize
+// request from verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 // randomState returns a random hex string for the OAuth state parameter.
 func randomState() (string, error) {
 	b := make([]byte, 16)
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestCredentialsFromEnv(t *testing.T) {
 	t.Setenv(envAppKey, "key")
@@ -17,12 +21,59 @@ func TestCredentialsFromEnv(t *testing.T) {
 }
 
 func TestCredentialsMissing(t *testing.T) {
+	stubKeyring(t, nil)
 	t.Setenv(envAppKey, "key")
 	t.Setenv(envAppSecret, "secret")
 	t.Setenv(envRefreshToken, "") // unset
 
-	if _, _, _, err := credentials(); err == nil {
-		t.Error("expected an error when a credential is missing")
+	_, _, _, err := credentials()
+	if err == nil {
+		t.Fatal("expected an error when a credential is missing")
+	}
+	// The message names what's missing and where to get it.
+	if msg := err.Error(); !strings.Contains(msg, envRefreshToken) || !strings.Contains(msg, appConsoleURL) {
+		t.Errorf("error should mention %s and the App Console:\n%s", envRefreshToken, msg)
+	}
+	if strings.Contains(strings.SplitN(err.Error(), "\n", 2)[0], envAppKey) {
+		t.Errorf("first line should only list missing variables: %q", err.Error())
+	}
+}
+
+func TestCredentialsStripsQuotes(t *testing.T) {
+	t.Setenv(envAppKey, " key\n")
+	t.Setenv(envAppSecret, `"secret"`)
+	t.Setenv(envRefreshToken, "'refresh'")
+
+	k, s, r, err := credentials()
+	if err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+	if k != "key" || s != "secret" || r != "refresh" {
+		t.Errorf("got (%q, %q, %q), want (key, secret, refresh)", k, s, r)
+	}
+	if got := len(credentialWarnings()); got != 3 {
+		t.Errorf("got %d warnings, want 3", got)
+	}
+}
+
+func TestCleanCredential(t *testing.T) {
+	cases := []struct {
+		in, want string
+		changed  bool
+	}{
+		{"abc", "abc", false},
+		{"  abc\t", "abc", true},
+		{`"abc"`, "abc", true},
+		{`'abc'`, "abc", true},
+		{`"abc'`, `"abc'`, false}, // mismatched quotes are left alone
+		{`"`, `"`, false},
+		{"", "", false},
+	}
+	for _, tc := range cases {
+		got, changed := cleanCredential(tc.in)
+		if got != tc.want || changed != tc.changed {
+			t.Errorf("cleanCredential(%q) = (%q, %v), want (%q, %v)", tc.in, got, changed, tc.want, tc.changed)
+		}
 	}
 }
 
@@ -35,3 +86,71 @@ func TestFormatCredentialExports(t *testing.T) {
 		t.Errorf("formatCredentialExports =\n%q\nwant\n%q", got, want)
 	}
 }
+
+func TestFormatCredentialExportsWithoutSecret(t *testing.T) {
+	got := formatCredentialExports("key", "", "refresh")
+	want := "export DROPBOX_APP_KEY='key'\n" +
+		"export DROPBOX_REFRESH_TOKEN='refresh'\n"
+	if got != want {
+		t.Errorf("formatCredentialExports =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCredentialsWithoutSecret(t *testing.T) {
+	stubKeyring(t, nil)
+	t.Setenv(envAppKey, "key")
+	t.Setenv(envAppSecret, "")
+	t.Setenv(envRefreshToken, "refresh")
+
+	k, s, r, err := credentials()
+	if err != nil {
+		t.Fatalf("a PKCE login needs no app secret: %v", err)
+	}
+	if k != "key" || s != "" || r != "refresh" {
+		t.Errorf("got (%q, %q, %q), want (key, \"\", refresh)", k, s, r)
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// The example from RFC 7636, appendix B.
+	got := pkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("challenge = %q, want %q", got, want)
+	}
+	v, err := pkceVerifier()
+	if err != nil || len(v) != 43 {
+		t.Errorf("verifier = %q (%v), want 43 characters", v, err)
+	}
+}
+
+func TestTeamSelection(t *testing.T) {
+	t.Setenv(envTeamMemberID, " 'dbmid:abc' ")
+	t.Setenv(envTeamAdminID, "")
+	member, admin, err := teamSelection()
+	if err != nil || member != "dbmid:abc" || admin != "" {
+		t.Errorf("got (%q, %q, %v), want the member only", member, admin, err)
+	}
+
+	t.Setenv(envTeamAdminID, "dbmid:def")
+	if _, _, err := teamSelection(); err == nil {
+		t.Error("expected an error when both a member and an admin are set")
+	}
+}
+
+func TestExplainTeamError(t *testing.T) {
+	t.Setenv(envTeamMemberID, "")
+	t.Setenv(envTeamAdminID, "")
+	teamErr := errors.New(`This API function operates on a single Dropbox account, but the OAuth 2 access token you provided is for an entire Dropbox Business team. ... providing the "Dropbox-API-Select-User" HTTP header`)
+
+	if msg := explainTeamError(teamErr).Error(); !strings.Contains(msg, envTeamMemberID) {
+		t.Errorf("error should say to set %s: %q", envTeamMemberID, msg)
+	}
+	other := errors.New("path/not_found/")
+	if explainTeamError(other) != other {
+		t.Error("other errors should pass through")
+	}
+	t.Setenv(envTeamMemberID, "dbmid:abc")
+	if explainTeamError(teamErr) != teamErr {
+		t.Error("with a member set, the original error should pass through")
+	}
+}
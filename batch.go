@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// errDownloadsFailed is returned by runDownload when some files couldn't be
+// downloaded, so the process exits non-zero after reporting them.
+var errDownloadsFailed = errors.New("some downloads failed")
+
+// runDownload implements `dbox download [--json] <path>...`: it downloads the
+// given Dropbox files and folders into the download directory without the TUI,
+// then prints a summary (or, with --json, a machine-readable report).
+func runDownload(args []string) error {
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print a JSON report to stdout instead of a summary")
+	paperFormat := fs.String("paper-format", "", "format to export Paper docs in: markdown or html (default from settings)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox download [--json] [--paper-format markdown|html] <path>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no paths given")
+	}
+	if *paperFormat != "" && !validPaperFormat(*paperFormat) {
+		return fmt.Errorf("unknown Paper export format %q (use markdown or html)", *paperFormat)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if *paperFormat != "" {
+		config.PaperFormat = *paperFormat
+	}
+	if err := config.EnsureDownloadPath(); err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return err
+	}
+
+	var items []FileItem
+	var lookupErrs []ItemError
+	for _, p := range fs.Args() {
+		item, err := lookupFileItem(dbx, p)
+		if err != nil {
+			lookupErrs = append(lookupErrs, ItemError{
+				Item: FileItem{Name: path.Base(p), Path: p},
+				Err:  fmt.Sprintf("Failed to look up %s: %v", p, err),
+			})
+			continue
+		}
+		items = append(items, item)
+	}
+
+	result := downloadWithIndex(dbx, items, config, newDownloadProgress(time.Now()))
+	result.Errors = append(lookupErrs, result.Errors...)
+
+	if *asJSON {
+		if err := writeDownloadReport(os.Stdout, result, config); err != nil {
+			return err
+		}
+	} else {
+		printDownloadSummary(os.Stdout, result, config.BinaryUnits)
+	}
+	if len(result.Errors) > 0 {
+		return errDownloadsFailed
+	}
+	return nil
+}
+
+// lookupFileItem resolves a Dropbox path given on the command line. The root
+// can't be looked up via GetMetadata, so it's built directly.
+func lookupFileItem(dbx files.Client, p string) (FileItem, error) {
+	p = normalizeRemotePath(p)
+	if p == "" {
+		return FileItem{Name: "/", Path: "", IsFolder: true}, nil
+	}
+	meta, err := dbx.GetMetadata(files.NewGetMetadataArg(p))
+	if err != nil {
+		return FileItem{}, err
+	}
+	item, ok := fileItemFromMetadata(meta)
+	if !ok {
+		return FileItem{}, fmt.Errorf("not a file or folder")
+	}
+	return item, nil
+}
+
+// downloadReportEntry is one file in the --json report.
+type downloadReportEntry struct {
+	Path      string `json:"path"`
+	LocalPath string `json:"local_path,omitempty"`
+	Size      int64  `json:"size"`
+	Error     string `json:"error,omitempty"`
+}
+
+// downloadReport is the --json output of `dbox download`. The arrays are never
+// null, so consumers like jq can iterate them unconditionally.
+type downloadReport struct {
+	Downloaded  []downloadReportEntry `json:"downloaded"`
+	Overwritten []downloadReportEntry `json:"overwritten"` // also in downloaded
+	Skipped     []downloadReportEntry `json:"skipped"`
+	Ignored     []downloadReportEntry `json:"ignored"`
+	TooDeep     []downloadReportEntry `json:"not_followed"`
+	Errors      []downloadReportEntry `json:"errors"`
+}
+
+// writeDownloadReport writes the result as an indented JSON object.
+func writeDownloadReport(w io.Writer, result DownloadCompleteMsg, config *Config) error {
+	entry := func(item FileItem) downloadReportEntry {
+		return downloadReportEntry{Path: item.Path, Size: item.Size, LocalPath: result.localPath(config, item)}
+	}
+
+	report := downloadReport{
+		Downloaded:  []downloadReportEntry{},
+		Overwritten: []downloadReportEntry{},
+		Skipped:     []downloadReportEntry{},
+		Ignored:     []downloadReportEntry{},
+		TooDeep:     []downloadReportEntry{},
+		Errors:      []downloadReportEntry{},
+	}
+	for _, item := range result.Downloaded {
+		report.Downloaded = append(report.Downloaded, entry(item))
+	}
+	for _, item := range result.Overwritten {
+		report.Overwritten = append(report.Overwritten, entry(item))
+	}
+	for _, item := range result.Skipped {
+		report.Skipped = append(report.Skipped, entry(item))
+	}
+	for _, item := range result.Ignored {
+		report.Ignored = append(report.Ignored, entry(item))
+	}
+	for _, item := range result.TooDeep {
+		report.TooDeep = append(report.TooDeep, downloadReportEntry{Path: item.Path})
+	}
+	for _, e := range result.Errors {
+		report.Errors = append(report.Errors, downloadReportEntry{Path: e.Item.Path, Size: e.Item.Size, Error: e.Err})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// printDownloadSummary prints the human-readable result of a batch download,
+// with sizes in binary units when binary is set.
+func printDownloadSummary(w io.Writer, result DownloadCompleteMsg, binary bool) {
+	overwritten := map[string]bool{}
+	for _, item := range result.Overwritten {
+		overwritten[item.Path] = true
+	}
+	for _, item := range result.Downloaded {
+		fmt.Fprintf(w, "downloaded  %s (%s)\n", item.Path, formatSize(item.Size, binary))
+		if overwritten[item.Path] {
+			fmt.Fprintf(w, "            replaced an existing file\n")
+		}
+		if local, ok := result.Renamed[item.Path]; ok {
+			fmt.Fprintf(w, "            saved as %s (same name as another file)\n", local)
+		}
+	}
+	for _, item := range result.Skipped {
+		fmt.Fprintf(w, "skipped     %s (already exists)\n", item.Path)
+	}
+	for _, item := range result.Ignored {
+		fmt.Fprintf(w, "ignored     %s (.dboxignore)\n", item.Path)
+	}
+	for _, item := range result.TooDeep {
+		fmt.Fprintf(w, "not followed %s/ (deeper than max_depth)\n", item.Path)
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(w, "error       %s\n", e.Err)
+	}
+	fmt.Fprintf(w, "Download complete. Downloaded: %d, Skipped: %d, Errors: %d",
+		len(result.Downloaded), len(result.Skipped), len(result.Errors))
+	if len(result.Overwritten) > 0 {
+		fmt.Fprintf(w, ", Overwritten: %d", len(result.Overwritten))
+	}
+	if len(result.Ignored) > 0 {
+		fmt.Fprintf(w, ", Ignored: %d", len(result.Ignored))
+	}
+	if len(result.TooDeep) > 0 {
+		fmt.Fprintf(w, ", Not followed: %d", len(result.TooDeep))
+	}
+	fmt.Fprintln(w)
+}
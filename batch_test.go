@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDownloadReport(t *testing.T) {
+	config := &Config{DownloadPath: t.TempDir()}
+	result := DownloadCompleteMsg{
+		Downloaded: []FileItem{{Name: "a.txt", Path: "/docs/a.txt", Size: 42}},
+		Errors:     []ItemError{{Item: FileItem{Name: "b.txt", Path: "/docs/b.txt", Size: 7}, Err: "boom"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDownloadReport(&buf, result, config); err != nil {
+		t.Fatalf("writeDownloadReport: %v", err)
+	}
+
+	var report downloadReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(report.Downloaded) != 1 || report.Downloaded[0].Size != 42 {
+		t.Errorf("downloaded = %+v", report.Downloaded)
+	}
+	if want := filepath.Join(config.DownloadPath, "docs", "a.txt"); report.Downloaded[0].LocalPath != want {
+		t.Errorf("local_path = %q, want %q", report.Downloaded[0].LocalPath, want)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Error != "boom" {
+		t.Errorf("errors = %+v", report.Errors)
+	}
+	// Empty lists are [] rather than null so jq can iterate them.
+	if !bytes.Contains(buf.Bytes(), []byte(`"skipped": []`)) {
+		t.Errorf("skipped should serialize as an empty array:\n%s", buf.String())
+	}
+}
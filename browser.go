@@ -2,22 +2,60 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os/exec"
+	"path"
 	"runtime"
 )
 
+// dropboxWebURL returns the Dropbox web page for a folder.
+func dropboxWebURL(folder string) string {
+	if folder == "" {
+		folder = "/"
+	}
+	// Properly URL encode the path for the web URL
+	return "https://www.dropbox.com/home" + url.PathEscape(folder)
+}
+
+// dropboxPreviewURL returns the Dropbox web page for an entry: a folder's own
+// page, or a file previewed in its folder.
+func dropboxPreviewURL(item FileItem) string {
+	p := item.displayPath()
+	if item.IsFolder {
+		return dropboxWebURL(p)
+	}
+	dir := path.Dir(p)
+	if dir == "/" {
+		dir = ""
+	}
+	return dropboxWebURL(dir) + "?preview=" + url.QueryEscape(path.Base(p))
+}
+
 // openBrowser opens url in the user's default browser.
 func openBrowser(url string) error {
+	return openDefault(url)
+}
+
+// openLocal opens a local file or directory with the system's default
+// application (the file manager, for a directory).
+func openLocal(path string) error {
+	return openDefault(path)
+}
+
+// openDefault hands target (a URL or local path) to the platform's opener.
+func openDefault(target string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
-		cmd = exec.Command("open", url)
+		cmd = exec.Command("open", target)
 	case "linux":
-		cmd = exec.Command("xdg-open", url)
+		cmd = exec.Command("xdg-open", target)
 	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
+		// The empty argument is start's window title, so a quoted target
+		// containing spaces isn't mistaken for one.
+		cmd = exec.Command("cmd", "/c", "start", "", target)
 	default:
-		return fmt.Errorf("cannot open browser on %s", runtime.GOOS)
+		return fmt.Errorf("cannot open %s on %s", target, runtime.GOOS)
 	}
 	return cmd.Start()
 }
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestDropboxPreviewURL(t *testing.T) {
+	tests := []struct {
+		name string
+		item FileItem
+		want string
+	}{
+		{"folder", FileItem{Path: "/photos/2024", DisplayPath: "/Photos/2024", IsFolder: true}, "https://www.dropbox.com/home%2FPhotos%2F2024"},
+		{"file", FileItem{Path: "/docs/q1 report.pdf", DisplayPath: "/Docs/Q1 report.pdf"}, "https://www.dropbox.com/home%2FDocs?preview=Q1+report.pdf"},
+		{"file at root", FileItem{Path: "/a&b.txt"}, "https://www.dropbox.com/home%2F?preview=a%26b.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dropboxPreviewURL(tt.item); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
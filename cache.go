@@ -0,0 +1,81 @@
+package main
+
+// defaultMaxCacheEntries is how many folder listings are kept when the
+// settings file doesn't say.
+const defaultMaxCacheEntries = 200
+
+// cachedFolder returns the cached listing of folder p, if there is one, and
+// marks it as the most recently used. With disable_cache there never is.
+func (m *Model) cachedFolder(p string) ([]FileItem, bool) {
+	if m.config.DisableCache {
+		return nil, false
+	}
+	files, ok := m.folderCache[p]
+	if ok {
+		m.touchCache(p)
+	}
+	return files, ok
+}
+
+// cacheFolder stores the listing of folder p, then evicts the least recently
+// used listings while there are more than max_cache_entries (0 keeps them
+// all). With disable_cache, nothing is stored.
+func (m *Model) cacheFolder(p string, files []FileItem) {
+	if m.config.DisableCache {
+		return
+	}
+	m.folderCache[p] = files
+	m.touchCache(p)
+	limit := m.config.MaxCacheEntries
+	for limit > 0 && len(m.folderCache) > limit && len(m.cacheOrder) > 0 {
+		oldest := m.cacheOrder[0]
+		m.cacheOrder = m.cacheOrder[1:]
+		delete(m.folderCache, oldest)
+	}
+}
+
+// touchCache moves p to the most recently used end of cacheOrder. Paths
+// dropped from folderCache elsewhere are pruned from the order as it goes.
+func (m *Model) touchCache(p string) {
+	order := m.cacheOrder[:0]
+	for _, cached := range m.cacheOrder {
+		if _, ok := m.folderCache[cached]; ok && cached != p {
+			order = append(order, cached)
+		}
+	}
+	m.cacheOrder = append(order, p)
+}
+
+// forgetFolder drops folder p's cached listing and preview, after something
+// changed in it.
+func (m *Model) forgetFolder(p string) {
+	delete(m.folderCache, p)
+	delete(m.previews, p)
+	if m.previewLastPath == p {
+		m.previewLast, m.previewLastPath = folderPreview{}, ""
+	}
+}
+
+// forgetPreviews drops the previews of folder dir and every folder below it,
+// which a fresh listing of dir may show have changed.
+func (m *Model) forgetPreviews(dir string) {
+	for p := range m.previews {
+		if p == dir || withinRemote(p, dir) {
+			delete(m.previews, p)
+		}
+	}
+	if m.previewLastPath == dir || withinRemote(m.previewLastPath, dir) {
+		m.previewLast, m.previewLastPath = folderPreview{}, ""
+	}
+}
+
+// clearCache drops every cached listing, and any revision counts and folder
+// previews with them.
+func (m *Model) clearCache() {
+	m.folderCache = make(map[string][]FileItem)
+	m.cacheOrder = nil
+	m.revisions = nil
+	m.previews = nil
+	m.previewLast, m.previewLastPath = folderPreview{}, ""
+	m.folderSizes = nil
+}
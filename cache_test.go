@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	m := initialModel(&Config{MaxCacheEntries: 2})
+	m.cacheFolder("/a", nil)
+	m.cacheFolder("/b", nil)
+	// Visiting /a makes /b the least recently used.
+	if _, ok := m.cachedFolder("/a"); !ok {
+		t.Fatal("/a should be cached")
+	}
+	m.cacheFolder("/c", nil)
+
+	if _, ok := m.folderCache["/b"]; ok {
+		t.Error("/b should have been evicted")
+	}
+	if want := []string{"/a", "/c"}; !reflect.DeepEqual(m.cacheOrder, want) {
+		t.Errorf("order = %v, want %v", m.cacheOrder, want)
+	}
+}
+
+func TestCacheOrderSkipsInvalidated(t *testing.T) {
+	m := initialModel(&Config{MaxCacheEntries: 2})
+	m.cacheFolder("/a", nil)
+	m.cacheFolder("/b", nil)
+	// Dropped elsewhere (e.g. after a delete): it shouldn't count toward the
+	// limit or be evicted in place of a live entry.
+	delete(m.folderCache, "/a")
+	m.cacheFolder("/c", nil)
+
+	if len(m.folderCache) != 2 {
+		t.Errorf("cached = %v, want /b and /c", m.folderCache)
+	}
+	if want := []string{"/b", "/c"}; !reflect.DeepEqual(m.cacheOrder, want) {
+		t.Errorf("order = %v, want %v", m.cacheOrder, want)
+	}
+}
+
+func TestCacheUnlimited(t *testing.T) {
+	m := initialModel(&Config{})
+	for _, p := range []string{"/a", "/b", "/c"} {
+		m.cacheFolder(p, nil)
+	}
+	if len(m.folderCache) != 3 {
+		t.Errorf("cached %d folders, want all 3", len(m.folderCache))
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	m := initialModel(&Config{DisableCache: true})
+	m.cacheFolder("/a", []FileItem{{Name: "x"}})
+	if _, ok := m.cachedFolder("/a"); ok || len(m.folderCache) != 0 {
+		t.Errorf("disable_cache should keep nothing, cached %v", m.folderCache)
+	}
+}
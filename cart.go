@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cartChrome is the number of lines the selection screen uses around its
+// list (title, blank lines, hint, and message).
+const cartChrome = 6
+
+// openCart switches to the screen listing everything selected, across
+// folders.
+func (m Model) openCart() (tea.Model, tea.Cmd) {
+	m.cart = append([]FileItem{}, m.selectedItems()...)
+	m.cartCursor = 0
+	return m, nil
+}
+
+// handleCartKey moves through the selection screen. x (or space) takes the
+// entry under the cursor out of the selection, c clears it (asking first with
+// confirm_leave_selection on), d downloads all of it, and esc or q goes back.
+func (m Model) handleCartKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.prompt != promptNone {
+		return m.handlePromptKey(msg)
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.cart = nil
+	case "down", "j":
+		m.cartCursor = min(m.cartCursor+1, max(0, len(m.cart)-1))
+	case "up", "k":
+		m.cartCursor = max(m.cartCursor-1, 0)
+	case "x", " ":
+		if m.cartCursor >= len(m.cart) {
+			break
+		}
+		m.deselect(m.cart[m.cartCursor : m.cartCursor+1])
+		m.cart = append(m.cart[:m.cartCursor], m.cart[m.cartCursor+1:]...)
+		m.cartCursor = min(m.cartCursor, max(0, len(m.cart)-1))
+	case "c":
+		if m.config.ConfirmLeaveSelection && len(m.cart) > 0 {
+			m.openPrompt(promptConfirmClear)
+			break
+		}
+		m.clearCart()
+	case "d":
+		if len(m.cart) == 0 {
+			break
+		}
+		items := m.cart
+		m.cart = nil
+		return m.startDownload(items, false)
+	}
+	return m, nil
+}
+
+// clearCart takes everything on the selection screen out of the selection.
+func (m *Model) clearCart() {
+	m.deselect(m.cart)
+	m.cart = m.cart[:0]
+	m.cartCursor = 0
+}
+
+// answerClear handles y/n at the prompt to clear the selection screen. Other
+// keys leave the prompt open.
+func (m Model) answerClear(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		m.closePrompt()
+		m.clearCart()
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
+
+// renderCartView renders the selection screen.
+func (m Model) renderCartView() string {
+	theme := m.config.Theme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	cursorStyle := lipgloss.NewStyle().Foreground(theme.Cursor).Bold(true)
+
+	var total int64
+	for _, item := range m.cart {
+		total += item.Size
+	}
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Selected: %s, %s of files", pluralize(len(m.cart), "item"), formatSize(total, m.config.BinaryUnits))) + "\n\n")
+	if len(m.cart) == 0 {
+		s.WriteString(mutedStyle.Render("Nothing selected") + "\n")
+	}
+
+	page := max(1, m.height-cartChrome)
+	offset := max(0, m.cartCursor-page+1)
+	for i := offset; i < min(len(m.cart), offset+page); i++ {
+		item := m.cart[i]
+		line, detail := item.displayPath(), formatSize(item.Size, m.config.BinaryUnits)
+		if item.IsFolder {
+			line, detail = line+"/", "folder"
+		}
+		if i == m.cartCursor {
+			line = cursorStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		s.WriteString(line + "  " + mutedStyle.Render(detail) + "\n")
+	}
+
+	if m.prompt != promptNone {
+		s.WriteString("\n " + m.renderPrompt() + "\n")
+	} else {
+		s.WriteString("\n" + mutedStyle.Render("d to download all · x to remove · c to clear · esc to go back") + "\n")
+	}
+	return s.String()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// runCat implements `dbox cat <path>`: it streams one Dropbox file to stdout
+// without the TUI and without writing anything to disk, so it can be piped.
+func runCat(args []string) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	paperFormat := fs.String("paper-format", "", "format to export Paper docs in: markdown or html (default from settings)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox cat [--paper-format markdown|html] <path>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one file path")
+	}
+	if *paperFormat != "" && !validPaperFormat(*paperFormat) {
+		return fmt.Errorf("unknown Paper export format %q (use markdown or html)", *paperFormat)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if *paperFormat != "" {
+		config.PaperFormat = *paperFormat
+	}
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return err
+	}
+	item, err := lookupFileItem(dbx, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", fs.Arg(0), err)
+	}
+	return catFile(dbx, item, config.PaperFormat, os.Stdout)
+}
+
+// catFile copies a file's contents to w. Paper docs are exported in
+// paperFormat, since they can't be downloaded as-is.
+func catFile(dbx files.Client, item FileItem, paperFormat string, w io.Writer) error {
+	if item.IsFolder {
+		return fmt.Errorf("%s is a folder", item.displayPath())
+	}
+	var contents io.ReadCloser
+	var err error
+	if item.Exportable {
+		arg := files.NewExportArg(item.Path)
+		arg.ExportFormat = paperFormat
+		_, contents, err = dbx.Export(arg)
+	} else {
+		_, contents, err = dbx.Download(files.NewDownloadArg(item.Path))
+	}
+	if err != nil {
+		return err
+	}
+	defer contents.Close()
+	_, err = io.Copy(w, contents)
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeCatClient serves one file's contents, exported or downloaded.
+type fakeCatClient struct {
+	files.Client
+	content      string
+	exportFormat string
+}
+
+func (f *fakeCatClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	return &files.FileMetadata{}, io.NopCloser(strings.NewReader(f.content)), nil
+}
+
+func (f *fakeCatClient) Export(arg *files.ExportArg) (*files.ExportResult, io.ReadCloser, error) {
+	f.exportFormat = arg.ExportFormat
+	return &files.ExportResult{}, io.NopCloser(strings.NewReader("# " + f.content)), nil
+}
+
+func TestCatFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    FileItem
+		want    string
+		wantErr bool
+	}{
+		{"file", FileItem{Name: "a.txt", Path: "/a.txt"}, "hello", false},
+		{"paper doc", FileItem{Name: "notes.paper", Path: "/notes.paper", Exportable: true}, "# hello", false},
+		{"folder", FileItem{Name: "docs", Path: "/docs", IsFolder: true}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbx := &fakeCatClient{content: "hello"}
+			var out bytes.Buffer
+			err := catFile(dbx, tt.item, "markdown", &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+			if tt.item.Exportable && dbx.exportFormat != "markdown" {
+				t.Errorf("exported as %q, want markdown", dbx.exportFormat)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyToClipboard puts text on the system clipboard using the platform's
+// copy tool, which reads it from stdin.
+func copyToClipboard(text string) error {
+	args, err := clipboardCommand(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "", func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clipboardCommand picks the copy tool for goos. On Linux that's wl-copy under
+// Wayland, otherwise xclip or xsel, whichever installed reports is there.
+func clipboardCommand(goos string, wayland bool, installed func(string) bool) ([]string, error) {
+	switch goos {
+	case "darwin":
+		return []string{"pbcopy"}, nil
+	case "windows":
+		return []string{"clip"}, nil
+	}
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if wayland {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, c := range candidates {
+		if installed(c[0]) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install wl-copy, xclip, or xsel)")
+}
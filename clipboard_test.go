@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipboardCommand(t *testing.T) {
+	tests := []struct {
+		goos      string
+		wayland   bool
+		installed string // space-separated tools on PATH
+		want      string // joined command, or "" for an error
+	}{
+		{"darwin", false, "", "pbcopy"},
+		{"windows", false, "", "clip"},
+		{"linux", true, "wl-copy xclip", "wl-copy"},
+		{"linux", false, "wl-copy xclip", "xclip -selection clipboard"},
+		{"linux", true, "xsel", "xsel --clipboard --input"},
+		{"linux", false, "", ""},
+	}
+	for _, tt := range tests {
+		have := strings.Fields(tt.installed)
+		installed := func(name string) bool {
+			for _, h := range have {
+				if h == name {
+					return true
+				}
+			}
+			return false
+		}
+		args, err := clipboardCommand(tt.goos, tt.wayland, installed)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s %q: got %v, want an error", tt.goos, tt.installed, args)
+			}
+			continue
+		}
+		if err != nil || strings.Join(args, " ") != tt.want {
+			t.Errorf("%s wayland=%v %q: got %v (%v), want %s", tt.goos, tt.wayland, tt.installed, args, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// configureColor drops ANSI colors when NO_COLOR is set (https://no-color.org/)
+// or stdout isn't a terminal, so redirected output stays clean.
+func configureColor() {
+	fd := os.Stdout.Fd()
+	tty := isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+	if colorDisabled(os.Getenv("NO_COLOR"), tty) {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// colorDisabled reports whether styling should be plain, given the NO_COLOR
+// value and whether stdout is a terminal. Per the convention, an empty
+// NO_COLOR doesn't count.
+func colorDisabled(noColor string, tty bool) bool {
+	return noColor != "" || !tty
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestColorDisabled(t *testing.T) {
+	tests := []struct {
+		noColor string
+		tty     bool
+		want    bool
+	}{
+		{"", true, false},
+		{"1", true, true},
+		{"", false, true},
+		{"1", false, true},
+	}
+	for _, tt := range tests {
+		if got := colorDisabled(tt.noColor, tt.tty); got != tt.want {
+			t.Errorf("colorDisabled(%q, %v) = %v, want %v", tt.noColor, tt.tty, got, tt.want)
+		}
+	}
+}
@@ -1,92 +1,106 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/auth"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+	"golang.org/x/sync/errgroup"
 )
 
-// loadFilesCmd returns a command that loads files from Dropbox
-func loadFilesCmd(path string) tea.Cmd {
+// defaultListConcurrency is how many ListFolder calls a recursive scan makes
+// at once unless list_concurrency says otherwise. Parallel listing is much
+// faster for wide trees, but bursts beyond a handful of requests start
+// tripping Dropbox's rate limits.
+const defaultListConcurrency = 4
+
+// loadFilesCmd returns a command that loads files from Dropbox. pageSize, if
+// set, caps the first page; the rest are fetched as needed (see moreCmd).
+func loadFilesCmd(config *Config, path string) tea.Cmd {
+	path = normalizeRemotePath(path)
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
+		dbx, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
 
 		// List files in the specified path
-		arg := files.NewListFolderArg(path)
-		if path == "" {
-			arg = files.NewListFolderArg("")
+		fileItems, cursor, err := listFolderPage(dbx, path, config.ListPageSize)
+		if isTimeoutError(err) {
+			return OfflineMsg{Path: path, TimedOut: true}
+		}
+		if isNetworkError(err) {
+			return OfflineMsg{Path: path}
 		}
-
-		result, err := dbx.ListFolder(arg)
 		if err != nil {
 			// Try to get more detailed error information
-			return ErrorMsg{Error: fmt.Sprintf("Failed to load files from path '%s': %v", path, err)}
+			return ErrorMsg{Error: fmt.Sprintf("Failed to load files from path '%s': %v", path, explainTeamError(err))}
 		}
 
-		var fileItems []FileItem
-
-		// Process entries
-		for _, entry := range result.Entries {
-			// Skip deleted files
-			if _, ok := entry.(*files.DeletedMetadata); ok {
-				continue
-			}
-
-			var item FileItem
-
-			switch v := entry.(type) {
-			case *files.FileMetadata:
-				item = FileItem{
-					Name:     v.Name,
-					Path:     v.PathLower,
-					IsFolder: false,
-					Size:     int64(v.Size),
-					Modified: v.ServerModified,
-				}
-			case *files.FolderMetadata:
-				item = FileItem{
-					Name:     v.Name,
-					Path:     v.PathLower,
-					IsFolder: true,
-					Size:     0,
-					Modified: time.Now(), // Folders don't have modification time in Dropbox API
-				}
-			default:
-				continue
-			}
-
-			fileItems = append(fileItems, item)
+		// The model sorts entries for display (see refreshVisible).
+		return FilesLoadedMsg{
+			Files:  fileItems,
+			Path:   path,
+			Cursor: cursor,
 		}
+	}
+}
 
-		// Sort files: folders first, then by name
-		sort.Slice(fileItems, func(i, j int) bool {
-			if fileItems[i].IsFolder != fileItems[j].IsFolder {
-				return fileItems[i].IsFolder
-			}
-			return strings.ToLower(fileItems[i].Name) < strings.ToLower(fileItems[j].Name)
-		})
+// listFolderPage lists the first page of a folder's entries, in no particular
+// order. pageSize, if set, caps the page. cursor is set when there are more
+// pages, to fetch with listFolderMore.
+func listFolderPage(dbx files.Client, path string, pageSize int) (items []FileItem, cursor string, err error) {
+	arg := files.NewListFolderArg(normalizeRemotePath(path))
+	arg.IncludeHasExplicitSharedMembers = true
+	if pageSize > 0 {
+		arg.Limit = uint32(pageSize)
+	}
+	result, err := dbx.ListFolder(arg)
+	if err != nil {
+		return nil, "", err
+	}
+	return listingPage(result)
+}
 
-		return FilesLoadedMsg{
-			Files: fileItems,
-			Path:  path,
+// listFolderMore lists the page of a folder's entries cursor points to.
+func listFolderMore(dbx files.Client, cursor string) (items []FileItem, next string, err error) {
+	result, err := dbx.ListFolderContinue(files.NewListFolderContinueArg(cursor))
+	if err != nil {
+		return nil, "", err
+	}
+	return listingPage(result)
+}
+
+// listingPage converts one page of a listing, returning the cursor of the
+// next page if there is one.
+func listingPage(result *files.ListFolderResult) (items []FileItem, cursor string, err error) {
+	for _, entry := range result.Entries {
+		if item, ok := fileItemFromMetadata(entry); ok {
+			items = append(items, item)
 		}
 	}
+	if result.HasMore {
+		cursor = result.Cursor
+	}
+	return items, cursor, nil
 }
 
 // downloadFileCmd returns a command that downloads a file from Dropbox
-func downloadFileCmd(path string, localPath string) tea.Cmd {
+func downloadFileCmd(config *Config, path string, localPath string) tea.Cmd {
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
+		dbx, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
@@ -106,7 +120,7 @@ func downloadFileCmd(path string, localPath string) tea.Cmd {
 		}
 
 		// Write to local file
-		err = os.WriteFile(localPath, contentBytes, 0644)
+		err = os.WriteFile(localPath, contentBytes, config.fileMode())
 		if err != nil {
 			return ErrorMsg{Error: fmt.Sprintf("Failed to write file: %v", err)}
 		}
@@ -115,127 +129,651 @@ func downloadFileCmd(path string, localPath string) tea.Cmd {
 	}
 }
 
-// downloadFilesCmd returns a command that downloads multiple files and folders
-func downloadFilesCmd(fileItems []FileItem, config *Config) tea.Cmd {
+// downloadFilesCmd returns a command that downloads multiple files and folders.
+// Bytes are counted into progress as they arrive so the UI can show speed.
+// Rather than returning a message itself, it streams one FileDoneMsg per file
+// and then the DownloadCompleteMsg on events, closing it at the end; the UI
+// reads them with waitForDownloadEvent. The job is saved as the download
+// queue while it runs, so it can be resumed if dbox quits first.
+func downloadFilesCmd(job DownloadMsg, config *Config, progress *downloadProgress, events chan<- tea.Msg) tea.Cmd {
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
-		if err != nil {
-			return ErrorMsg{Error: err.Error()}
+		defer close(events)
+		progress.onFile = func(msg FileDoneMsg) { events <- msg }
+		progress.onScan = func(msg ScanProgressMsg) { events <- msg }
+		events <- runDownloadJob(job, config, progress)
+		return nil
+	}
+}
+
+// downloader moves a download job's files (see runDownloadJob).
+type downloader func(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress) DownloadCompleteMsg
+
+// downloaderFor picks how msg's job downloads: file by file, folders as zips,
+// or archiving.
+func downloaderFor(msg DownloadMsg) downloader {
+	switch {
+	case msg.Archive:
+		return archiveFiles
+	case msg.Zip:
+		return downloadZipsThenFiles
+	default:
+		return downloadWithIndex
+	}
+}
+
+// runDownloadJob downloads job's files the way downloaderFor picks, keeping
+// the download queue up to date around the job and adding its files to the
+// download history. Any errors are also written to a log file (see
+// logDownloadErrors). An archive job is queued as a plain download, so
+// resuming it never deletes anything without asking.
+func runDownloadJob(job DownloadMsg, config *Config, progress *downloadProgress) tea.Msg {
+	fileItems, download := job.Files, downloaderFor(job)
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return ErrorMsg{Error: err.Error()}
+	}
+	var result DownloadCompleteMsg
+	if config.link != nil {
+		// The queue is resumed against the account, so links skip it.
+		result = download(dbx, fileItems, config, progress)
+	} else {
+		queueErr := saveDownloadQueue(downloadQueue{Items: fileItems, PaperFormat: config.PaperFormat, MaxDepth: config.MaxDepth, Zip: job.Zip})
+		result = download(dbx, fileItems, config, progress)
+		if queueErr == nil {
+			queueErr = clearDownloadQueue()
 		}
+		if queueErr != nil {
+			result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to update download queue: %v", queueErr)})
+		}
+		if err := appendHistory(historyEntries(result, config, time.Now())); err != nil {
+			result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to update download history: %v", err)})
+		}
+	}
+	result = openDownloaded(fileItems, result, config)
+	return logDownloadErrors(result, config.DownloadPath, config.fileMode(), time.Now())
+}
 
-		downloadDir := config.DownloadPath
-		var downloaded, skipped, errors []string
-
-		// Expand folders to include all their contents
-		var allFilesToDownload []FileItem
-		for _, fileItem := range fileItems {
-			if fileItem.IsFolder {
-				folderFiles, err := getAllFilesInFolder(dbx, fileItem.Path)
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to list folder %s: %v", fileItem.Name, err))
-					continue
-				}
-				// Add the folder itself first (for empty folders)
-				allFilesToDownload = append(allFilesToDownload, fileItem)
-				// Then add all its contents
-				allFilesToDownload = append(allFilesToDownload, folderFiles...)
-			} else {
-				allFilesToDownload = append(allFilesToDownload, fileItem)
+// logDownloadErrors writes a job's errors, if it had any, to a file named
+// for the time (e.g. dbox-errors-20240102-150405.log) in dir, recording its
+// path in ErrorLog. The status line only has room for a count. If another job
+// already wrote a log that second, a number is added (...-150405-2.log)
+// rather than replacing it.
+func logDownloadErrors(result DownloadCompleteMsg, dir string, mode os.FileMode, now time.Time) DownloadCompleteMsg {
+	if len(result.Errors) == 0 {
+		return result
+	}
+	var log strings.Builder
+	for _, e := range result.Errors {
+		if e.Item.Path != "" {
+			fmt.Fprintf(&log, "%s: ", e.Item.displayPath())
+		}
+		log.WriteString(e.Err + "\n")
+	}
+	f, err := createErrorLog(dir, now, mode)
+	if err == nil {
+		_, err = f.WriteString(log.String())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to write error log: %v", err)})
+		return result
+	}
+	result.ErrorLog = f.Name()
+	return result
+}
+
+// createErrorLog creates a new, uniquely named error log for now in dir,
+// never opening one that already exists.
+func createErrorLog(dir string, now time.Time, mode os.FileMode) (*os.File, error) {
+	base := filepath.Join(dir, "dbox-errors-"+now.Format("20060102-150405"))
+	name := base + ".log"
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+		name = fmt.Sprintf("%s-%d.log", base, n)
+	}
+}
+
+// openDownloaded opens the downloaded file with its default application if
+// open_after_download is on and the job was for a single file. Jobs for
+// several files or a folder are left alone, so they don't open dozens of
+// windows.
+func openDownloaded(fileItems []FileItem, result DownloadCompleteMsg, config *Config) DownloadCompleteMsg {
+	if !config.OpenAfterDownload || len(fileItems) != 1 || fileItems[0].IsFolder || len(result.Downloaded) != 1 {
+		return result
+	}
+	local := result.localPath(config, result.Downloaded[0])
+	if local == "" {
+		return result
+	}
+	if err := openLocal(local); err != nil {
+		result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to open %s: %v", local, err)})
+	}
+	return result
+}
+
+// waitForDownloadEvent returns the next message a running download job sends
+// on events, or nil once the job has closed it.
+func waitForDownloadEvent(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// downloadFiles downloads files and folders (recursively) into the download
+// directory, mirroring their Dropbox paths and skipping files already
+// downloaded: those idx (which may be nil) knows to be up to date, or else
+// per alreadyDownloaded. It is shared by the TUI and the `dbox download` subcommand.
+func downloadFiles(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress, idx *downloadIndex) DownloadCompleteMsg {
+	var downloaded, overwritten, skipped, ignored, tooDeep []FileItem
+	var errors []ItemError
+
+	maxDepth := -1
+	if config.MaxDepth != nil {
+		maxDepth = *config.MaxDepth
+	}
+
+	// Patterns in .dboxignore (in the download folder or the working
+	// directory) leave matching entries out of folders being downloaded.
+	cwd, _ := os.Getwd()
+	ignore, err := loadIgnoreRules(config.DownloadPath, cwd)
+	if err != nil {
+		// Without the patterns, ignored entries can't be told apart.
+		return DownloadCompleteMsg{Errors: []ItemError{{Err: "Downloaded nothing: " + err.Error()}}}
+	}
+
+	// Expand folders to include their contents, down to the max depth
+	var allFilesToDownload []FileItem
+	for _, fileItem := range fileItems {
+		if fileItem.IsFolder {
+			folderFiles, deeper, denied, err := getFilesToDepth(dbx, fileItem.Path, maxDepth, config.ListConcurrency, progress.folderScanned)
+			folderFiles, left := ignore.filter(folderFiles)
+			ignored = append(ignored, left...)
+			// Folders too deep to follow don't count inside ignored ones.
+			deeper, _ = ignore.filter(append(slices.Clone(left), deeper...))
+			tooDeep = append(tooDeep, deeper...)
+			errors = append(errors, denied...)
+			if err != nil {
+				errors = append(errors, ItemError{Item: fileItem, Err: fmt.Sprintf("Failed to list folder %s: %v", fileItem.Name, err)})
+				continue
+			}
+			// Add the folder itself first (for empty folders)
+			allFilesToDownload = append(allFilesToDownload, fileItem)
+			// Then add all its contents
+			allFilesToDownload = append(allFilesToDownload, folderFiles...)
+		} else {
+			allFilesToDownload = append(allFilesToDownload, fileItem)
+		}
+	}
+	progress.scanFinished()
+
+	// Now that folders are expanded, the job's total size is known (on top
+	// of any zips downloaded before, see downloadZips).
+	var totalBytes int64
+	for _, fileItem := range allFilesToDownload {
+		if !fileItem.IsFolder {
+			totalBytes += fileItem.Size
+		}
+	}
+	progress.total.Add(totalBytes)
+
+	renamed := renameCollisions(allFilesToDownload, config, idx)
+	localPathOf := func(fileItem FileItem) (string, error) {
+		if alt, ok := renamed[fileItem.Path]; ok {
+			return alt, nil
+		}
+		return itemLocalPath(config, fileItem)
+	}
+
+	// A resumed job counts the files its earlier run finished as done before
+	// downloading anything, so its progress starts where that run left off.
+	var present map[string]bool
+	for _, fileItem := range allFilesToDownload {
+		if fileItem.IsFolder {
+			continue
+		}
+		progress.files.Add(1)
+		if !progress.resumed {
+			continue
+		}
+		if localPath, err := localPathOf(fileItem); err == nil && upToDate(localPath, fileItem, config, idx) {
+			if present == nil {
+				present = map[string]bool{}
 			}
+			present[fileItem.Path] = true
+			skipped = append(skipped, fileItem)
+			progress.preloaded.Add(fileItem.Size)
+			progress.fileDone(fileItem, fileSkipped)
 		}
+	}
 
-		for _, fileItem := range allFilesToDownload {
-			localPath := filepath.Join(downloadDir, fileItem.Path)
-			if fileItem.IsFolder {
-				if err := os.MkdirAll(localPath, 0755); err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to create folder %s: %v", fileItem.Name, err))
-					continue
-				}
-				// Don't count empty folders in download count
+	for _, fileItem := range allFilesToDownload {
+		if present[fileItem.Path] {
+			continue
+		}
+		localPath, err := localPathOf(fileItem)
+		if err != nil {
+			errors = append(errors, ItemError{Item: fileItem, Err: fmt.Sprintf("Skipped %s: %v", fileItem.Name, err)})
+			if !fileItem.IsFolder {
+				progress.drop(fileItem.Size)
+				progress.fileDone(fileItem, fileFailed)
+			}
+			continue
+		}
+		if fileItem.IsFolder {
+			if err := os.MkdirAll(localPath, config.dirMode()); err != nil {
+				errors = append(errors, ItemError{Item: fileItem, Err: fmt.Sprintf("Failed to create folder %s: %v", fileItem.Name, err)})
+				continue
+			}
+			// Don't count empty folders in download count
+		} else {
+			// Anything that stops this file short removes the rest of its
+			// bytes from the job total so the ETA stays honest.
+			before := progress.bytes.Load()
+			if upToDate(localPath, fileItem, config, idx) {
+				skipped = append(skipped, fileItem)
+				progress.abandon(fileItem.Size, before)
+				progress.fileDone(fileItem, fileSkipped)
+				continue
+			}
+			parentDir := filepath.Dir(localPath)
+			if err := os.MkdirAll(parentDir, config.dirMode()); err != nil {
+				errors = append(errors, ItemError{Item: fileItem, Err: fmt.Sprintf("Failed to create directory for %s: %v", fileItem.Name, err)})
+				progress.abandon(fileItem.Size, before)
+				progress.fileDone(fileItem, fileFailed)
+				continue
+			}
+			_, statErr := os.Stat(localPath)
+			existed := statErr == nil
+			ctx, done := progress.startFile()
+			fileClient := cancellableClient{Client: dbx, ctx: ctx}
+			if fileItem.Exportable {
+				err = exportToFile(fileClient, fileItem.Path, localPath, config.PaperFormat, config.fileMode(), &progress.bytes)
 			} else {
-				if _, err := os.Stat(localPath); err == nil {
-					skipped = append(skipped, fileItem.Name)
-					continue
-				}
-				parentDir := filepath.Dir(localPath)
-				if err := os.MkdirAll(parentDir, 0755); err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to create directory for %s: %v", fileItem.Name, err))
-					continue
-				}
-				arg := files.NewDownloadArg(fileItem.Path)
-				_, contents, err := dbx.Download(arg)
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to download %s: %v", fileItem.Name, err))
-					continue
-				}
-				defer contents.Close()
-				contentBytes, err := io.ReadAll(contents)
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to read content of %s: %v", fileItem.Name, err))
-					continue
-				}
-				err = os.WriteFile(localPath, contentBytes, 0644)
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("Failed to write %s: %v", fileItem.Name, err))
-					continue
-				}
-				downloaded = append(downloaded, fileItem.Name)
+				err = downloadToFile(fileClient, fileItem.Path, fileItem.Size, localPath, config.fileMode(), &progress.bytes, &progress.preloaded)
+			}
+			cancelled := ctx.Err() != nil
+			done()
+			if err != nil && cancelled {
+				// Skipped with the skip_file key.
+				skipped = append(skipped, fileItem)
+				progress.abandon(fileItem.Size, before)
+				progress.fileDone(fileItem, fileSkipped)
+				continue
+			}
+			if err != nil {
+				errors = append(errors, ItemError{Item: fileItem, Err: fmt.Sprintf("Failed to download %s: %v", fileItem.Name, err)})
+				progress.abandon(fileItem.Size, before)
+				progress.fileDone(fileItem, fileFailed)
+				continue
+			}
+			idx.record(localPath, fileItem)
+			downloaded = append(downloaded, fileItem)
+			if existed {
+				overwritten = append(overwritten, fileItem)
 			}
+			progress.fileDone(fileItem, fileDownloaded)
+		}
+	}
+
+	return DownloadCompleteMsg{
+		Downloaded:  downloaded,
+		Overwritten: overwritten,
+		Skipped:     skipped,
+		Ignored:     ignored,
+		TooDeep:     tooDeep,
+		Errors:      errors,
+		Renamed:     renamed,
+	}
+}
+
+// Ways of deciding a file at the download path is already downloaded (the
+// skip_existing setting).
+const (
+	skipIfExists = "exists" // any file there
+	skipIfSize   = "size"   // a file of the same size
+	skipIfHash   = "hash"   // a file with the same content hash
+)
+
+// validSkipExisting reports whether mode is a supported skip_existing value.
+func validSkipExisting(mode string) bool {
+	return mode == skipIfExists || mode == skipIfSize || mode == skipIfHash
+}
+
+// upToDate reports whether the file at localPath needn't be downloaded again:
+// the index (which may be nil) knows it's current, or, if the index doesn't
+// know it, alreadyDownloaded says so.
+func upToDate(localPath string, item FileItem, config *Config, idx *downloadIndex) bool {
+	current, known := idx.check(localPath, item)
+	return current || !known && alreadyDownloaded(localPath, item, config.SkipExisting)
+}
+
+// alreadyDownloaded reports whether the file at localPath can stand in for
+// item, so its download is skipped. Other files there (stale or cut short)
+// are downloaded again over the top. Paper docs are exported, so they can't
+// be compared and any file there counts.
+func alreadyDownloaded(localPath string, item FileItem, mode string) bool {
+	info, err := os.Stat(localPath)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if item.Exportable || mode == skipIfExists {
+		return true
+	}
+	if info.Size() != item.Size {
+		return false
+	}
+	if mode == skipIfHash && item.ContentHash != "" {
+		hash, err := dropboxContentHash(localPath)
+		return err == nil && hash == item.ContentHash
+	}
+	return true
+}
+
+// partSuffix is appended to a file's name while it downloads. The final name
+// only appears once the whole file has arrived, so an interrupted download is
+// never mistaken for a finished one, and the .part file lets a later attempt
+// pick up where it stopped.
+const partSuffix = ".part"
+
+// downloadToFile streams a Dropbox file of the given size to localPath, adding
+// each byte received to counter. Bytes already in a .part file from an earlier
+// attempt are kept, and added to preloaded instead so they don't count toward
+// the rate; only the rest is requested with a Range header. The
+// .part file is renamed into place once its size matches (and, when resumed,
+// its content hash too, since the two halves came from separate requests).
+// The file is created with mode.
+func downloadToFile(dbx files.Client, dropboxPath string, size int64, localPath string, mode os.FileMode, counter, preloaded *atomic.Int64) error {
+	partPath := localPath + partSuffix
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+		if offset >= size {
+			// Nothing sensible to resume from; start over.
+			os.Remove(partPath)
+			offset = 0
 		}
+	}
 
-		return DownloadCompleteMsg{
-			Downloaded: downloaded,
-			Skipped:    skipped,
-			Errors:     errors,
+	arg := files.NewDownloadArg(dropboxPath)
+	if offset > 0 {
+		arg.ExtraHeaders = map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)}
+	}
+	meta, contents, err := dbx.Download(arg)
+	if err != nil {
+		return err
+	}
+	defer contents.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(partPath, flags, mode)
+	if err != nil {
+		return err
+	}
+	// Bytes from the earlier attempt count as done, but not as received.
+	preloaded.Add(offset)
+	if _, err := io.Copy(out, countingReader{r: contents, n: counter}); err != nil {
+		out.Close()
+		return fmt.Errorf("write failed (will resume from %s): %w", partPath, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		if info.Size() > size {
+			os.Remove(partPath)
+		}
+		return fmt.Errorf("incomplete download: got %d of %d bytes", info.Size(), size)
+	}
+	if offset > 0 && meta != nil && meta.ContentHash != "" {
+		hash, err := dropboxContentHash(partPath)
+		if err != nil {
+			return err
+		}
+		if hash != meta.ContentHash {
+			os.Remove(partPath)
+			return fmt.Errorf("resumed download doesn't match the file in Dropbox; it will be fetched again")
 		}
 	}
+	return os.Rename(partPath, localPath)
 }
 
-// getAllFilesInFolder recursively gets all files in a folder and its subfolders
-func getAllFilesInFolder(dbx files.Client, folderPath string) ([]FileItem, error) {
-	var allFiles []FileItem
+// cancellableClient cuts short the downloads, zips, and exports it starts once ctx
+// is cancelled, by closing their contents mid-transfer.
+type cancellableClient struct {
+	files.Client
+	ctx context.Context
+}
 
-	// List files in the current folder
-	arg := files.NewListFolderArg(folderPath)
-	result, err := dbx.ListFolder(arg)
+func (c cancellableClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	res, contents, err := c.Client.Download(arg)
 	if err != nil {
-		return nil, err
+		return res, contents, err
 	}
+	return res, closeOnCancel(c.ctx, contents), nil
+}
 
-	// Process entries
-	for _, entry := range result.Entries {
-		// Skip deleted files
-		if _, ok := entry.(*files.DeletedMetadata); ok {
-			continue
+func (c cancellableClient) Export(arg *files.ExportArg) (*files.ExportResult, io.ReadCloser, error) {
+	res, contents, err := c.Client.Export(arg)
+	if err != nil {
+		return res, contents, err
+	}
+	return res, closeOnCancel(c.ctx, contents), nil
+}
+
+func (c cancellableClient) DownloadZip(arg *files.DownloadZipArg) (*files.DownloadZipResult, io.ReadCloser, error) {
+	res, contents, err := c.Client.DownloadZip(arg)
+	if err != nil {
+		return res, contents, err
+	}
+	return res, closeOnCancel(c.ctx, contents), nil
+}
+
+// closeOnCancel closes contents as soon as ctx is cancelled, unless it's
+// closed first.
+func closeOnCancel(ctx context.Context, contents io.ReadCloser) io.ReadCloser {
+	stop := context.AfterFunc(ctx, func() { contents.Close() })
+	return stopOnClose{ReadCloser: contents, stop: stop}
+}
+
+// stopOnClose stops watching for cancellation once its contents are closed.
+type stopOnClose struct {
+	io.ReadCloser
+	stop func() bool
+}
+
+func (s stopOnClose) Close() error {
+	s.stop()
+	return s.ReadCloser.Close()
+}
+
+// writeLocalFile copies contents to localPath, created with mode, and closes
+// it, counting bytes into counter. A partially written file is removed on
+// failure.
+func writeLocalFile(contents io.ReadCloser, localPath string, mode os.FileMode, counter *atomic.Int64) error {
+	defer contents.Close()
+
+	out, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, countingReader{r: contents, n: counter}); err != nil {
+		out.Close()
+		os.Remove(localPath)
+		return fmt.Errorf("write failed: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(localPath)
+		return fmt.Errorf("write failed: %w", err)
+	}
+	return nil
+}
+
+// getAllFilesInFolder recursively gets all files in a folder and its subfolders.
+// Subfolders are listed concurrently (at most concurrency ListFolder calls in
+// flight at once, or defaultListConcurrency if it's below 1), but the result
+// is deterministic: each folder's entries are sorted by name and every folder
+// is immediately followed by its contents. Unlike a download, it fails if any
+// subfolder can't be listed.
+func getAllFilesInFolder(dbx files.Client, folderPath string, concurrency int) ([]FileItem, error) {
+	items, _, denied, err := getFilesToDepth(dbx, folderPath, -1, concurrency, nil)
+	if err == nil && len(denied) > 0 {
+		err = errors.New(denied[0].Err)
+	}
+	return items, err
+}
+
+// getFilesToDepth is getAllFilesInFolder descending at most maxDepth levels
+// below folderPath: 0 lists only its direct contents, and a negative depth
+// has no limit. Subfolders that would go deeper are left out of the result and
+// returned separately in tooDeep, unlisted. Subfolders dbox isn't allowed to
+// list (see isPermissionError) are skipped and returned in denied, so one
+// restricted folder doesn't stop the rest of the tree. If scanned is set, it's
+// called (from several goroutines at once) with the number of files in each
+// folder as it's listed.
+func getFilesToDepth(dbx files.Client, folderPath string, maxDepth, concurrency int, scanned func(files int)) (items, tooDeep []FileItem, denied []ItemError, err error) {
+	if concurrency < 1 {
+		concurrency = defaultListConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	return listTree(dbx, folderPath, maxDepth, sem, scanned)
+}
+
+// listTree lists folderPath and then each of its subfolders in parallel,
+// descending depthLeft more levels (negative for no limit). Each subtree's
+// results are collected into their own slot and merged in order once all of
+// them finish, so no locking is needed and the output order doesn't depend on
+// which listing returns first.
+func listTree(dbx files.Client, folderPath string, depthLeft int, sem chan struct{}, scanned func(files int)) ([]FileItem, []FileItem, []ItemError, error) {
+	sem <- struct{}{}
+	entries, err := listFolderEntries(dbx, folderPath)
+	<-sem
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if scanned != nil {
+		files := 0
+		for _, entry := range entries {
+			if !entry.IsFolder {
+				files++
+			}
 		}
+		scanned(files)
+	}
 
-		switch v := entry.(type) {
-		case *files.FileMetadata:
-			allFiles = append(allFiles, FileItem{
-				Name:     v.Name,
-				Path:     v.PathLower,
-				IsFolder: false,
-				Size:     int64(v.Size),
-				Modified: v.ServerModified,
-			})
-		case *files.FolderMetadata:
-			// Add the folder itself
-			allFiles = append(allFiles, FileItem{
-				Name:     v.Name,
-				Path:     v.PathLower,
-				IsFolder: true,
-				Size:     0,
-				Modified: time.Now(),
-			})
-
-			// Recursively get files in this subfolder
-			subFiles, err := getAllFilesInFolder(dbx, v.PathLower)
+	subtrees := make([][]FileItem, len(entries))
+	skipped := make([][]FileItem, len(entries))
+	refused := make([][]ItemError, len(entries))
+	var g errgroup.Group
+	for i, entry := range entries {
+		if !entry.IsFolder || depthLeft == 0 {
+			continue
+		}
+		g.Go(func() error {
+			subFiles, subSkipped, subRefused, err := listTree(dbx, entry.Path, depthLeft-1, sem, scanned)
+			if isPermissionError(err) {
+				refused[i] = []ItemError{{Item: entry, Err: fmt.Sprintf("No permission to list folder %s: %v", entry.Name, err)}}
+				return nil
+			}
 			if err != nil {
-				return nil, err
+				return err
 			}
-			allFiles = append(allFiles, subFiles...)
+			subtrees[i], skipped[i], refused[i] = subFiles, subSkipped, subRefused
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var allFiles, tooDeep []FileItem
+	var denied []ItemError
+	for i, entry := range entries {
+		if entry.IsFolder && depthLeft == 0 {
+			tooDeep = append(tooDeep, entry)
+			continue
 		}
+		allFiles = append(allFiles, entry)
+		allFiles = append(allFiles, subtrees[i]...)
+		tooDeep = append(tooDeep, skipped[i]...)
+		denied = append(denied, refused[i]...)
+	}
+	return allFiles, tooDeep, denied, nil
+}
+
+// isPermissionError reports whether a listing failed because the account
+// isn't allowed to see the folder, as with restricted team folders.
+func isPermissionError(err error) bool {
+	switch e := err.(type) {
+	case files.ListFolderAPIError:
+		return e.EndpointError != nil && e.EndpointError.Path != nil &&
+			e.EndpointError.Path.Tag == files.LookupErrorRestrictedContent
+	case auth.AccessAPIError:
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "no_permission")
+}
+
+// listFolderEntries lists the direct children of a folder, sorted by name.
+func listFolderEntries(dbx files.Client, folderPath string) ([]FileItem, error) {
+	entries, cursor, err := listFolderPage(dbx, folderPath, 0)
+	for err == nil && cursor != "" {
+		var more []FileItem
+		more, cursor, err = listFolderMore(dbx, cursor)
+		entries = append(entries, more...)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return allFiles, nil
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// fileItemFromMetadata converts Dropbox metadata into a FileItem. It reports
+// false for entries that aren't files or folders (e.g. deleted entries).
+func fileItemFromMetadata(entry files.IsMetadata) (FileItem, bool) {
+	switch v := entry.(type) {
+	case *files.FileMetadata:
+		return FileItem{
+			Name:        v.Name,
+			Path:        v.PathLower,
+			DisplayPath: v.PathDisplay,
+			IsFolder:    false,
+			Size:        int64(v.Size),
+			Modified:    v.ServerModified,
+			ContentHash: v.ContentHash,
+			Exportable:  isExportOnly(v),
+			Shared:      v.HasExplicitSharedMembers,
+		}, true
+	case *files.FolderMetadata:
+		return FileItem{
+			Name:        v.Name,
+			Path:        v.PathLower,
+			DisplayPath: v.PathDisplay,
+			IsFolder:    true,
+			Size:        0,
+			Modified:    time.Now(), // Folders don't have modification time in Dropbox API
+		}, true
+	default:
+		return FileItem{}, false
+	}
 }
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeFilesClient serves ListFolder from an in-memory tree keyed by lowercased
+// folder path. Any other method panics via the nil embedded interface.
+type fakeFilesClient struct {
+	files.Client
+	tree map[string][]files.IsMetadata
+	// denied lists folders that fail with restricted_content
+	denied map[string]bool
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (f *fakeFilesClient) ListFolder(arg *files.ListFolderArg) (*files.ListFolderResult, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if f.denied[arg.Path] {
+		return nil, files.ListFolderAPIError{EndpointError: &files.ListFolderError{
+			Tagged: dropbox.Tagged{Tag: files.ListFolderErrorPath},
+			Path:   &files.LookupError{Tagged: dropbox.Tagged{Tag: files.LookupErrorRestrictedContent}},
+		}}
+	}
+	entries, ok := f.tree[arg.Path]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", arg.Path)
+	}
+	return &files.ListFolderResult{Entries: entries}, nil
+}
+
+func fakeFile(path string) *files.FileMetadata {
+	name := path[strings.LastIndex(path, "/")+1:]
+	return &files.FileMetadata{Metadata: files.Metadata{Name: name, PathLower: path}, Size: 1}
+}
+
+func fakeFolder(path string) *files.FolderMetadata {
+	name := path[strings.LastIndex(path, "/")+1:]
+	return &files.FolderMetadata{Metadata: files.Metadata{Name: name, PathLower: path}}
+}
+
+func TestGetAllFilesInFolderDeterministic(t *testing.T) {
+	// Entries are deliberately out of order; the result must be sorted by name
+	// with each folder followed by its own contents.
+	dbx := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root": {
+			fakeFile("/root/z.txt"),
+			fakeFolder("/root/b"),
+			fakeFolder("/root/a"),
+		},
+		"/root/a":   {fakeFile("/root/a/2.txt"), fakeFile("/root/a/1.txt")},
+		"/root/b":   {fakeFolder("/root/b/c")},
+		"/root/b/c": {fakeFile("/root/b/c/deep.txt")},
+	}}
+
+	want := "/root/a,/root/a/1.txt,/root/a/2.txt,/root/b,/root/b/c,/root/b/c/deep.txt,/root/z.txt"
+	for i := 0; i < 20; i++ {
+		items, err := getAllFilesInFolder(dbx, "/root", defaultListConcurrency)
+		if err != nil {
+			t.Fatalf("getAllFilesInFolder: %v", err)
+		}
+		var got []string
+		for _, it := range items {
+			got = append(got, it.Path)
+		}
+		if strings.Join(got, ",") != want {
+			t.Fatalf("run %d: got %v, want %s", i, got, want)
+		}
+	}
+}
+
+func TestGetFilesToDepth(t *testing.T) {
+	dbx := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root":     {fakeFile("/root/z.txt"), fakeFolder("/root/b"), fakeFolder("/root/a")},
+		"/root/a":   {fakeFile("/root/a/1.txt")},
+		"/root/b":   {fakeFolder("/root/b/c")},
+		"/root/b/c": {fakeFile("/root/b/c/deep.txt")},
+	}}
+	paths := func(items []FileItem) string {
+		var out []string
+		for _, it := range items {
+			out = append(out, it.Path)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		maxDepth     int
+		want, unseen string
+	}{
+		{0, "/root/z.txt", "/root/a,/root/b"},
+		{1, "/root/a,/root/a/1.txt,/root/b,/root/z.txt", "/root/b/c"},
+		{2, "/root/a,/root/a/1.txt,/root/b,/root/b/c,/root/b/c/deep.txt,/root/z.txt", ""},
+		{-1, "/root/a,/root/a/1.txt,/root/b,/root/b/c,/root/b/c/deep.txt,/root/z.txt", ""},
+	}
+	for _, tt := range tests {
+		items, tooDeep, _, err := getFilesToDepth(dbx, "/root", tt.maxDepth, defaultListConcurrency, nil)
+		if err != nil {
+			t.Fatalf("depth %d: %v", tt.maxDepth, err)
+		}
+		if got := paths(items); got != tt.want {
+			t.Errorf("depth %d: items = %s, want %s", tt.maxDepth, got, tt.want)
+		}
+		if got := paths(tooDeep); got != tt.unseen {
+			t.Errorf("depth %d: too deep = %s, want %s", tt.maxDepth, got, tt.unseen)
+		}
+	}
+	// The folders left out are never listed.
+	if _, _, _, err := getFilesToDepth(&fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root": {fakeFolder("/root/unlistable")},
+	}}, "/root", 0, defaultListConcurrency, nil); err != nil {
+		t.Errorf("depth 0 listed a subfolder: %v", err)
+	}
+}
+
+func TestListingSkipsRestrictedFolders(t *testing.T) {
+	dbx := &fakeFilesClient{
+		tree: map[string][]files.IsMetadata{
+			"/root":      {fakeFolder("/root/open"), fakeFolder("/root/secret")},
+			"/root/open": {fakeFile("/root/open/a.txt")},
+		},
+		denied: map[string]bool{"/root/secret": true},
+	}
+
+	items, _, denied, err := getFilesToDepth(dbx, "/root", -1, defaultListConcurrency, nil)
+	if err != nil {
+		t.Fatalf("a restricted subfolder shouldn't fail the listing: %v", err)
+	}
+	if len(items) != 3 || items[1].Path != "/root/open/a.txt" {
+		t.Errorf("items = %+v, want the open folder's contents and both folders", items)
+	}
+	if len(denied) != 1 || denied[0].Item.Path != "/root/secret" || !strings.Contains(denied[0].Err, "No permission") {
+		t.Errorf("denied = %+v", denied)
+	}
+
+	// A download carries on and reports the folder it couldn't list.
+	dir := t.TempDir()
+	dbx.tree["/root"] = []files.IsMetadata{fakeFolder("/root/secret")}
+	result := downloadFiles(dbx, []FileItem{{Name: "root", Path: "/root", IsFolder: true}}, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()), nil)
+	if len(result.Errors) != 1 || result.Errors[0].Item.Path != "/root/secret" {
+		t.Errorf("errors = %+v, want the restricted folder", result.Errors)
+	}
+
+	// Listing a whole tree for anything else still needs all of it.
+	if _, err := getAllFilesInFolder(dbx, "/root", defaultListConcurrency); err == nil {
+		t.Error("getAllFilesInFolder should fail when a subfolder can't be listed")
+	}
+}
+
+func TestGetAllFilesInFolderBoundedConcurrency(t *testing.T) {
+	tree := map[string][]files.IsMetadata{}
+	var root []files.IsMetadata
+	for i := 0; i < 30; i++ {
+		dir := fmt.Sprintf("/wide/d%02d", i)
+		root = append(root, fakeFolder(dir))
+		tree[dir] = []files.IsMetadata{fakeFile(dir + "/f.txt")}
+	}
+	tree["/wide"] = root
+	dbx := &fakeFilesClient{tree: tree}
+
+	items, err := getAllFilesInFolder(dbx, "/wide", defaultListConcurrency)
+	if err != nil {
+		t.Fatalf("getAllFilesInFolder: %v", err)
+	}
+	if len(items) != 60 {
+		t.Errorf("got %d items, want 60", len(items))
+	}
+	if dbx.peak > defaultListConcurrency {
+		t.Errorf("peak concurrent ListFolder calls = %d, want <= %d", dbx.peak, defaultListConcurrency)
+	}
+}
+
+func TestGetAllFilesInFolderError(t *testing.T) {
+	dbx := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root": {fakeFolder("/root/missing")},
+	}}
+	if _, err := getAllFilesInFolder(dbx, "/root", defaultListConcurrency); err == nil {
+		t.Error("expected an error when a subfolder can't be listed")
+	}
+}
+
+// fakeDownloadClient serves Download from in-memory contents, honoring a
+// "bytes=N-" Range header the way Dropbox does.
+type fakeDownloadClient struct {
+	files.Client
+	content string
+	hash    string
+	ranges  []string
+}
+
+func (f *fakeDownloadClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	body := f.content
+	rng := arg.ExtraHeaders["Range"]
+	f.ranges = append(f.ranges, rng)
+	if rng != "" {
+		var start int
+		fmt.Sscanf(rng, "bytes=%d-", &start)
+		body = body[start:]
+	}
+	meta := &files.FileMetadata{Size: uint64(len(f.content)), ContentHash: f.hash}
+	return meta, io.NopCloser(strings.NewReader(body)), nil
+}
+
+func TestDownloadToFileResumes(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	os.WriteFile(src, []byte(content), 0644)
+	hash, err := dropboxContentHash(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := filepath.Join(dir, "big.bin")
+	// An earlier attempt got the first 300 bytes.
+	os.WriteFile(local+partSuffix, []byte(content[:300]), 0644)
+
+	dbx := &fakeDownloadClient{content: content, hash: hash}
+	var counter, preloaded atomic.Int64
+	if err := downloadToFile(dbx, "/big.bin", int64(len(content)), local, 0644, &counter, &preloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dbx.ranges) != 1 || dbx.ranges[0] != "bytes=300-" {
+		t.Errorf("requests = %q, want one for bytes=300-", dbx.ranges)
+	}
+	got, _ := os.ReadFile(local)
+	if string(got) != content {
+		t.Error("resumed file doesn't match the original")
+	}
+	if _, err := os.Stat(local + partSuffix); !os.IsNotExist(err) {
+		t.Error(".part file should be renamed into place")
+	}
+	// The 300 bytes already there are done, but weren't received this time.
+	if counter.Load() != 700 || preloaded.Load() != 300 {
+		t.Errorf("counted %d bytes received and %d preloaded, want 700 and 300", counter.Load(), preloaded.Load())
+	}
+}
+
+func TestDownloadToFileRejectsMismatchedResume(t *testing.T) {
+	content := strings.Repeat("a", 100)
+	local := filepath.Join(t.TempDir(), "f")
+	// Left over from a different version of the file.
+	os.WriteFile(local+partSuffix, []byte(strings.Repeat("b", 40)), 0644)
+
+	dbx := &fakeDownloadClient{content: content, hash: "not-the-hash-of-the-mix"}
+	var counter, preloaded atomic.Int64
+	if err := downloadToFile(dbx, "/f", int64(len(content)), local, 0644, &counter, &preloaded); err == nil {
+		t.Fatal("expected a content hash mismatch")
+	}
+	if _, err := os.Stat(local); !os.IsNotExist(err) {
+		t.Error("mismatched download shouldn't be put in place")
+	}
+	if _, err := os.Stat(local + partSuffix); !os.IsNotExist(err) {
+		t.Error("mismatched .part should be discarded so the next attempt starts over")
+	}
+}
+
+func TestDownloadFilesReportsEachFile(t *testing.T) {
+	dir := t.TempDir()
+	// b.txt is already downloaded, so it's skipped.
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("hello"), 0644)
+
+	dbx := &fakeDownloadClient{content: "hello"}
+	items := []FileItem{
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+		{Name: "b.txt", Path: "/b.txt", Size: 5},
+	}
+	progress := newDownloadProgress(time.Now())
+	var events []FileDoneMsg
+	progress.onFile = func(msg FileDoneMsg) { events = append(events, msg) }
+
+	downloadFiles(dbx, items, &Config{DownloadPath: dir}, progress, nil)
+
+	var tally downloadTally
+	for _, e := range events {
+		tally.add(e)
+	}
+	if len(events) != 2 || tally.downloaded != 1 || tally.skipped != 1 || tally.last != "/b.txt" {
+		t.Errorf("events = %+v, tally = %+v", events, tally)
+	}
+}
+
+func TestDownloadFilesEmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	dbx := &fakeDownloadClient{}
+	progress := newDownloadProgress(time.Now())
+	var events []FileDoneMsg
+	progress.onFile = func(msg FileDoneMsg) { events = append(events, msg) }
+
+	downloadFiles(dbx, []FileItem{{Name: "empty.txt", Path: "/empty.txt"}}, &Config{DownloadPath: dir}, progress, nil)
+
+	if len(events) != 1 || events[0].Outcome != fileDownloaded {
+		t.Fatalf("events = %+v, want one download", events)
+	}
+	info, err := os.Stat(filepath.Join(dir, "empty.txt"))
+	if err != nil || info.Size() != 0 {
+		t.Errorf("empty file not written: %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "empty.txt"+partSuffix)); !os.IsNotExist(err) {
+		t.Error(".part file left behind")
+	}
+}
+
+func TestDownloadFilesMode(t *testing.T) {
+	dir := t.TempDir()
+	dbx := &fakeDownloadClient{content: "secret"}
+	downloadFiles(dbx, []FileItem{{Name: "a.txt", Path: "/a.txt", Size: 6}}, &Config{DownloadPath: dir, FileMode: 0600}, newDownloadProgress(time.Now()), nil)
+
+	info, err := os.Stat(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("mode = %o, want 600", got)
+	}
+}
+
+func TestAlreadyDownloaded(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := dropboxContentHash(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		item FileItem
+		mode string
+		want bool
+	}{
+		{"missing", filepath.Join(dir, "nope.txt"), FileItem{Size: 5}, skipIfExists, false},
+		{"exists ignores size", local, FileItem{Size: 99}, skipIfExists, true},
+		{"same size", local, FileItem{Size: 5}, skipIfSize, true},
+		{"different size", local, FileItem{Size: 99}, skipIfSize, false},
+		{"unset mode compares size", local, FileItem{Size: 99}, "", false},
+		{"same hash", local, FileItem{Size: 5, ContentHash: hash}, skipIfHash, true},
+		{"same size, different hash", local, FileItem{Size: 5, ContentHash: "abc"}, skipIfHash, false},
+		{"Paper doc", local, FileItem{Size: 99, Exportable: true}, skipIfHash, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alreadyDownloaded(tt.path, tt.item, tt.mode); got != tt.want {
+				t.Errorf("alreadyDownloaded = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForDownloadEvent(t *testing.T) {
+	events := make(chan tea.Msg, 2)
+	events <- FileDoneMsg{Item: FileItem{Path: "/a"}}
+	close(events)
+
+	if msg, ok := waitForDownloadEvent(events)().(FileDoneMsg); !ok || msg.Item.Path != "/a" {
+		t.Errorf("first event = %#v, want the FileDoneMsg", msg)
+	}
+	if msg := waitForDownloadEvent(events)(); msg != nil {
+		t.Errorf("after close got %#v, want nil", msg)
+	}
+}
+
+func TestDownloadFilesResumeCountsFinishedFiles(t *testing.T) {
+	dir := t.TempDir()
+	// The interrupted run got as far as a.txt.
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+
+	dbx := &fakeDownloadClient{content: "hello"}
+	items := []FileItem{
+		{Name: "b.txt", Path: "/b.txt", Size: 5},
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+	}
+	progress := newDownloadProgress(time.Now())
+	progress.resumed = true
+	var events []FileDoneMsg
+	progress.onFile = func(msg FileDoneMsg) {
+		if len(events) == 0 {
+			// a.txt is counted before anything is downloaded.
+			if f, _ := progress.fraction(); msg.Item.Path != "/a.txt" || f != 0.5 || progress.completed.Load() != 1 {
+				t.Errorf("first event %+v at fraction %v, %d completed", msg, f, progress.completed.Load())
+			}
+		}
+		events = append(events, msg)
+	}
+
+	result := downloadFiles(dbx, items, &Config{DownloadPath: dir}, progress, nil)
+
+	if len(result.Downloaded) != 1 || len(result.Skipped) != 1 || len(events) != 2 {
+		t.Fatalf("result = %+v, events = %+v", result, events)
+	}
+	if progress.files.Load() != 2 || progress.completed.Load() != 2 || progress.bytes.Load() != 5 || progress.preloaded.Load() != 5 {
+		t.Errorf("files %d, completed %d, bytes %d, preloaded %d", progress.files.Load(), progress.completed.Load(), progress.bytes.Load(), progress.preloaded.Load())
+	}
+	if f, _ := progress.fraction(); f != 1 {
+		t.Errorf("fraction = %v, want 1", f)
+	}
+}
+
+// stallingClient serves /big.bin as a download that never sends anything,
+// skipping it as soon as it starts, and other files from content.
+type stallingClient struct {
+	fakeDownloadClient
+	progress *downloadProgress
+}
+
+func (c *stallingClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	if arg.Path != "/big.bin" {
+		return c.fakeDownloadClient.Download(arg)
+	}
+	r, _ := io.Pipe()
+	c.progress.skipFile()
+	return &files.FileMetadata{}, r, nil
+}
+
+func TestDownloadFilesSkipCurrentFile(t *testing.T) {
+	dir := t.TempDir()
+	progress := newDownloadProgress(time.Now())
+	dbx := &stallingClient{fakeDownloadClient: fakeDownloadClient{content: "hello"}, progress: progress}
+	items := []FileItem{
+		{Name: "big.bin", Path: "/big.bin", Size: 1 << 30},
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+	}
+
+	result := downloadFiles(dbx, items, &Config{DownloadPath: dir}, progress, nil)
+
+	if len(result.Downloaded) != 1 || result.Downloaded[0].Path != "/a.txt" {
+		t.Errorf("downloaded = %+v, want only a.txt", result.Downloaded)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Path != "/big.bin" || len(result.Errors) > 0 {
+		t.Errorf("skipped = %+v, errors = %+v, want big.bin skipped", result.Skipped, result.Errors)
+	}
+	if progress.skipFile() {
+		t.Error("skipFile reported a file downloading after the job finished")
+	}
+}
+
+// blockingClient serves /big.bin as a download that never sends anything,
+// closing started once it has begun, and other files from content.
+type blockingClient struct {
+	fakeDownloadClient
+	started chan struct{}
+}
+
+func (c *blockingClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	if arg.Path != "/big.bin" {
+		return c.fakeDownloadClient.Download(arg)
+	}
+	r, _ := io.Pipe()
+	close(c.started)
+	return &files.FileMetadata{}, r, nil
+}
+
+func TestSkipFileKey(t *testing.T) {
+	m := initialModel(&Config{})
+	m.downloading = true
+	m.progress = newDownloadProgress(time.Now())
+	dbx := &blockingClient{fakeDownloadClient: fakeDownloadClient{content: "hello"}, started: make(chan struct{})}
+	items := []FileItem{
+		{Name: "big.bin", Path: "/big.bin", Size: 1 << 30},
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+	}
+	results := make(chan DownloadCompleteMsg)
+	go func() {
+		results <- downloadFiles(dbx, items, &Config{DownloadPath: t.TempDir()}, m.progress, nil)
+	}()
+
+	<-dbx.started
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	select {
+	case result := <-results:
+		if len(result.Skipped) != 1 || result.Skipped[0].Path != "/big.bin" || len(result.Downloaded) != 1 {
+			t.Errorf("skipped = %+v, downloaded = %+v, want big.bin skipped", result.Skipped, result.Downloaded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the skip key didn't cancel the file being downloaded")
+	}
+}
+
+func TestDownloadFilesReportsOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	// A stale copy of a.txt, which a size check doesn't accept.
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("old"), 0644)
+
+	dbx := &fakeDownloadClient{content: "hello"}
+	items := []FileItem{
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+		{Name: "b.txt", Path: "/b.txt", Size: 5},
+	}
+	result := downloadFiles(dbx, items, &Config{DownloadPath: dir, SkipExisting: skipIfSize}, newDownloadProgress(time.Now()), nil)
+
+	if len(result.Downloaded) != 2 || len(result.Overwritten) != 1 || result.Overwritten[0].Path != "/a.txt" {
+		t.Errorf("downloaded = %+v, overwritten = %+v", result.Downloaded, result.Overwritten)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "hello" {
+		t.Errorf("a.txt = %q, want the new contents", data)
+	}
+}
+
+func TestLogDownloadErrors(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+
+	if result := logDownloadErrors(DownloadCompleteMsg{}, dir, 0644, now); result.ErrorLog != "" {
+		t.Errorf("a job without errors shouldn't write a log, got %q", result.ErrorLog)
+	}
+
+	result := logDownloadErrors(DownloadCompleteMsg{Errors: []ItemError{
+		{Item: FileItem{Path: "/a.txt", DisplayPath: "/A.txt"}, Err: "Failed to download A.txt: boom"},
+		{Err: "Failed to update download queue: disk full"},
+	}}, dir, 0644, now)
+	want := filepath.Join(dir, "dbox-errors-20240102-150405.log")
+	if result.ErrorLog != want {
+		t.Fatalf("ErrorLog = %q, want %q", result.ErrorLog, want)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "/A.txt: Failed to download A.txt: boom\nFailed to update download queue: disk full\n" {
+		t.Errorf("log = %q", got)
+	}
+	// A second job finishing the same second gets its own log.
+	again := logDownloadErrors(DownloadCompleteMsg{Errors: []ItemError{{Err: "other"}}}, dir, 0644, now)
+	if want := filepath.Join(dir, "dbox-errors-20240102-150405-2.log"); again.ErrorLog != want {
+		t.Errorf("second ErrorLog = %q, want %q", again.ErrorLog, want)
+	}
+	if data, _ := os.ReadFile(want); !strings.HasPrefix(string(data), "/A.txt") {
+		t.Errorf("first log was replaced: %q", data)
+	}
+}
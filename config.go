@@ -1,23 +1,283 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds application configuration
 type Config struct {
-	DownloadPath string
+	DownloadPath string `yaml:"-"`
+	// PaperFormat is the format Paper docs are exported to when downloaded:
+	// "markdown" or "html".
+	PaperFormat string `yaml:"paper_format"`
+	// FoldersFirst lists folders ahead of files; it can also be toggled
+	// while browsing.
+	FoldersFirst bool `yaml:"folders_first"`
+	// RefreshOnFocus reloads the current folder whenever the terminal
+	// regains focus, picking up changes made elsewhere.
+	RefreshOnFocus bool `yaml:"refresh_on_focus"`
+	// AltScreen runs the TUI in the terminal's alternate screen, which is
+	// cleared on exit. Turning it off (or passing --no-altscreen) leaves the
+	// last screen, such as a download summary, in the scrollback.
+	AltScreen bool `yaml:"alt_screen"`
+	// DownloadCursor makes download take the entry under the cursor when
+	// nothing is selected. Turning it off requires an explicit selection.
+	DownloadCursor bool `yaml:"download_cursor"`
+	// WrapCursor makes up at the first entry jump to the last, and down at
+	// the last jump to the first.
+	WrapCursor bool `yaml:"wrap_cursor"`
+	// ConfirmLeaveSelection asks before quitting, or clearing the selection
+	// screen, would drop what's selected.
+	ConfirmLeaveSelection bool `yaml:"confirm_leave_selection"`
+	// OpenAfterDownload opens a file with its default application once it's
+	// downloaded, when it was downloaded on its own.
+	OpenAfterDownload bool `yaml:"open_after_download"`
+	// Keys rebinds browse-mode actions, mapping an action name to its keys
+	// (see defaultKeys). Actions left out keep their default keys.
+	Keys map[string][]string `yaml:"keys"`
+	// Trash, if set, is a Dropbox folder (e.g. "/.dbox-trash") that deletes
+	// move entries into instead of deleting them; emptying it deletes them.
+	Trash string `yaml:"trash"`
+	// SkipExisting decides when a file already at its download path is
+	// skipped: "exists" (any file), "size" (same size), or "hash" (same
+	// content hash). Others are downloaded again.
+	SkipExisting string `yaml:"skip_existing"`
+	// OrganizeByExtension saves downloaded files into a folder per
+	// extension (jpg/, pdf/, other/ for none) directly under DownloadPath,
+	// instead of mirroring their Dropbox folders.
+	OrganizeByExtension bool `yaml:"organize_by_extension"`
+	// FileMode and DirMode are the permissions downloaded files and the
+	// folders made for them get, written in octal like 0600.
+	FileMode permMode `yaml:"file_mode"`
+	DirMode  permMode `yaml:"dir_mode"`
+	// MaxDepth limits how far downloads descend into selected folders: 0
+	// takes only a folder's direct contents, 1 one level of subfolders, and
+	// so on. Nil means no limit.
+	MaxDepth *int `yaml:"max_depth"`
+	// ShowRevisions counts the revisions of the file under the cursor and
+	// shows them in the list. Each count is a Dropbox call, so it's off by
+	// default.
+	ShowRevisions bool `yaml:"show_revisions"`
+	// ListPageSize caps how many entries the first page of a folder listing
+	// holds (1-2000), so huge folders open sooner; the rest load as the
+	// cursor nears the end. 0 leaves it to Dropbox.
+	ListPageSize int `yaml:"list_page_size"`
+	// RequestsPerSecond caps how many Dropbox API calls dbox makes a second,
+	// across parallel listings and downloads. 0 means no limit.
+	RequestsPerSecond int `yaml:"requests_per_second"`
+	// AdaptiveRate lowers the request rate (not bandwidth) when Dropbox
+	// answers 429 Too Many Requests and ramps back up to RequestsPerSecond
+	// once requests succeed again.
+	AdaptiveRate bool `yaml:"adaptive_rate"`
+	// BinaryUnits shows sizes in KiB, MiB, ... (powers of 1024) rather than
+	// KB, MB, ... (powers of 1000).
+	BinaryUnits bool `yaml:"binary_units"`
+	// AllowArchive turns on archiving (A): downloading files, then deleting
+	// them from Dropbox once verified. It's off by default since it deletes.
+	AllowArchive bool `yaml:"allow_archive"`
+	// LogFile, if set, is where dbox logs what it's doing behind the scenes,
+	// like AdaptiveRate's adjustments.
+	LogFile string `yaml:"log_file"`
+	// ListConcurrency is how many folders a recursive scan lists at once.
+	ListConcurrency int `yaml:"list_concurrency"`
+	// MaxCacheEntries caps how many folder listings are kept while browsing;
+	// the least recently used go first. 0 means no limit.
+	MaxCacheEntries int `yaml:"max_cache_entries"`
+	// DisableCache lists every folder from Dropbox each time it's opened,
+	// instead of reusing listings from earlier in the session.
+	DisableCache bool `yaml:"disable_cache"`
+	// StatusTimeout and ErrorTimeout are how long status and error messages
+	// stay on screen, written like "3s". 0s keeps them until another message
+	// replaces them.
+	StatusTimeout time.Duration `yaml:"status_timeout"`
+	ErrorTimeout  time.Duration `yaml:"error_timeout"`
+	// ListTimeout bounds each listing or metadata call, and DownloadTimeout
+	// each call that moves file contents (downloads, exports, uploads). 0s
+	// means no timeout.
+	ListTimeout     time.Duration `yaml:"list_timeout"`
+	DownloadTimeout time.Duration `yaml:"download_timeout"`
+	// ThemeName picks a built-in theme ("dark" or "light"); Colors overrides
+	// individual colors in it. Theme is the result.
+	ThemeName string `yaml:"theme"`
+	Colors    Theme  `yaml:"colors"`
+	Theme     Theme  `yaml:"-"`
+
+	// limiter paces the Dropbox API calls of every client built from this
+	// config (see newConfig), per RequestsPerSecond and AdaptiveRate; nil
+	// means no limit.
+	limiter *rateLimiter
+	// link is set by `dbox link` so browse mode lists and downloads from the
+	// shared link instead of the account; it's nil otherwise. Paths are then
+	// relative to the link's root folder.
+	link *linkRoot
 }
 
 // LoadConfig loads configuration. Dropbox credentials are handled separately
-// (see auth.go); this just resolves filesystem settings.
+// (see auth.go); this resolves filesystem settings and applies the optional
+// settings file (see settingsPath).
 func LoadConfig() (*Config, error) {
 	dlpath, err := getDefaultDownloadPath()
 	if err != nil {
 		return nil, err
 	}
-	return &Config{DownloadPath: dlpath}, nil
+	config := &Config{
+		DownloadPath:      dlpath,
+		PaperFormat:       defaultPaperFormat,
+		SkipExisting:      skipIfSize,
+		FoldersFirst:      true,
+		BinaryUnits:       true,
+		AltScreen:         true,
+		DownloadCursor:    true,
+		MaxCacheEntries:   defaultMaxCacheEntries,
+		RequestsPerSecond: defaultRequestsPerSecond,
+		ListConcurrency:   defaultListConcurrency,
+		FileMode:          defaultFileMode,
+		DirMode:           defaultDirMode,
+		StatusTimeout:     defaultStatusTimeout,
+		ErrorTimeout:      defaultErrorTimeout,
+		ListTimeout:       defaultListTimeout,
+		ThemeName:         defaultTheme,
+		Theme:             themes[defaultTheme],
+	}
+
+	path, err := settingsPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.loadSettings(path); err != nil {
+		return nil, err
+	}
+	config.limiter = newRateLimiter(config.RequestsPerSecond)
+	if config.AdaptiveRate {
+		config.limiter = newAdaptiveRateLimiter(config.RequestsPerSecond)
+	}
+	if config.LogFile != "" {
+		logger, err := openDebugLog(config.LogFile, config.fileMode())
+		if err != nil {
+			return nil, err
+		}
+		// Only the limiter logs so far.
+		if config.limiter != nil {
+			config.limiter.log = logger
+		}
+	}
+	return config, nil
+}
+
+// fileMode and dirMode are the permissions downloaded files and the folders
+// made for them are created with (before the umask). Unset (0), as in a
+// Config not loaded from settings, they're the defaults.
+func (c *Config) fileMode() os.FileMode {
+	if c.FileMode == 0 {
+		return os.FileMode(defaultFileMode)
+	}
+	return os.FileMode(c.FileMode)
+}
+
+func (c *Config) dirMode() os.FileMode {
+	if c.DirMode == 0 {
+		return os.FileMode(defaultDirMode)
+	}
+	return os.FileMode(c.DirMode)
+}
+
+// httpClient wraps client so its requests are paced by the config's limiter
+// and time out per ListTimeout and DownloadTimeout.
+func (c *Config) httpClient(client *http.Client) *http.Client {
+	return limitRequests(limitCallTimes(client, c.ListTimeout, c.DownloadTimeout), c.limiter)
+}
+
+// settingsPath returns where the optional settings file lives:
+// $XDG_CONFIG_HOME/dbox/config.yaml, or ~/.config/dbox/config.yaml.
+func settingsPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "dbox", "config.yaml"), nil
+}
+
+// loadSettings overlays the settings file at path onto c. A missing file
+// leaves the defaults in place.
+func (c *Config) loadSettings(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read settings %q: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	// Reject unknown keys so typos surface as errors instead of being silently
+	// ignored.
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not parse settings %q: %w", path, err)
+	}
+
+	if !validPaperFormat(c.PaperFormat) {
+		return fmt.Errorf("settings: %q must be markdown or html", "paper_format")
+	}
+	if c.SkipExisting != "" && !validSkipExisting(c.SkipExisting) {
+		return fmt.Errorf("settings: %q must be exists, size, or hash", "skip_existing")
+	}
+	if c.Trash != "" {
+		c.Trash = normalizeRemotePath(c.Trash)
+		if c.Trash == "" {
+			return fmt.Errorf("settings: %q can't be the Dropbox root", "trash")
+		}
+	}
+	if c.MaxDepth != nil && *c.MaxDepth < 0 {
+		return fmt.Errorf("settings: %q must be 0 or more", "max_depth")
+	}
+	if c.ListPageSize < 0 || c.ListPageSize > maxListPageSize {
+		return fmt.Errorf("settings: %q must be between 1 and %d", "list_page_size", maxListPageSize)
+	}
+	if c.MaxCacheEntries < 0 {
+		return fmt.Errorf("settings: %q must be 0 or more", "max_cache_entries")
+	}
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("settings: %q must be 0 or more", "requests_per_second")
+	}
+	if c.ListConcurrency < 1 {
+		return fmt.Errorf("settings: %q must be at least 1", "list_concurrency")
+	}
+	if c.StatusTimeout < 0 {
+		return fmt.Errorf("settings: %q must be 0s or more", "status_timeout")
+	}
+	if c.ErrorTimeout < 0 {
+		return fmt.Errorf("settings: %q must be 0s or more", "error_timeout")
+	}
+	if c.ListTimeout < 0 {
+		return fmt.Errorf("settings: %q must be 0s or more", "list_timeout")
+	}
+	if c.DownloadTimeout < 0 {
+		return fmt.Errorf("settings: %q must be 0s or more", "download_timeout")
+	}
+	if _, err := newKeyMap(c.Keys); err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+	theme, err := resolveTheme(c.ThemeName, c.Colors)
+	if err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+	c.Theme = theme
+	return nil
 }
 
 // getDefaultDownloadPath returns the default download path
@@ -31,5 +291,5 @@ func getDefaultDownloadPath() (string, error) {
 
 // EnsureDownloadPath creates the download directory if it doesn't exist
 func (c *Config) EnsureDownloadPath() error {
-	return os.MkdirAll(c.DownloadPath, 0755)
+	return os.MkdirAll(c.DownloadPath, c.dirMode())
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadSettings(t *testing.T) {
+	defaults := func() *Config {
+		return &Config{
+			DownloadPath:    "/dl",
+			PaperFormat:     defaultPaperFormat,
+			ListConcurrency: defaultListConcurrency,
+			ThemeName:       defaultTheme,
+			Theme:           themes[defaultTheme],
+		}
+	}
+	write := func(t *testing.T, body string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("write settings: %v", err)
+		}
+		return path
+	}
+
+	t.Run("missing file keeps defaults", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(filepath.Join(t.TempDir(), "nope.yaml")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(c, defaults()) {
+			t.Errorf("config = %+v, want defaults", *c)
+		}
+	})
+
+	t.Run("empty file keeps defaults", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(c, defaults()) {
+			t.Errorf("config = %+v, want defaults", *c)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "refresh_on_focus: true\npaper_format: html\norganize_by_extension: true\nwrap_cursor: true\nopen_after_download: true\ndisable_cache: true\nadaptive_rate: true\nallow_archive: true\nconfirm_leave_selection: true\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !c.RefreshOnFocus || c.PaperFormat != "html" || !c.OrganizeByExtension || !c.WrapCursor || !c.OpenAfterDownload || !c.DisableCache || !c.AdaptiveRate || !c.AllowArchive || !c.ConfirmLeaveSelection || c.DownloadPath != "/dl" {
+			t.Errorf("config = %+v", *c)
+		}
+	})
+
+	t.Run("theme with color overrides", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "theme: light\ncolors:\n  cursor: \"#ff8800\"\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := themes["light"]
+		want.Cursor = "#ff8800"
+		if c.Theme != want {
+			t.Errorf("theme = %+v, want %+v", c.Theme, want)
+		}
+	})
+
+	t.Run("unknown theme", func(t *testing.T) {
+		if err := defaults().loadSettings(write(t, "theme: solarized\n")); err == nil {
+			t.Error("expected an error for an unknown theme")
+		}
+	})
+
+	t.Run("unknown color", func(t *testing.T) {
+		if err := defaults().loadSettings(write(t, "colors:\n  cursr: \"1\"\n")); err == nil {
+			t.Error("expected an error for a misspelled color name")
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		if err := defaults().loadSettings(write(t, "refresh_on_focuss: true\n")); err == nil {
+			t.Error("expected an error for a misspelled key")
+		}
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "trash: .dbox-trash/\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Trash != "/.dbox-trash" {
+			t.Errorf("trash = %q, want /.dbox-trash", c.Trash)
+		}
+		if err := defaults().loadSettings(write(t, "trash: /\n")); err == nil {
+			t.Error("expected an error for the root as trash")
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "max_depth: 0\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.MaxDepth == nil || *c.MaxDepth != 0 {
+			t.Errorf("max depth = %v, want 0", c.MaxDepth)
+		}
+		if err := defaults().loadSettings(write(t, "max_depth: -1\n")); err == nil {
+			t.Error("expected an error for a negative max_depth")
+		}
+	})
+
+	t.Run("max cache entries", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "max_cache_entries: 50\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.MaxCacheEntries != 50 {
+			t.Errorf("max cache entries = %d, want 50", c.MaxCacheEntries)
+		}
+		if err := defaults().loadSettings(write(t, "max_cache_entries: -1\n")); err == nil {
+			t.Error("expected an error for a negative max_cache_entries")
+		}
+	})
+
+	t.Run("requests per second", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "requests_per_second: 3\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.RequestsPerSecond != 3 {
+			t.Errorf("requests per second = %d, want 3", c.RequestsPerSecond)
+		}
+		if err := defaults().loadSettings(write(t, "requests_per_second: -1\n")); err == nil {
+			t.Error("expected an error for a negative requests_per_second")
+		}
+	})
+
+	t.Run("binary units", func(t *testing.T) {
+		c := defaults()
+		c.BinaryUnits = true
+		if err := c.loadSettings(write(t, "binary_units: false\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.BinaryUnits {
+			t.Error("binary_units: false should switch to decimal units")
+		}
+	})
+
+	t.Run("list concurrency", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "list_concurrency: 16\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.ListConcurrency != 16 {
+			t.Errorf("list concurrency = %d, want 16", c.ListConcurrency)
+		}
+		if err := defaults().loadSettings(write(t, "list_concurrency: 0\n")); err == nil {
+			t.Error("expected an error for a list_concurrency of 0")
+		}
+	})
+
+	t.Run("message timeouts", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "status_timeout: 10s\nerror_timeout: 0s\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.StatusTimeout != 10*time.Second || c.ErrorTimeout != 0 {
+			t.Errorf("timeouts = %v, %v, want 10s and 0", c.StatusTimeout, c.ErrorTimeout)
+		}
+		if err := defaults().loadSettings(write(t, "error_timeout: 5\n")); err == nil {
+			t.Error("expected an error for a timeout without a unit")
+		}
+		if err := defaults().loadSettings(write(t, "status_timeout: -1s\n")); err == nil {
+			t.Error("expected an error for a negative timeout")
+		}
+	})
+
+	t.Run("call timeouts", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "list_timeout: 10s\ndownload_timeout: 1h\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.ListTimeout != 10*time.Second || c.DownloadTimeout != time.Hour {
+			t.Errorf("timeouts = %v, %v, want 10s and 1h", c.ListTimeout, c.DownloadTimeout)
+		}
+		if err := defaults().loadSettings(write(t, "list_timeout: -1s\n")); err == nil {
+			t.Error("expected an error for a negative timeout")
+		}
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "file_mode: 0600\ndir_mode: \"700\"\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.FileMode != 0600 || c.DirMode != 0700 {
+			t.Errorf("modes = %o, %o, want 600 and 700", c.FileMode, c.DirMode)
+		}
+		for _, bad := range []string{"file_mode: 0689\n", "dir_mode: 01777\n", "file_mode: rw\n"} {
+			if err := defaults().loadSettings(write(t, bad)); err == nil {
+				t.Errorf("expected an error for %q", bad)
+			}
+		}
+	})
+
+	t.Run("list page size", func(t *testing.T) {
+		c := defaults()
+		if err := c.loadSettings(write(t, "list_page_size: 100\n")); err != nil || c.ListPageSize != 100 {
+			t.Errorf("list page size = %d (%v), want 100", c.ListPageSize, err)
+		}
+		for _, bad := range []string{"-1", "2001"} {
+			if err := defaults().loadSettings(write(t, "list_page_size: "+bad+"\n")); err == nil {
+				t.Errorf("expected an error for list_page_size %s", bad)
+			}
+		}
+	})
+
+	t.Run("bad skip existing", func(t *testing.T) {
+		if err := defaults().loadSettings(write(t, "skip_existing: newer\n")); err == nil {
+			t.Error("expected an error for an unsupported skip_existing")
+		}
+	})
+
+	t.Run("bad paper format", func(t *testing.T) {
+		if err := defaults().loadSettings(write(t, "paper_format: pdf\n")); err == nil {
+			t.Error("expected an error for an unsupported paper_format")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -91,14 +92,15 @@ func (c *DboxConfig) normalizeAndValidate() error {
 }
 
 // normalizeRemotePath ensures a Dropbox path has a single leading slash and no
-// trailing slash. An empty (or slash-only) path normalizes to "".
+// trailing slash, with repeated slashes and "." or ".." segments resolved. The
+// root normalizes to "", the only form the API accepts for it (it rejects
+// "/"), so every path sent to Dropbox should pass through here.
 func normalizeRemotePath(p string) string {
-	p = strings.TrimSpace(p)
-	p = strings.Trim(p, "/")
-	if p == "" {
+	p = path.Clean("/" + strings.TrimSpace(p))
+	if p == "/" {
 		return ""
 	}
-	return "/" + p
+	return p
 }
 
 // matchesFileType reports whether name has one of the configured extensions.
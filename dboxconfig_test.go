@@ -56,3 +56,38 @@ func TestLoadDboxConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestNormalizeRemotePath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"/", ""},
+		{"//", ""},
+		{" / ", ""},
+		{".", ""},
+		{"/..", ""},
+		{"photos", "/photos"},
+		{"/photos/", "/photos"},
+		{"//photos//2024/", "/photos/2024"},
+		{"/photos/./2024/../2023", "/photos/2023"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRemotePath(tt.in); got != tt.want {
+			t.Errorf("normalizeRemotePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParentPath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"/photos", ""},
+		{"/photos/2024", "/photos"},
+		{"/photos/2024/", "/photos"},
+		{"/", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := parentPath(tt.in); got != tt.want {
+			t.Errorf("parentPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
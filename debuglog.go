@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// openDebugLog opens the log_file setting's log, where dbox notes what it's
+// doing behind the scenes, like adaptive_rate's adjustments. Lines are
+// appended to what's there; a new file is created with mode.
+func openDebugLog(path string, mode os.FileMode) (*log.Logger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, mode)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file %q: %w", path, err)
+	}
+	return log.New(f, "", log.LstdFlags), nil
+}
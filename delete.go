@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/async"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// batchPollInterval is how long to wait between checks on a running Dropbox
+// batch job.
+const batchPollInterval = time.Second
+
+// deleteJobMsg reports that a batch delete is still running on Dropbox's side;
+// polls counts the status checks made so far.
+type deleteJobMsg struct {
+	jobID string
+	items []FileItem
+	polls int
+}
+
+// DeleteCompleteMsg reports the outcome of a batch delete, entry by entry.
+// Revs holds the last revision of each deleted file by path, so the delete
+// can be undone.
+type DeleteCompleteMsg struct {
+	Deleted []FileItem
+	Errors  []ItemError
+	Revs    map[string]string
+}
+
+// confirmDelete asks before deleting the selected entries.
+func (m Model) confirmDelete(items []FileItem) (tea.Model, tea.Cmd) {
+	if m.job != "" {
+		m.error = "Wait for the current " + m.job + " to finish"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	if m.mixesTrash(items) {
+		m.error = "Some of the selection is in the trash and some isn't; delete them separately"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.pendingDelete = items
+	m.openPrompt(promptConfirmDelete)
+	return m, nil
+}
+
+// answerDelete handles y/n at the delete confirmation prompt. Other keys leave
+// the prompt open.
+func (m Model) answerDelete(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		items := m.pendingDelete
+		m.pendingDelete = nil
+		m.closePrompt()
+		m.deselect(items)
+		if m.trashes(items) {
+			return m.moveToTrash(items)
+		}
+		m.job = "delete"
+		m.status = fmt.Sprintf("Deleting %s...", pluralize(len(items), "item"))
+		m.statusTime = time.Now()
+		return m, deleteBatchCmd(&m.config, items)
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
+
+// deleteBatchCmd starts a Dropbox batch delete for items. Small batches may
+// finish immediately; otherwise the job is polled with deleteCheckCmd.
+func deleteBatchCmd(config *Config, items []FileItem) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return deleteFailed(items, err)
+		}
+		entries := make([]*files.DeleteArg, len(items))
+		for i, item := range items {
+			entries[i] = files.NewDeleteArg(item.Path)
+		}
+		launch, err := dbx.DeleteBatch(files.NewDeleteBatchArg(entries))
+		if err != nil {
+			return deleteFailed(items, err)
+		}
+		switch launch.Tag {
+		case files.DeleteBatchLaunchComplete:
+			return deleteOutcome(items, launch.Complete)
+		case files.DeleteBatchLaunchAsyncJobId:
+			return deleteJobMsg{jobID: launch.AsyncJobId, items: items}
+		default:
+			return deleteFailed(items, fmt.Errorf("unexpected response %q", launch.Tag))
+		}
+	}
+}
+
+// deleteCheckCmd waits batchPollInterval, then checks on a running batch
+// delete.
+func deleteCheckCmd(config *Config, job deleteJobMsg) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(batchPollInterval)
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return deleteFailed(job.items, err)
+		}
+		return checkDeleteJob(dbx, job)
+	}
+}
+
+// checkDeleteJob polls a batch delete once, returning the next deleteJobMsg
+// while it's running or a DeleteCompleteMsg once it has finished.
+func checkDeleteJob(dbx files.Client, job deleteJobMsg) tea.Msg {
+	status, err := dbx.DeleteBatchCheck(async.NewPollArg(job.jobID))
+	if err != nil {
+		return deleteFailed(job.items, err)
+	}
+	switch status.Tag {
+	case files.DeleteBatchJobStatusInProgress:
+		job.polls++
+		return job
+	case files.DeleteBatchJobStatusComplete:
+		return deleteOutcome(job.items, status.Complete)
+	case files.DeleteBatchJobStatusFailed:
+		return deleteFailed(job.items, fmt.Errorf("%s", status.Failed.Tag))
+	default:
+		return deleteFailed(job.items, fmt.Errorf("unexpected status %q", status.Tag))
+	}
+}
+
+// deleteOutcome pairs each requested item with its entry in the batch result,
+// which Dropbox returns in the same order as the request.
+func deleteOutcome(items []FileItem, result *files.DeleteBatchResult) DeleteCompleteMsg {
+	msg := DeleteCompleteMsg{Revs: make(map[string]string)}
+	for i, item := range items {
+		if i >= len(result.Entries) {
+			msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: no result returned", item.Name)})
+			continue
+		}
+		entry := result.Entries[i]
+		if entry.Tag == files.DeleteBatchResultEntrySuccess {
+			msg.Deleted = append(msg.Deleted, item)
+			if entry.Success != nil {
+				if file, ok := entry.Success.Metadata.(*files.FileMetadata); ok {
+					msg.Revs[item.Path] = file.Rev
+				}
+			}
+			continue
+		}
+		msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: %s", item.Name, describeDeleteError(entry.Failure))})
+	}
+	return msg
+}
+
+// deleteFailed reports every item as failed when the whole batch did.
+func deleteFailed(items []FileItem, err error) DeleteCompleteMsg {
+	var msg DeleteCompleteMsg
+	for _, item := range items {
+		msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: %v", item.Name, err)})
+	}
+	return msg
+}
+
+// describeDeleteError renders a per-entry delete failure such as
+// "path_lookup/not_found".
+func describeDeleteError(e *files.DeleteError) string {
+	switch {
+	case e == nil:
+		return "unknown error"
+	case e.PathLookup != nil:
+		return e.Tag + "/" + e.PathLookup.Tag
+	case e.PathWrite != nil:
+		return e.Tag + "/" + e.PathWrite.Tag
+	default:
+		return e.Tag
+	}
+}
+
+// invalidatePaths drops cached listings and previews that items affect: the
+// folders they were in and, for folders, their own cached contents and
+// everything below. Measured folder sizes that include them go too.
+func (m *Model) invalidatePaths(items []FileItem) {
+	for _, item := range items {
+		m.forgetFolder(parentPath(item.Path))
+		for cached := range m.folderCache {
+			if cached == item.Path || withinRemote(cached, item.Path) {
+				delete(m.folderCache, cached)
+			}
+		}
+		m.forgetPreviews(item.Path)
+		for sized := range m.folderSizes {
+			if sized == item.Path || withinRemote(item.Path, sized) || withinRemote(sized, item.Path) {
+				delete(m.folderSizes, sized)
+			}
+		}
+	}
+}
+
+// itemsOf returns the items that errors refer to.
+func itemsOf(errors []ItemError) []FileItem {
+	items := make([]FileItem, len(errors))
+	for i, e := range errors {
+		items[i] = e.Item
+	}
+	return items
+}
+
+// pluralize formats a count with a noun, e.g. "1 item" or "3 items".
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
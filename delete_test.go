@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/async"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeDeleteClient answers DeleteBatchCheck with each status in turn.
+type fakeDeleteClient struct {
+	files.Client
+	statuses []*files.DeleteBatchJobStatus
+}
+
+func (f *fakeDeleteClient) DeleteBatchCheck(arg *async.PollArg) (*files.DeleteBatchJobStatus, error) {
+	status := f.statuses[0]
+	f.statuses = f.statuses[1:]
+	return status, nil
+}
+
+func TestCheckDeleteJob(t *testing.T) {
+	items := []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	dbx := &fakeDeleteClient{statuses: []*files.DeleteBatchJobStatus{
+		{Tagged: dropbox.Tagged{Tag: files.DeleteBatchJobStatusInProgress}},
+		{
+			Tagged: dropbox.Tagged{Tag: files.DeleteBatchJobStatusComplete},
+			Complete: &files.DeleteBatchResult{Entries: []*files.DeleteBatchResultEntry{
+				{Tagged: dropbox.Tagged{Tag: files.DeleteBatchResultEntrySuccess}},
+				{
+					Tagged: dropbox.Tagged{Tag: files.DeleteBatchResultEntryFailure},
+					Failure: &files.DeleteError{
+						Tagged:     dropbox.Tagged{Tag: files.DeleteErrorPathLookup},
+						PathLookup: &files.LookupError{Tagged: dropbox.Tagged{Tag: files.LookupErrorNotFound}},
+					},
+				},
+			}},
+		},
+	}}
+
+	job := deleteJobMsg{jobID: "job", items: items}
+	next, ok := checkDeleteJob(dbx, job).(deleteJobMsg)
+	if !ok || next.polls != 1 {
+		t.Fatalf("in-progress job should be polled again, got %#v", next)
+	}
+
+	done, ok := checkDeleteJob(dbx, next).(DeleteCompleteMsg)
+	if !ok {
+		t.Fatal("completed job should produce a DeleteCompleteMsg")
+	}
+	if !reflect.DeepEqual(done.Deleted, items[:1]) {
+		t.Errorf("deleted = %v, want [a]", done.Deleted)
+	}
+	if len(done.Errors) != 1 || done.Errors[0].Err != "b: path_lookup/not_found" {
+		t.Errorf("errors = %v, want b: path_lookup/not_found", done.Errors)
+	}
+}
+
+func TestInvalidatePaths(t *testing.T) {
+	m := initialModel(&Config{})
+	for _, p := range []string{"", "/docs", "/docs/old", "/docs/old/deep", "/docs/older", "/photos"} {
+		m.folderCache[p] = nil
+	}
+
+	m.invalidatePaths([]FileItem{{Path: "/docs/old", IsFolder: true}})
+
+	var kept []string
+	for _, p := range []string{"", "/docs", "/docs/old", "/docs/old/deep", "/docs/older", "/photos"} {
+		if _, ok := m.folderCache[p]; ok {
+			kept = append(kept, p)
+		}
+	}
+	want := []string{"", "/docs/older", "/photos"}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("cache kept %v, want %v", kept, want)
+	}
+}
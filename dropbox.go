@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
@@ -11,20 +14,64 @@ import (
 
 // newConfig builds the SDK config from the credentials in the environment. It
 // returns an auto-refreshing HTTP client (built from the refresh token + app
-// key/secret), so access tokens are minted and renewed transparently.
-func newConfig() (dropbox.Config, error) {
+// key/secret), so access tokens are minted and renewed transparently. Its
+// requests are paced and time out per config (see Config.httpClient).
+func newConfig(config *Config) (dropbox.Config, error) {
 	appKey, appSecret, refreshToken, err := credentials()
 	if err != nil {
 		return dropbox.Config{}, err
 	}
+	member, admin, err := teamSelection()
+	if err != nil {
+		return dropbox.Config{}, err
+	}
 	cfg := oauthConfig(appKey, appSecret)
 	client := cfg.Client(context.Background(), &oauth2.Token{RefreshToken: refreshToken})
-	return dropbox.Config{Client: client}, nil
+	return dropbox.Config{Client: config.httpClient(client), AsMemberID: member, AsAdminID: admin}, nil
 }
 
-// newFilesClient builds a Dropbox files client from stored credentials.
-func newFilesClient() (files.Client, error) {
-	cfg, err := newConfig()
+// teamSelection reads which team member to act as when the credentials are
+// for a team app: DROPBOX_TEAM_MEMBER_ID acts as that member, and
+// DROPBOX_TEAM_ADMIN_ID as that admin (who can also see team folders). Both
+// are empty for a personal app.
+func teamSelection() (member, admin string, err error) {
+	member, _ = cleanCredential(os.Getenv(envTeamMemberID))
+	admin, _ = cleanCredential(os.Getenv(envTeamAdminID))
+	if member != "" && admin != "" {
+		return "", "", fmt.Errorf("set only one of %s and %s", envTeamMemberID, envTeamAdminID)
+	}
+	return member, admin, nil
+}
+
+// explainTeamError replaces the error Dropbox gives when a team token is used
+// without picking a member to act as with one that says how to pick one.
+// Other errors are returned as is.
+func explainTeamError(err error) error {
+	if err == nil || !isTeamTokenError(err) {
+		return err
+	}
+	member, admin, _ := teamSelection()
+	if member != "" || admin != "" {
+		return err
+	}
+	return fmt.Errorf("these credentials are for a Dropbox team: export %s='dbmid:...' to act as a team member, or %s to act as an admin",
+		envTeamMemberID, envTeamAdminID)
+}
+
+// isTeamTokenError reports whether err is Dropbox refusing a user call made
+// with a team token: it asks for a Dropbox-API-Select-User header.
+func isTeamTokenError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Dropbox-API-Select-User") || strings.Contains(msg, "entire Dropbox Business team")
+}
+
+// newFilesClient builds a Dropbox files client from stored credentials. While
+// browsing a shared link (see Config.link) it serves the link instead.
+func newFilesClient(config *Config) (files.Client, error) {
+	if config.link != nil {
+		return newSharedLinkClient(config.link, config)
+	}
+	cfg, err := newConfig(config)
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +79,8 @@ func newFilesClient() (files.Client, error) {
 }
 
 // newSharingClient builds a Dropbox sharing client from stored credentials.
-func newSharingClient() (sharing.Client, error) {
-	cfg, err := newConfig()
+func newSharingClient(config *Config) (sharing.Client, error) {
+	cfg, err := newConfig(config)
 	if err != nil {
 		return nil, err
 	}
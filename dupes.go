@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// duplicateGroup is a set of files with the same contents.
+type duplicateGroup struct {
+	ContentHash string   `json:"content_hash"`
+	Size        int64    `json:"size"`   // of each copy
+	Wasted      int64    `json:"wasted"` // bytes taken by all but one copy
+	Paths       []string `json:"paths"`
+}
+
+// runDupes implements `dbox dupes [--json] [--output file] [path]`: it lists
+// a folder tree and reports the files in it that share their contents.
+func runDupes(args []string) error {
+	fs := flag.NewFlagSet("dupes", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the groups as JSON instead of text")
+	output := fs.String("output", "", "write the report to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox dupes [--json] [--output file] [path]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one path")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return err
+	}
+	root, err := lookupFileItem(dbx, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", fs.Arg(0), explainTeamError(err))
+	}
+	if !root.IsFolder {
+		return fmt.Errorf("%s is not a folder", root.displayPath())
+	}
+	items, _, denied, err := getFilesToDepth(dbx, root.Path, -1, config.ListConcurrency, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", root.displayPath(), explainTeamError(err))
+	}
+	for _, e := range denied {
+		fmt.Fprintf(os.Stderr, "Skipped %s: %s\n", e.Item.displayPath(), e.Err)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		out, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+	groups := findDuplicates(items)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(groups)
+	}
+	return writeDuplicates(w, groups, config.BinaryUnits)
+}
+
+// findDuplicates groups files by content hash and returns the groups with
+// more than one file, the most wasted space first. Folders, Paper docs (which
+// have no content hash), and empty files are left out.
+func findDuplicates(items []FileItem) []duplicateGroup {
+	byHash := map[string]*duplicateGroup{}
+	var hashes []string
+	for _, item := range items {
+		if item.IsFolder || item.ContentHash == "" || item.Size == 0 {
+			continue
+		}
+		g, ok := byHash[item.ContentHash]
+		if !ok {
+			g = &duplicateGroup{ContentHash: item.ContentHash, Size: item.Size}
+			byHash[item.ContentHash] = g
+			hashes = append(hashes, item.ContentHash)
+		}
+		g.Paths = append(g.Paths, item.displayPath())
+	}
+
+	groups := []duplicateGroup{}
+	for _, hash := range hashes {
+		g := byHash[hash]
+		if len(g.Paths) < 2 {
+			continue
+		}
+		sort.Strings(g.Paths)
+		g.Wasted = g.Size * int64(len(g.Paths)-1)
+		groups = append(groups, *g)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Wasted != groups[j].Wasted {
+			return groups[i].Wasted > groups[j].Wasted
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups
+}
+
+// writeDuplicates prints each group's copies under a line giving their size,
+// then the total space the extra copies take. Sizes are in binary units when
+// binary is set.
+func writeDuplicates(w io.Writer, groups []duplicateGroup, binary bool) error {
+	if len(groups) == 0 {
+		_, err := fmt.Fprintln(w, "No duplicate files found")
+		return err
+	}
+	var wasted int64
+	for _, g := range groups {
+		fmt.Fprintf(w, "%d copies of %s (%s wasted)\n", len(g.Paths), formatSize(g.Size, binary), formatSize(g.Wasted, binary))
+		for _, p := range g.Paths {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+		fmt.Fprintln(w)
+		wasted += g.Wasted
+	}
+	_, err := fmt.Fprintf(w, "%s of duplicates, %s wasted\n", pluralize(len(groups), "group"), formatSize(wasted, binary))
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	items := []FileItem{
+		{Path: "/a/x.jpg", DisplayPath: "/a/X.jpg", Size: 100, ContentHash: "h1"},
+		{Path: "/b/x.jpg", DisplayPath: "/b/X.jpg", Size: 100, ContentHash: "h1"},
+		{Path: "/c/big.mov", DisplayPath: "/c/big.mov", Size: 5000, ContentHash: "h2"},
+		{Path: "/a/big.mov", DisplayPath: "/a/big.mov", Size: 5000, ContentHash: "h2"},
+		{Path: "/a/unique.txt", DisplayPath: "/a/unique.txt", Size: 10, ContentHash: "h3"},
+		{Path: "/a/empty", DisplayPath: "/a/empty", Size: 0, ContentHash: "h0"},
+		{Path: "/b/empty", DisplayPath: "/b/empty", Size: 0, ContentHash: "h0"},
+		{Path: "/a/doc.paper", DisplayPath: "/a/doc.paper", Exportable: true},
+		{Path: "/b/doc.paper", DisplayPath: "/b/doc.paper", Exportable: true},
+		{Path: "/a", DisplayPath: "/a", IsFolder: true},
+	}
+	want := []duplicateGroup{
+		{ContentHash: "h2", Size: 5000, Wasted: 5000, Paths: []string{"/a/big.mov", "/c/big.mov"}},
+		{ContentHash: "h1", Size: 100, Wasted: 100, Paths: []string{"/a/X.jpg", "/b/X.jpg"}},
+	}
+	if got := findDuplicates(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteDuplicates(t *testing.T) {
+	var out bytes.Buffer
+	writeDuplicates(&out, []duplicateGroup{
+		{Size: 2048, Wasted: 4096, Paths: []string{"/a", "/b", "/c"}},
+	}, true)
+	want := "3 copies of 2.0 KiB (4.0 KiB wasted)\n  /a\n  /b\n  /c\n\n1 group of duplicates, 4.0 KiB wasted\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeDuplicates(&out, nil, true)
+	if out.String() != "No duplicate files found\n" {
+		t.Errorf("empty report: %q", out.String())
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// maxCopyProbes bounds how many "(copy N)" names are tried before giving up.
+const maxCopyProbes = 100
+
+// DuplicateCompleteMsg reports the copies made of the selected entries, by
+// their new paths, and any that failed.
+type DuplicateCompleteMsg struct {
+	Copies []string
+	Errors []ItemError
+}
+
+// duplicateCmd copies each item alongside itself under a free "(copy)" name.
+func duplicateCmd(config *Config, items []FileItem) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		var msg DuplicateCompleteMsg
+		for _, item := range items {
+			dest, err := duplicateItem(dbx, item)
+			if err != nil {
+				msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: %v", item.Name, err)})
+				continue
+			}
+			msg.Copies = append(msg.Copies, dest)
+		}
+		return msg
+	}
+}
+
+// duplicateItem copies item into its own folder, trying "name (copy).ext",
+// then "name (copy 2).ext" and so on until Dropbox accepts a name that isn't
+// taken. It returns the path of the copy.
+func duplicateItem(dbx files.Client, item FileItem) (string, error) {
+	dir := parentPath(item.Path)
+	for n := 1; n <= maxCopyProbes; n++ {
+		dest := path.Join("/", dir, copyName(item.Name, item.IsFolder, n))
+		_, err := dbx.CopyV2(files.NewRelocationArg(item.Path, dest))
+		if err == nil {
+			return dest, nil
+		}
+		if !isCopyConflict(err) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free name after %d tries", maxCopyProbes)
+}
+
+// copyName names the nth copy of name: "report (copy).pdf", then
+// "report (copy 2).pdf". Folders and dotfiles keep the whole name as the stem.
+func copyName(name string, isFolder bool, n int) string {
+	stem, ext := name, ""
+	if !isFolder {
+		if e := path.Ext(name); e != "" && e != name {
+			stem, ext = strings.TrimSuffix(name, e), e
+		}
+	}
+	if n == 1 {
+		return fmt.Sprintf("%s (copy)%s", stem, ext)
+	}
+	return fmt.Sprintf("%s (copy %d)%s", stem, n, ext)
+}
+
+// isCopyConflict reports whether a CopyV2 failed because the destination
+// name is already taken.
+func isCopyConflict(err error) bool {
+	apiErr, ok := err.(files.CopyV2APIError)
+	return ok &&
+		apiErr.EndpointError != nil &&
+		apiErr.EndpointError.To != nil &&
+		apiErr.EndpointError.To.Tag == files.WriteErrorConflict
+}
+
+// handleDuplicateComplete reports the copies made and reloads the folder so
+// they appear.
+func (m Model) handleDuplicateComplete(msg DuplicateCompleteMsg) (tea.Model, tea.Cmd) {
+	m.forgetFolder(m.currentPath)
+	if op := duplicateUndo(msg); op != nil {
+		m.lastOp = op
+	}
+	if len(msg.Copies) > 0 {
+		names := make([]string, len(msg.Copies))
+		for i, p := range msg.Copies {
+			names[i] = path.Base(p)
+		}
+		m.status = "Created " + strings.Join(names, ", ")
+		m.statusTime = time.Now()
+	}
+	if len(msg.Errors) > 0 {
+		var errs []string
+		for _, e := range msg.Errors {
+			errs = append(errs, e.Err)
+		}
+		m.error = fmt.Sprintf("Failed to duplicate %d: %s", len(msg.Errors), strings.Join(errs, ", "))
+		m.errorTime = time.Now()
+	}
+	return m, m.load(m.currentPath)
+}
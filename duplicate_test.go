@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeCopyClient fails CopyV2 with a conflict for any destination in taken.
+type fakeCopyClient struct {
+	files.Client
+	taken  map[string]bool
+	copies []string
+}
+
+func (f *fakeCopyClient) CopyV2(arg *files.RelocationArg) (*files.RelocationResult, error) {
+	if f.taken[arg.ToPath] {
+		return nil, files.CopyV2APIError{EndpointError: &files.RelocationError{
+			Tagged: dropbox.Tagged{Tag: files.RelocationErrorTo},
+			To:     &files.WriteError{Tagged: dropbox.Tagged{Tag: files.WriteErrorConflict}},
+		}}
+	}
+	f.copies = append(f.copies, arg.ToPath)
+	return &files.RelocationResult{}, nil
+}
+
+func TestCopyName(t *testing.T) {
+	tests := []struct {
+		name     string
+		isFolder bool
+		n        int
+		want     string
+	}{
+		{"report.pdf", false, 1, "report (copy).pdf"},
+		{"report.pdf", false, 3, "report (copy 3).pdf"},
+		{"archive.tar.gz", false, 1, "archive.tar (copy).gz"},
+		{".bashrc", false, 1, ".bashrc (copy)"},
+		{"README", false, 2, "README (copy 2)"},
+		{"v1.2", true, 1, "v1.2 (copy)"},
+	}
+	for _, tt := range tests {
+		if got := copyName(tt.name, tt.isFolder, tt.n); got != tt.want {
+			t.Errorf("copyName(%q, %v, %d) = %q, want %q", tt.name, tt.isFolder, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateItemProbesFreeName(t *testing.T) {
+	dbx := &fakeCopyClient{taken: map[string]bool{
+		"/docs/a (copy).txt":   true,
+		"/docs/a (copy 2).txt": true,
+	}}
+	dest, err := duplicateItem(dbx, FileItem{Name: "a.txt", Path: "/docs/a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != "/docs/a (copy 3).txt" {
+		t.Errorf("copied to %s, want /docs/a (copy 3).txt", dest)
+	}
+
+	// At the root the copy stays at the root.
+	dest, err = duplicateItem(dbx, FileItem{Name: "b.txt", Path: "/b.txt"})
+	if err != nil || dest != "/b (copy).txt" {
+		t.Errorf("copied to %s (%v), want /b (copy).txt", dest, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// folderSizing is a folder size calculation in progress. The scan adds to
+// the counts from its own goroutines; the UI reads them to show progress.
+type folderSizing struct {
+	item    FileItem
+	folders atomic.Int64
+	files   atomic.Int64
+}
+
+// scanned counts one listed folder holding files files.
+func (s *folderSizing) scanned(files int) {
+	s.folders.Add(1)
+	s.files.Add(int64(files))
+}
+
+// FolderSizeMsg carries the total size of the files in a folder, however
+// deeply nested.
+type FolderSizeMsg struct {
+	Path  string
+	Size  int64
+	Files int
+	Err   error
+}
+
+// folderSizeCmd adds up the sizes of everything inside the folder being
+// sized.
+func folderSizeCmd(config *Config, sizing *folderSizing) tea.Cmd {
+	return func() tea.Msg {
+		path := sizing.item.Path
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return FolderSizeMsg{Path: path, Err: err}
+		}
+		items, _, denied, err := getFilesToDepth(dbx, path, -1, config.ListConcurrency, sizing.scanned)
+		if err == nil && len(denied) > 0 {
+			// A total missing whole folders would be misleading.
+			err = errors.New(denied[0].Err)
+		}
+		if err != nil {
+			return FolderSizeMsg{Path: path, Err: err}
+		}
+		msg := FolderSizeMsg{Path: path}
+		for _, item := range items {
+			if !item.IsFolder {
+				msg.Size += item.Size
+				msg.Files++
+			}
+		}
+		return msg
+	}
+}
+
+// sizeFolder works out how big the folder under the cursor is, or reports
+// the size of a file. Sizes already worked out are shown straight away.
+func (m Model) sizeFolder() (tea.Model, tea.Cmd) {
+	if m.cursor >= len(m.visible) {
+		return m, nil
+	}
+	item := m.visible[m.cursor]
+	switch size, known := m.folderSizes[item.Path]; {
+	case !item.IsFolder:
+		m.status = fmt.Sprintf("%s: %s", item.Name, formatSize(item.Size, m.config.BinaryUnits))
+	case m.sizing != nil:
+		m.status = "Still measuring " + m.sizing.item.Name
+	case known:
+		m.status = fmt.Sprintf("%s: %s (measured earlier; %s clears it)", item.Name, formatSize(size, m.config.BinaryUnits), m.keys.describe(actionClearCache))
+	default:
+		m.sizing = &folderSizing{item: item}
+		return m, tea.Batch(folderSizeCmd(&m.config, m.sizing), m.startSpinner())
+	}
+	m.statusTime = time.Now()
+	return m, nil
+}
+
+// handleFolderSize reports a folder's size and remembers it.
+func (m Model) handleFolderSize(msg FolderSizeMsg) (tea.Model, tea.Cmd) {
+	if m.sizing == nil || m.sizing.item.Path != msg.Path {
+		return m, nil
+	}
+	name := m.sizing.item.Name
+	m.sizing = nil
+	if msg.Err != nil {
+		m.error = fmt.Sprintf("Failed to measure %s: %v", name, msg.Err)
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	if m.folderSizes == nil {
+		m.folderSizes = make(map[string]int64)
+	}
+	m.folderSizes[msg.Path] = msg.Size
+	m.status = fmt.Sprintf("%s: %s in %s", name, formatSize(msg.Size, m.config.BinaryUnits), pluralize(msg.Files, "file"))
+	m.statusTime = time.Now()
+	return m, nil
+}
+
+// sizingLine describes the folder size calculation in progress.
+func (m Model) sizingLine() string {
+	return fmt.Sprintf("%s Measuring %s: %s folders, %s files so far", spinnerFrames[m.spinnerFrame],
+		m.sizing.item.Name, groupDigits(m.sizing.folders.Load()), groupDigits(m.sizing.files.Load()))
+}
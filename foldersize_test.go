@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFolderSize(t *testing.T) {
+	m := initialModel(&Config{BinaryUnits: true})
+	m.setFiles("", []FileItem{
+		{Name: "docs", Path: "/docs", IsFolder: true},
+		{Name: "z.txt", Path: "/z.txt", Size: 2048},
+	})
+
+	next, cmd := m.sizeFolder()
+	m = next.(Model)
+	if m.sizing == nil || cmd == nil {
+		t.Fatal("measuring a folder should start a scan")
+	}
+	m.sizing.scanned(3)
+	if line := m.sizingLine(); !strings.Contains(line, "Measuring docs: 1 folders, 3 files so far") {
+		t.Errorf("progress = %q", line)
+	}
+
+	next, _ = m.handleFolderSize(FolderSizeMsg{Path: "/docs", Size: 3 * 1024 * 1024, Files: 3})
+	m = next.(Model)
+	if m.sizing != nil || m.folderSizes["/docs"] != 3*1024*1024 || !strings.Contains(m.status, "3 files") {
+		t.Fatalf("after measuring: sizing = %v, sizes = %v, status = %q", m.sizing, m.folderSizes, m.status)
+	}
+
+	// The size is remembered, so asking again needs no scan.
+	if next, cmd = m.sizeFolder(); cmd != nil || !strings.Contains(next.(Model).status, "measured earlier") {
+		t.Errorf("second measurement: cmd = %v, status = %q", cmd, next.(Model).status)
+	}
+
+	// Changing something inside the folder forgets its size.
+	m.invalidatePaths([]FileItem{{Path: "/docs/sub/b.txt"}})
+	if _, ok := m.folderSizes["/docs"]; ok {
+		t.Error("size should be forgotten once the folder's contents change")
+	}
+
+	m.cursor = 1
+	if next, cmd = m.sizeFolder(); cmd != nil || next.(Model).status != "z.txt: 2.0 KiB" {
+		t.Errorf("a file's size = %q", next.(Model).status)
+	}
+
+	m.cursor = 0
+	next, _ = m.sizeFolder()
+	next, _ = next.(Model).handleFolderSize(FolderSizeMsg{Path: "/docs", Err: errors.New("boom")})
+	if m = next.(Model); m.sizing != nil || !strings.Contains(m.error, "boom") {
+		t.Errorf("failed measurement: sizing = %v, error = %q", m.sizing, m.error)
+	}
+}
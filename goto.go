@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// PathCompletionMsg carries the listing of Dir, fetched to complete a path
+// typed at the : prompt.
+type PathCompletionMsg struct {
+	Dir   string
+	Files []FileItem
+	Err   string
+}
+
+// goTo acts on input typed at the : prompt: a path starting with / opens that
+// folder, and a number moves the cursor to that 1-based line, clamped to the
+// list.
+func (m Model) goTo(input string) (tea.Model, tea.Cmd) {
+	if strings.HasPrefix(strings.TrimSpace(input), "/") {
+		return m.goToPath(input)
+	}
+	n, err := strconv.Atoi(input)
+	if err != nil {
+		m.error = fmt.Sprintf("Not a line number or a path: %s", input)
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.jumpToLine(max(1, n), 0)
+	cmd := m.moreCmd()
+	return m, cmd
+}
+
+// goToPath opens the folder at input. A folder already cached is known to
+// exist; any other is checked first, so a mistyped path leaves the list as it
+// is. Paths are lowercased, as listings key folders by Dropbox's path_lower.
+func (m Model) goToPath(input string) (tea.Model, tea.Cmd) {
+	p := strings.ToLower(normalizeRemotePath(input))
+	if p == m.currentPath {
+		return m, nil
+	}
+	if _, ok := m.cachedFolder(p); ok {
+		return m.openFolder(p)
+	}
+	m.loading = true
+	return m, tea.Batch(goToPathCmd(&m.config, p), m.startSpinner())
+}
+
+// goToPathCmd checks that p is a folder, then loads it as loadFilesCmd does.
+func goToPathCmd(config *Config, p string) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		switch err := checkFolder(dbx, p); {
+		case errors.Is(err, errNoSuchFolder):
+			return ErrorMsg{Error: fmt.Sprintf("No such folder: %s", p)}
+		case errors.Is(err, errNotAFolder):
+			return ErrorMsg{Error: fmt.Sprintf("%s is a file, not a folder", p)}
+		}
+		return loadFilesCmd(config, p)()
+	}
+}
+
+// errNoSuchFolder and errNotAFolder are why checkFolder can't open a path.
+var (
+	errNoSuchFolder = errors.New("no such folder")
+	errNotAFolder   = errors.New("is a file, not a folder")
+)
+
+// checkFolder returns an error wrapping errNoSuchFolder or errNotAFolder when
+// p doesn't exist or isn't a folder. Other failures are left for the listing
+// to report.
+func checkFolder(dbx files.Client, p string) error {
+	if p == "" {
+		return nil
+	}
+	meta, err := dbx.GetMetadata(files.NewGetMetadataArg(p))
+	if isNotFoundErr(err) {
+		return fmt.Errorf("%w: %s", errNoSuchFolder, p)
+	}
+	if _, isFile := meta.(*files.FileMetadata); err == nil && isFile {
+		return fmt.Errorf("%s %w", p, errNotAFolder)
+	}
+	return nil
+}
+
+// completeGoTo completes the folder name being typed at the : prompt from the
+// listing of the folder it's in. When that listing isn't at hand, it's
+// fetched, and the completion happens once it arrives.
+func (m Model) completeGoTo() (tea.Model, tea.Cmd) {
+	if !strings.HasPrefix(m.promptInput, "/") {
+		return m, nil
+	}
+	dir := completionDir(m.promptInput)
+	listing, ok := m.cachedFolder(dir)
+	if !ok && dir == m.currentPath && m.moreCursor == "" {
+		listing, ok = m.files, true
+	}
+	if !ok {
+		return m, pathCompletionCmd(&m.config, dir)
+	}
+	m.applyCompletion(listing)
+	return m, nil
+}
+
+// applyCompletion extends the prompt's input with what the folders in listing
+// whose names start with the part being typed have in common, adding a / once
+// only one is left, and otherwise listing them after the input. Names match
+// ignoring case, as Dropbox does.
+func (m *Model) applyCompletion(listing []FileItem) {
+	dir, prefix := path.Split(m.promptInput)
+	var matches []FileItem
+	for _, file := range listing {
+		if file.IsFolder && strings.HasPrefix(strings.ToLower(file.Name), strings.ToLower(prefix)) &&
+			(m.showHidden || !isHidden(file.Name) || strings.HasPrefix(prefix, ".")) {
+			matches = append(matches, file)
+		}
+	}
+	sortEntries(matches, false)
+	names := make([]string, len(matches))
+	for i, file := range matches {
+		names[i] = file.Name
+	}
+	switch len(names) {
+	case 0:
+		m.promptHint = "no folder matches"
+	case 1:
+		m.promptInput = dir + names[0] + "/"
+	default:
+		m.promptInput = dir + commonPrefix(names, prefix)
+		m.promptHint = strings.Join(names, "  ")
+	}
+}
+
+// commonPrefix returns the longest start shared by all names, ignoring case,
+// spelled as in the first. It's never shorter than typed.
+func commonPrefix(names []string, typed string) string {
+	first := []rune(names[0])
+	n := len(first)
+	for _, name := range names[1:] {
+		runes := []rune(name)
+		i := 0
+		for i < n && i < len(runes) && strings.EqualFold(string(first[i]), string(runes[i])) {
+			i++
+		}
+		n = i
+	}
+	if n < len([]rune(typed)) {
+		return typed
+	}
+	return string(first[:n])
+}
+
+// completionDir returns the folder whose entries complete input: the part
+// before its last /, lowercased like the paths folders are cached under.
+func completionDir(input string) string {
+	dir, _ := path.Split(input)
+	return strings.ToLower(normalizeRemotePath(dir))
+}
+
+// pathCompletionCmd lists the folder at dir for completing a path.
+func pathCompletionCmd(config *Config, dir string) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return PathCompletionMsg{Dir: dir, Err: err.Error()}
+		}
+		entries, err := listFolderEntries(dbx, dir)
+		if err != nil {
+			return PathCompletionMsg{Dir: dir, Err: explainTeamError(err).Error()}
+		}
+		return PathCompletionMsg{Dir: dir, Files: entries}
+	}
+}
+
+// handlePathCompletion caches a listing fetched for completion, then
+// completes the input if the prompt is still open on that folder.
+func (m Model) handlePathCompletion(msg PathCompletionMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != "" {
+		m.error = "Couldn't complete the path: " + msg.Err
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.cacheFolder(msg.Dir, msg.Files)
+	if m.prompt == promptGoTo && strings.HasPrefix(m.promptInput, "/") &&
+		completionDir(m.promptInput) == msg.Dir {
+		m.applyCompletion(msg.Files)
+	}
+	return m, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// notFoundClient answers GetMetadata from meta, failing with not_found for
+// any other path.
+type notFoundClient struct {
+	files.Client
+	meta map[string]files.IsMetadata
+}
+
+func (c *notFoundClient) GetMetadata(arg *files.GetMetadataArg) (files.IsMetadata, error) {
+	if meta, ok := c.meta[arg.Path]; ok {
+		return meta, nil
+	}
+	return nil, files.GetMetadataAPIError{EndpointError: &files.GetMetadataError{
+		Tagged: dropbox.Tagged{Tag: files.GetMetadataErrorPath},
+		Path:   &files.LookupError{Tagged: dropbox.Tagged{Tag: files.LookupErrorNotFound}},
+	}}
+}
+
+func TestCheckFolder(t *testing.T) {
+	dbx := &notFoundClient{meta: map[string]files.IsMetadata{
+		"/docs":       fakeFolder("/docs"),
+		"/docs/a.txt": fakeFile("/docs/a.txt"),
+	}}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"/docs", ""},
+		{"/docs/a.txt", "/docs/a.txt is a file, not a folder"},
+		{"/nope", "no such folder: /nope"},
+	}
+	for _, tt := range tests {
+		err := checkFolder(dbx, tt.path)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("checkFolder(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if !errors.Is(checkFolder(dbx, "/nope"), errNoSuchFolder) || !errors.Is(checkFolder(dbx, "/docs/a.txt"), errNotAFolder) {
+		t.Error("the errors should say which problem it is, for goToPathCmd to describe")
+	}
+}
+
+func TestGoToPath(t *testing.T) {
+	m := initialModel(&Config{})
+	m.cacheFolder("", []FileItem{
+		{Name: "Photos", Path: "/photos", IsFolder: true},
+		{Name: "Projects", Path: "/projects", IsFolder: true},
+		{Name: "plan.txt", Path: "/plan.txt"},
+	})
+	m.cacheFolder("/projects", []FileItem{{Name: "dbox", Path: "/projects/dbox", IsFolder: true}})
+	m.setFiles("", m.folderCache[""])
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			updated, _ := m.handleKeyPress(key)
+			m = updated.(Model)
+		}
+	}
+	typed := func(s string) []tea.KeyMsg {
+		var keys []tea.KeyMsg
+		for _, r := range s {
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return keys
+	}
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+
+	press(typed(":/p")...)
+	press(tab)
+	if m.promptInput != "/P" || m.promptHint != "Photos  Projects" {
+		t.Fatalf("after tab: input %q, hint %q; want the shared start and both folders", m.promptInput, m.promptHint)
+	}
+	press(typed("r")...)
+	press(tab)
+	if m.promptInput != "/Projects/" {
+		t.Fatalf("after tab: input %q, want the one folder left", m.promptInput)
+	}
+	press(tab)
+	if m.promptInput != "/Projects/dbox/" {
+		t.Fatalf("after tab: input %q, want the folder inside", m.promptInput)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.prompt != promptNone || m.currentPath != "" || !m.loading {
+		t.Fatalf("path %q, loading %v; want a folder not cached checked before it's opened", m.currentPath, m.loading)
+	}
+
+	m.loading = false
+	press(typed(":/PROJECTS")...)
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentPath != "/projects" || m.loading {
+		t.Fatalf("path %q, loading %v; want the cached folder opened", m.currentPath, m.loading)
+	}
+}
+
+func TestGoToLineScrollsIntoView(t *testing.T) {
+	m := initialModel(&Config{})
+	var items []FileItem
+	for i := 1; i <= 100; i++ {
+		name := fmt.Sprintf("file-%03d.txt", i)
+		items = append(items, FileItem{Name: name, Path: "/big/" + name})
+	}
+	m.setFiles("/big", items)
+
+	updated, _ := m.goTo("42")
+	m = updated.(Model)
+	view := m.View()
+	if m.cursor != 41 || !strings.Contains(view, "> ") || !strings.Contains(view, "file-042.txt") {
+		t.Fatalf("cursor %d, and line 42 should be on screen:\n%s", m.cursor, view)
+	}
+	if strings.Contains(view, "file-001.txt") {
+		t.Error("the list should have scrolled past the top")
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.height {
+		t.Errorf("view is %d lines, taller than the %d-line terminal", lines, m.height)
+	}
+
+	// Moving back up within the window doesn't scroll.
+	m.setFiles("/big", items)
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune(":")},
+		{Type: tea.KeyRunes, Runes: []rune("4")},
+		{Type: tea.KeyRunes, Runes: []rune("2")},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune("k")},
+	} {
+		next, _ := m.Update(key)
+		m = next.(Model)
+	}
+	if view := m.View(); m.cursor != 40 || !strings.Contains(view, "file-042.txt") {
+		t.Errorf("cursor %d; moving up a line shouldn't scroll line 42 out of view:\n%s", m.cursor, view)
+	}
+}
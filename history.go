@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyChrome is the number of lines the history screen uses around its
+// list (title, blank lines, hint, and message).
+const historyChrome = 6
+
+// maxHistoryShown caps how many of the most recent history entries the
+// history screen loads.
+const maxHistoryShown = 1000
+
+// Outcomes of a file in the download history.
+const (
+	outcomeDownloaded = "downloaded"
+	outcomeSkipped    = "skipped"
+	outcomeIgnored    = "ignored"
+	outcomeFailed     = "failed"
+)
+
+// historyEntry is one file of a past download job. The history is kept as
+// one JSON object per line in the state directory's history.jsonl, and only
+// ever appended to.
+type historyEntry struct {
+	Path      string    `json:"path"` // Dropbox path, with its original casing
+	LocalPath string    `json:"local_path,omitempty"`
+	Size      int64     `json:"size"`
+	Time      time.Time `json:"time"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+	Paper     bool      `json:"paper,omitempty"`
+}
+
+// item returns the file an entry is about, to download it again.
+func (e historyEntry) item() FileItem {
+	return FileItem{
+		Name:        path.Base(e.Path),
+		Path:        strings.ToLower(e.Path),
+		DisplayPath: e.Path,
+		Size:        e.Size,
+		Exportable:  e.Paper,
+	}
+}
+
+// HistoryMsg carries the download history, newest first.
+type HistoryMsg struct {
+	Entries []historyEntry
+	Err     error
+}
+
+// historyEntries turns a finished download job into history entries.
+// Problems not tied to a file (such as failing to save the queue) are left
+// out.
+func historyEntries(result DownloadCompleteMsg, config *Config, now time.Time) []historyEntry {
+	entry := func(item FileItem, outcome string) historyEntry {
+		return historyEntry{
+			Path:      item.displayPath(),
+			LocalPath: result.localPath(config, item),
+			Size:      item.Size,
+			Time:      now,
+			Outcome:   outcome,
+			Paper:     item.Exportable,
+		}
+	}
+	var entries []historyEntry
+	for _, item := range result.Downloaded {
+		entries = append(entries, entry(item, outcomeDownloaded))
+	}
+	for _, item := range result.Skipped {
+		entries = append(entries, entry(item, outcomeSkipped))
+	}
+	for _, item := range result.Ignored {
+		entries = append(entries, entry(item, outcomeIgnored))
+	}
+	for _, e := range result.Errors {
+		if e.Item.Path == "" || e.Item.IsFolder {
+			continue
+		}
+		failed := entry(e.Item, outcomeFailed)
+		failed.Error = e.Err
+		entries = append(entries, failed)
+	}
+	return entries
+}
+
+// appendHistory adds entries to the end of the history file.
+func appendHistory(entries []historyEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	file, err := statePath("history.jsonl")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// loadHistory reads the most recent maxHistoryShown history entries, newest
+// first. Lines that can't be parsed (say, one cut short by a crash) are
+// skipped.
+func loadHistory() ([]historyEntry, error) {
+	file, err := statePath("history.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var e historyEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) > 2*maxHistoryShown {
+			entries = append(entries[:0], entries[len(entries)-maxHistoryShown:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) > maxHistoryShown {
+		entries = entries[len(entries)-maxHistoryShown:]
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// loadHistoryCmd reads the download history.
+func loadHistoryCmd() tea.Cmd {
+	return func() tea.Msg {
+		entries, err := loadHistory()
+		return HistoryMsg{Entries: entries, Err: err}
+	}
+}
+
+// openHistory switches to the download history screen.
+func (m Model) openHistory() (tea.Model, tea.Cmd) {
+	m.history = []historyEntry{}
+	m.historyCursor = 0
+	return m, loadHistoryCmd()
+}
+
+// handleHistory shows the loaded history, if the screen is still open.
+func (m Model) handleHistory(msg HistoryMsg) (tea.Model, tea.Cmd) {
+	if m.history == nil {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.history = nil
+		m.error = fmt.Sprintf("Failed to read download history: %v", msg.Err)
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.history = msg.Entries
+	m.historyCursor = min(m.historyCursor, max(0, len(m.history)-1))
+	return m, nil
+}
+
+// handleHistoryKey moves through the history screen. enter or o opens the
+// local file, d downloads the file again, and esc or q goes back.
+func (m Model) handleHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.history = nil
+	case "down", "j":
+		m.historyCursor = min(m.historyCursor+1, max(0, len(m.history)-1))
+	case "up", "k":
+		m.historyCursor = max(m.historyCursor-1, 0)
+	case "enter", "o":
+		if m.historyCursor >= len(m.history) {
+			break
+		}
+		target := m.history[m.historyCursor].LocalPath
+		if _, err := os.Stat(target); target == "" || err != nil {
+			m.error = "The downloaded file isn't there anymore; press d to download it again"
+			m.errorTime = time.Now()
+			break
+		}
+		return m, func() tea.Msg {
+			if err := openLocal(target); err != nil {
+				return StatusMsg{Message: fmt.Sprintf("Failed to open %s: %v", target, err)}
+			}
+			return StatusMsg{Message: fmt.Sprintf("Opened %s", target)}
+		}
+	case "d":
+		if m.historyCursor >= len(m.history) {
+			break
+		}
+		item := m.history[m.historyCursor].item()
+		m.history = nil
+		return m, func() tea.Msg {
+			return DownloadMsg{Files: []FileItem{item}}
+		}
+	}
+	return m, nil
+}
+
+// renderHistoryView renders the download history screen.
+func (m Model) renderHistoryView() string {
+	theme := m.config.Theme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	cursorStyle := lipgloss.NewStyle().Foreground(theme.Cursor).Bold(true)
+	outcomeStyles := map[string]lipgloss.Style{
+		outcomeDownloaded: lipgloss.NewStyle().Foreground(theme.Status),
+		outcomeSkipped:    mutedStyle,
+		outcomeIgnored:    mutedStyle,
+		outcomeFailed:     lipgloss.NewStyle().Foreground(theme.Error),
+	}
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Download history") + "\n\n")
+	if len(m.history) == 0 {
+		s.WriteString(mutedStyle.Render("Nothing downloaded yet") + "\n")
+	}
+
+	page := max(1, m.height-historyChrome)
+	offset := max(0, m.historyCursor-page+1)
+	for i := offset; i < min(len(m.history), offset+page); i++ {
+		e := m.history[i]
+		when := e.Time.Local().Format("2006-01-02 15:04")
+		outcome := outcomeStyles[e.Outcome].Render(fmt.Sprintf("%-10s", e.Outcome))
+		detail := formatSize(e.Size, m.config.BinaryUnits)
+		if e.LocalPath != "" {
+			detail += " → " + e.LocalPath
+		}
+		if e.Error != "" {
+			detail = e.Error
+		}
+		line := e.Path
+		if i == m.historyCursor {
+			line = cursorStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		s.WriteString(mutedStyle.Render(when) + " " + outcome + " " + line + "  " + mutedStyle.Render(detail) + "\n")
+	}
+
+	s.WriteString("\n" + mutedStyle.Render("enter to open · d to download again · esc to go back") + "\n")
+	if m.error != "" && shown(m.errorTime, m.config.ErrorTimeout) {
+		s.WriteString(lipgloss.NewStyle().Foreground(theme.Error).Render("❌ "+m.error) + "\n")
+	} else if m.status != "" && shown(m.statusTime, m.config.StatusTimeout) {
+		s.WriteString(lipgloss.NewStyle().Foreground(theme.Status).Render(m.status) + "\n")
+	}
+	return s.String()
+}
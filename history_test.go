@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	config := &Config{DownloadPath: "/dl"}
+
+	if entries, err := loadHistory(); err != nil || entries != nil {
+		t.Fatalf("no history yet: got %v, %v", entries, err)
+	}
+
+	first := DownloadCompleteMsg{
+		Downloaded: []FileItem{{Name: "a.txt", Path: "/docs/a.txt", DisplayPath: "/Docs/a.txt", Size: 5}},
+		Errors: []ItemError{
+			{Item: FileItem{Name: "b.txt", Path: "/b.txt", Size: 3}, Err: "boom"},
+			{Err: "Failed to update download queue: nope"},
+		},
+	}
+	second := DownloadCompleteMsg{Skipped: []FileItem{{Name: "c.txt", Path: "/c.txt"}}}
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	if err := appendHistory(historyEntries(first, config, now)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := appendHistory(historyEntries(second, config, now.Add(time.Hour))); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	entries, err := loadHistory()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (problems without a file are left out): %+v", len(entries), entries)
+	}
+	if entries[0].Path != "/c.txt" || entries[0].Outcome != outcomeSkipped {
+		t.Errorf("newest entry = %+v, want c.txt skipped", entries[0])
+	}
+	a := entries[2]
+	if a.Path != "/Docs/a.txt" || a.LocalPath != filepath.Join("/dl", "docs", "a.txt") || a.Outcome != outcomeDownloaded || !a.Time.Equal(now) {
+		t.Errorf("oldest entry = %+v", a)
+	}
+	if entries[1].Outcome != outcomeFailed || entries[1].Error != "boom" {
+		t.Errorf("failed entry = %+v", entries[1])
+	}
+}
+
+func TestHistoryRedownload(t *testing.T) {
+	m := initialModel(&Config{})
+	m.history = []historyEntry{{Path: "/Docs/a.txt", Size: 5, Outcome: outcomeDownloaded}}
+
+	next, cmd := m.handleHistoryKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if next.(Model).history != nil {
+		t.Error("downloading again should close the history screen")
+	}
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || len(msg.Files) != 1 {
+		t.Fatalf("got %#v, want a download", cmd())
+	}
+	if got := msg.Files[0]; got.Path != "/docs/a.txt" || got.DisplayPath != "/Docs/a.txt" || got.Name != "a.txt" || got.Size != 5 {
+		t.Errorf("file = %+v", got)
+	}
+}
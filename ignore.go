@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile is the name of the file listing paths to leave out of folder
+// downloads, one glob pattern per line, like .gitignore.
+const ignoreFile = ".dboxignore"
+
+// ignorePattern is one line of a .dboxignore file.
+type ignorePattern struct {
+	glob       string // lowercased, as Dropbox paths compare
+	anchored   bool   // contains a slash: matched against the whole path
+	folderOnly bool   // ended in a slash: matches folders only
+}
+
+// ignoreRules are the patterns from every .dboxignore found, parsed once per
+// download job. The zero value ignores nothing.
+type ignoreRules struct {
+	patterns []ignorePattern
+}
+
+// loadIgnoreRules reads the .dboxignore files in dirs, skipping those that
+// don't exist. A directory listed twice (the download folder is the working
+// directory) is read once.
+func loadIgnoreRules(dirs ...string) (ignoreRules, error) {
+	var rules ignoreRules
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		file := filepath.Join(dir, ignoreFile)
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		patterns, err := readIgnoreFile(file)
+		if err != nil {
+			return rules, err
+		}
+		rules.patterns = append(rules.patterns, patterns...)
+	}
+	return rules, nil
+}
+
+// readIgnoreFile parses the patterns in the file at name. Blank lines and
+// lines starting with # are skipped. A missing file has no patterns.
+func readIgnoreFile(name string) ([]ignorePattern, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", name, err)
+	}
+	defer f.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p := ignorePattern{glob: strings.ToLower(text)}
+		if strings.HasSuffix(p.glob, "/") {
+			p.folderOnly = true
+			p.glob = strings.TrimRight(p.glob, "/")
+		}
+		if strings.Contains(p.glob, "/") {
+			p.anchored = true
+			p.glob = "/" + strings.TrimPrefix(p.glob, "/")
+		}
+		if _, err := path.Match(p.glob, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: bad pattern %q: %w", name, line, text, err)
+		}
+		patterns = append(patterns, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", name, err)
+	}
+	return patterns, nil
+}
+
+// matches reports whether item is ignored. Patterns with a slash are matched
+// against its whole Dropbox path, and others against its name, so "*.tmp"
+// ignores temporary files in any folder.
+func (r ignoreRules) matches(item FileItem) bool {
+	for _, p := range r.patterns {
+		if p.folderOnly && !item.IsFolder {
+			continue
+		}
+		target := strings.ToLower(item.Name)
+		if p.anchored {
+			target = strings.ToLower(item.Path)
+		}
+		if ok, _ := path.Match(p.glob, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filter splits the contents of a folder being downloaded into those to keep
+// and those ignored. Everything inside an ignored folder is dropped too, and
+// only the folder itself is returned in ignored.
+func (r ignoreRules) filter(items []FileItem) (kept, ignored []FileItem) {
+	if len(r.patterns) == 0 {
+		return items, nil
+	}
+	var ignoredFolders []string
+	for _, item := range items {
+		inside := false
+		for _, folder := range ignoredFolders {
+			if strings.HasPrefix(strings.ToLower(item.Path), folder+"/") {
+				inside = true
+				break
+			}
+		}
+		switch {
+		case inside:
+		case r.matches(item):
+			ignored = append(ignored, item)
+			if item.IsFolder {
+				ignoredFolders = append(ignoredFolders, strings.ToLower(item.Path))
+			}
+		default:
+			kept = append(kept, item)
+		}
+	}
+	return kept, ignored
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ignoreFile), []byte("# scratch files\n*.TMP\n\nbuild/\n/root/docs/draft-*\n"), 0644)
+	rules, err := loadIgnoreRules(dir, "", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.patterns) != 3 {
+		t.Fatalf("patterns = %+v, want 3 (read once)", rules.patterns)
+	}
+
+	tests := []struct {
+		item FileItem
+		want bool
+	}{
+		{FileItem{Name: "cache.tmp", Path: "/root/a/cache.tmp"}, true},
+		{FileItem{Name: "notes.txt", Path: "/root/notes.txt"}, false},
+		{FileItem{Name: "build", Path: "/root/build", IsFolder: true}, true},
+		{FileItem{Name: "build", Path: "/root/build"}, false}, // folders only
+		{FileItem{Name: "Draft-1.md", Path: "/root/docs/draft-1.md"}, true},
+		{FileItem{Name: "draft-1.md", Path: "/root/other/draft-1.md"}, false},
+	}
+	for _, tt := range tests {
+		if got := rules.matches(tt.item); got != tt.want {
+			t.Errorf("matches(%s) = %v, want %v", tt.item.Path, got, tt.want)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, ignoreFile), []byte("[oops\n"), 0644)
+	if _, err := loadIgnoreRules(dir); err == nil || !strings.Contains(err.Error(), ":1: bad pattern") {
+		t.Errorf("a bad pattern should be reported with its line, got %v", err)
+	}
+}
+
+func TestDownloadFilesIgnores(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ignoreFile), []byte("*.tmp\nbuild/\n"), 0644)
+	keep := fakeFile("/root/keep.txt")
+	keep.Size = 5 // fakeZipClient downloads "hello"
+	dbx := &fakeZipClient{tree: &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root":       {keep, fakeFile("/root/scratch.tmp"), fakeFolder("/root/build")},
+		"/root/build": {fakeFile("/root/build/out.bin")},
+	}}}
+	items := []FileItem{{Name: "root", Path: "/root", IsFolder: true}}
+	result := downloadFiles(dbx, items, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()), nil)
+
+	var downloaded, ignored []string
+	for _, item := range result.Downloaded {
+		downloaded = append(downloaded, item.Path)
+	}
+	for _, item := range result.Ignored {
+		ignored = append(ignored, item.Path)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("errors: %+v", result.Errors)
+	}
+	if got := strings.Join(downloaded, ","); got != "/root/keep.txt" {
+		t.Errorf("downloaded = %s, want only keep.txt", got)
+	}
+	if got := strings.Join(ignored, ","); got != "/root/build,/root/scratch.tmp" {
+		t.Errorf("ignored = %s, want the ignored file and folder", got)
+	}
+	if len(result.Skipped) > 0 {
+		t.Errorf("skipped = %+v, want ignored entries kept apart", result.Skipped)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "root", "build")); err == nil {
+		t.Error("an ignored folder shouldn't be created")
+	}
+
+	// Without its patterns, the job can't tell what to leave out.
+	os.WriteFile(filepath.Join(dir, ignoreFile), []byte("[oops\n"), 0644)
+	os.Remove(filepath.Join(dir, "root", "keep.txt"))
+	result = downloadFiles(dbx, items, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()), nil)
+	if len(result.Downloaded) > 0 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Err, "bad pattern") {
+		t.Errorf("an unreadable .dboxignore should fail the job, got %+v", result)
+	}
+}
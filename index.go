@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// indexEntry records a downloaded file: the Dropbox path it came from, the
+// content hash it had and the local file's modification time once written.
+// Entries from before paths were recorded have no Path.
+type indexEntry struct {
+	Path        string    `json:"path,omitempty"`
+	ContentHash string    `json:"content_hash"`
+	Modified    time.Time `json:"modified"`
+}
+
+// downloadIndex remembers what each local file was downloaded from, keyed by
+// local path, so later downloads can tell a file that changed in Dropbox from
+// one that didn't without fetching it again. It's kept in the state directory
+// as downloads.json.
+type downloadIndex struct {
+	entries map[string]indexEntry
+	dirty   bool
+}
+
+// loadDownloadIndex reads the index, returning an empty one if there isn't
+// one yet.
+func loadDownloadIndex() (*downloadIndex, error) {
+	idx := &downloadIndex{entries: map[string]indexEntry{}}
+	path, err := statePath("downloads.json")
+	if err != nil {
+		return idx, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, err
+	}
+	if err := json.Unmarshal(data, &idx.entries); err != nil {
+		idx.entries = map[string]indexEntry{}
+		return idx, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// save writes the index back if anything was recorded.
+func (idx *downloadIndex) save() error {
+	if !idx.dirty {
+		return nil
+	}
+	path, err := statePath("downloads.json")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(idx.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	idx.dirty = false
+	return nil
+}
+
+// check reports whether the index knows the file at localPath, and if so
+// whether it's an up-to-date copy of item: downloaded from the same content
+// hash and not changed locally since. A local file whose modification time
+// moved is hashed, so one that was only touched still counts.
+func (idx *downloadIndex) check(localPath string, item FileItem) (upToDate, known bool) {
+	if idx == nil || item.Exportable || item.ContentHash == "" {
+		return false, false
+	}
+	entry, ok := idx.entries[localPath]
+	if !ok {
+		return false, false
+	}
+	if entry.ContentHash != item.ContentHash {
+		return false, true // changed in Dropbox
+	}
+	info, err := os.Stat(localPath)
+	if err != nil || info.IsDir() || info.Size() != item.Size {
+		return false, true
+	}
+	if info.ModTime().Equal(entry.Modified) {
+		return true, true
+	}
+	hash, err := dropboxContentHash(localPath)
+	if err != nil || hash != item.ContentHash {
+		return false, true
+	}
+	entry.ContentHash, entry.Modified = hash, info.ModTime()
+	idx.entries[localPath] = entry
+	idx.dirty = true
+	return true, true
+}
+
+// heldByOther reports whether the file at localPath is still there and was
+// downloaded from a Dropbox path other than dropboxPath, so writing
+// dropboxPath's file there would lose it.
+func (idx *downloadIndex) heldByOther(localPath, dropboxPath string) bool {
+	if idx == nil {
+		return false
+	}
+	entry, ok := idx.entries[localPath]
+	if !ok || entry.Path == "" || entry.Path == dropboxPath {
+		return false
+	}
+	_, err := os.Stat(localPath)
+	return err == nil
+}
+
+// record notes that item was just downloaded to localPath.
+func (idx *downloadIndex) record(localPath string, item FileItem) {
+	if idx == nil || item.Exportable || item.ContentHash == "" {
+		return
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return
+	}
+	idx.entries[localPath] = indexEntry{Path: item.Path, ContentHash: item.ContentHash, Modified: info.ModTime()}
+	idx.dirty = true
+}
+
+// downloadWithIndex runs downloadFiles with the download index loaded, and
+// saves it once the job is done. Problems with the index are reported among
+// the job's errors but don't stop it.
+func downloadWithIndex(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress) DownloadCompleteMsg {
+	idx, loadErr := loadDownloadIndex()
+	result := downloadFiles(dbx, fileItems, config, progress, idx)
+	for _, err := range []error{loadErr, idx.save()} {
+		if err != nil {
+			result.Errors = append(result.Errors, ItemError{Err: fmt.Sprintf("Failed to update download index: %v", err)})
+		}
+	}
+	return result
+}
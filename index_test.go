@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadIndexCheck(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "a.txt")
+	os.WriteFile(local, []byte("hello"), 0644)
+	hash, err := dropboxContentHash(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := FileItem{Name: "a.txt", Path: "/a.txt", Size: 5, ContentHash: hash}
+
+	idx := &downloadIndex{entries: map[string]indexEntry{}}
+	if _, known := idx.check(local, item); known {
+		t.Fatal("an unrecorded file shouldn't be known")
+	}
+	idx.record(local, item)
+	if upToDate, known := idx.check(local, item); !upToDate || !known {
+		t.Errorf("just recorded: upToDate %v, known %v", upToDate, known)
+	}
+
+	// Touching the local file doesn't make it stale.
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(local, later, later)
+	if upToDate, _ := idx.check(local, item); !upToDate {
+		t.Error("a file that was only touched should still be up to date")
+	}
+
+	// A new version in Dropbox of the same size is fetched again.
+	changed := item
+	changed.ContentHash = "other"
+	if upToDate, known := idx.check(local, changed); upToDate || !known {
+		t.Errorf("changed in Dropbox: upToDate %v, known %v", upToDate, known)
+	}
+
+	// So is a file edited locally.
+	os.WriteFile(local, []byte("HELLO"), 0644)
+	if upToDate, _ := idx.check(local, item); upToDate {
+		t.Error("a locally edited file shouldn't be up to date")
+	}
+
+	var none *downloadIndex
+	if _, known := none.check(local, item); known {
+		t.Error("a nil index knows nothing")
+	}
+}
+
+func TestDownloadIndexRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	local := filepath.Join(dir, "a.txt")
+	os.WriteFile(local, []byte("hello"), 0644)
+
+	idx, err := loadDownloadIndex()
+	if err != nil || len(idx.entries) != 0 {
+		t.Fatalf("no index yet: got %v, %v", idx.entries, err)
+	}
+	idx.record(local, FileItem{Path: "/a.txt", Size: 5, ContentHash: "abc"})
+	if err := idx.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := loadDownloadIndex()
+	if err != nil || got.entries[local].ContentHash != "abc" {
+		t.Errorf("load = %v, %v", got.entries, err)
+	}
+}
+
+func TestDownloadFilesUsesIndex(t *testing.T) {
+	dir := t.TempDir()
+	// The local copy has the right size, but Dropbox has a newer version.
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+	idx := &downloadIndex{entries: map[string]indexEntry{
+		filepath.Join(dir, "a.txt"): {ContentHash: "old"},
+	}}
+	dbx := &fakeDownloadClient{content: "world"}
+	item := FileItem{Name: "a.txt", Path: "/a.txt", Size: 5, ContentHash: "new"}
+
+	result := downloadFiles(dbx, []FileItem{item}, &Config{DownloadPath: dir, SkipExisting: skipIfSize}, newDownloadProgress(time.Now()), idx)
+	if len(result.Downloaded) != 1 {
+		t.Fatalf("result = %+v, want a.txt downloaded again", result)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(got) != "world" {
+		t.Errorf("content = %q", got)
+	}
+	if idx.entries[filepath.Join(dir, "a.txt")].ContentHash != "new" {
+		t.Error("the index should record the new hash")
+	}
+
+	result = downloadFiles(dbx, []FileItem{item}, &Config{DownloadPath: dir, SkipExisting: skipIfSize}, newDownloadProgress(time.Now()), idx)
+	if len(result.Skipped) != 1 {
+		t.Errorf("result = %+v, want a.txt skipped the second time", result)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fileInfo is the metadata shown in the details panel.
+type fileInfo struct {
+	Name         string
+	Path         string // as displayed by Dropbox, with original casing
+	IsFolder     bool
+	Size         int64
+	Modified     time.Time // server-side modification time
+	ContentHash  string
+	Rev          string
+	Downloadable bool
+	Children     int // folders only
+
+	// The downloaded copy, if there is one. Its hash is computed once the
+	// panel is open, so LocalHash and LocalErr are empty until then.
+	LocalPath string
+	LocalHash string
+	LocalErr  string
+}
+
+// FileInfoMsg carries metadata fetched for the details panel, and the entry
+// it was fetched for.
+type FileInfoMsg struct {
+	Info fileInfo
+	Item FileItem
+}
+
+// LocalHashMsg carries the content hash of the downloaded copy at LocalPath.
+type LocalHashMsg struct {
+	LocalPath string
+	Hash      string
+	Err       error
+}
+
+// fileInfoCmd fetches full metadata for item. Folders have no size or hash,
+// so their direct children are counted instead.
+func fileInfoCmd(config *Config, item FileItem) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		info, err := fetchFileInfo(dbx, item.Path)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to get details for %s: %v", item.Name, err)}
+		}
+		return FileInfoMsg{Info: info, Item: item}
+	}
+}
+
+// localHashCmd computes the Dropbox content hash of the local file at path.
+func localHashCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		hash, err := dropboxContentHash(path)
+		return LocalHashMsg{LocalPath: path, Hash: hash, Err: err}
+	}
+}
+
+// handleFileInfo opens the details panel. If the file has been downloaded,
+// its local hash is computed in the background to compare with Dropbox's.
+// Paper docs are skipped: their exports never match the Dropbox hash.
+func (m Model) handleFileInfo(msg FileInfoMsg) (tea.Model, tea.Cmd) {
+	info := msg.Info
+	m.info = &info
+	if info.IsFolder || msg.Item.Exportable || m.config.link != nil {
+		return m, nil
+	}
+	local, err := itemLocalPath(&m.config, msg.Item)
+	if err != nil {
+		return m, nil
+	}
+	if st, err := os.Stat(local); err != nil || !st.Mode().IsRegular() {
+		return m, nil
+	}
+	m.info.LocalPath = local
+	return m, localHashCmd(local)
+}
+
+// handleLocalHash fills in the local hash, if the panel still shows the file
+// it was computed for.
+func (m Model) handleLocalHash(msg LocalHashMsg) (tea.Model, tea.Cmd) {
+	if m.info == nil || m.info.LocalPath != msg.LocalPath {
+		return m, nil
+	}
+	info := *m.info
+	if msg.Err != nil {
+		info.LocalErr = msg.Err.Error()
+	} else {
+		info.LocalHash = msg.Hash
+	}
+	m.info = &info
+	return m, nil
+}
+
+// localHashLabel describes the downloaded copy's hash and whether it matches
+// the one on Dropbox.
+func localHashLabel(info *fileInfo) string {
+	switch {
+	case info.LocalPath == "":
+		return "not downloaded"
+	case info.LocalErr != "":
+		return "unreadable: " + info.LocalErr
+	case info.LocalHash == "":
+		return "computing..."
+	case info.LocalHash == info.ContentHash:
+		return info.LocalHash + " ✓ matches"
+	default:
+		return info.LocalHash + " ✗ differs"
+	}
+}
+
+// fetchFileInfo looks up the metadata for a Dropbox path.
+func fetchFileInfo(dbx files.Client, path string) (fileInfo, error) {
+	meta, err := dbx.GetMetadata(files.NewGetMetadataArg(path))
+	if err != nil {
+		return fileInfo{}, err
+	}
+	switch v := meta.(type) {
+	case *files.FileMetadata:
+		return fileInfo{
+			Name:         v.Name,
+			Path:         v.PathDisplay,
+			Size:         int64(v.Size),
+			Modified:     v.ServerModified,
+			ContentHash:  v.ContentHash,
+			Rev:          v.Rev,
+			Downloadable: v.IsDownloadable,
+		}, nil
+	case *files.FolderMetadata:
+		children, err := listFolderEntries(dbx, path)
+		if err != nil {
+			return fileInfo{}, err
+		}
+		return fileInfo{
+			Name:     v.Name,
+			Path:     v.PathDisplay,
+			IsFolder: true,
+			Children: len(children),
+		}, nil
+	default:
+		return fileInfo{}, fmt.Errorf("not a file or folder")
+	}
+}
+
+// handleInfoKey closes the details panel on esc, i, or q.
+func (m Model) handleInfoKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "i", "q":
+		m.info = nil
+	}
+	return m, nil
+}
+
+// renderInfoView renders the details panel for m.info.
+func (m Model) renderInfoView() string {
+	info := m.info
+
+	theme := m.config.Theme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Selected)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(0, 1)
+
+	type field struct{ label, value string }
+	fields := []field{{"Path", info.Path}}
+	if info.IsFolder {
+		fields = append(fields, field{"Contains", pluralize(info.Children, "item")})
+	} else {
+		downloadable := "yes"
+		if !info.Downloadable {
+			downloadable = "no (export only)"
+		}
+		fields = append(fields,
+			field{"Size", fmt.Sprintf("%s (%d bytes)", formatSize(info.Size, m.config.BinaryUnits), info.Size)},
+			field{"Modified", info.Modified.Local().Format("2006-01-02 15:04:05 MST")},
+			field{"Content hash", info.ContentHash},
+			field{"Local hash", localHashLabel(info)},
+			field{"Rev", info.Rev},
+			field{"Downloadable", downloadable},
+		)
+	}
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(info.Name) + "\n\n")
+	for _, f := range fields {
+		s.WriteString(labelStyle.Render(fmt.Sprintf("%-13s", f.label)) + f.value + "\n")
+	}
+	s.WriteString("\n" + hintStyle.Render("press esc or i to close"))
+
+	if m.width > 4 {
+		panelStyle = panelStyle.MaxWidth(m.width)
+	}
+	return panelStyle.Render(s.String()) + "\n"
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeMetadataClient answers GetMetadata from a map, and ListFolder from the
+// embedded fake's tree.
+type fakeMetadataClient struct {
+	*fakeFilesClient
+	meta map[string]files.IsMetadata
+}
+
+func (f *fakeMetadataClient) GetMetadata(arg *files.GetMetadataArg) (files.IsMetadata, error) {
+	return f.meta[arg.Path], nil
+}
+
+func TestFetchFileInfo(t *testing.T) {
+	file := fakeFile("/docs/a.txt")
+	file.PathDisplay = "/Docs/A.txt"
+	file.ContentHash = "abc123"
+	file.Rev = "015f"
+	file.IsDownloadable = true
+	dbx := &fakeMetadataClient{
+		fakeFilesClient: &fakeFilesClient{tree: map[string][]files.IsMetadata{
+			"/docs": {file, fakeFolder("/docs/sub")},
+		}},
+		meta: map[string]files.IsMetadata{
+			"/docs":       fakeFolder("/docs"),
+			"/docs/a.txt": file,
+		},
+	}
+
+	info, err := fetchFileInfo(dbx, "/docs/a.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Path != "/Docs/A.txt" || info.ContentHash != "abc123" || info.Rev != "015f" || !info.Downloadable || info.IsFolder {
+		t.Errorf("file info = %+v", info)
+	}
+
+	info, err = fetchFileInfo(dbx, "/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.IsFolder || info.Children != 2 {
+		t.Errorf("folder info = %+v, want a folder with 2 children", info)
+	}
+}
+
+func TestLocalHashComparison(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(dir, "docs", "a.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := dropboxContentHash(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(&Config{DownloadPath: dir})
+	item := FileItem{Name: "a.txt", Path: "/docs/a.txt"}
+	updated, cmd := m.handleFileInfo(FileInfoMsg{Info: fileInfo{Path: "/docs/a.txt", ContentHash: hash}, Item: item})
+	m = updated.(Model)
+	if m.info.LocalPath != local || cmd == nil {
+		t.Fatalf("local path = %q, want %q with a hash command", m.info.LocalPath, local)
+	}
+	if got := localHashLabel(m.info); got != "computing..." {
+		t.Errorf("label before hashing = %q", got)
+	}
+	updated, _ = m.handleLocalHash(cmd().(LocalHashMsg))
+	m = updated.(Model)
+	if got, want := localHashLabel(m.info), hash+" ✓ matches"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+
+	m.info.ContentHash = "other"
+	if got, want := localHashLabel(m.info), hash+" ✗ differs"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+
+	missing := FileItem{Name: "b.txt", Path: "/docs/b.txt"}
+	updated, cmd = m.handleFileInfo(FileInfoMsg{Info: fileInfo{Path: "/docs/b.txt"}, Item: missing})
+	if m = updated.(Model); cmd != nil || localHashLabel(m.info) != "not downloaded" {
+		t.Errorf("missing file: label = %q, cmd = %v", localHashLabel(m.info), cmd)
+	}
+}
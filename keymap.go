@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// action is something a key can be bound to in browse mode. The names are
+// what the settings file's keys section uses.
+type action string
+
+const (
+	actionQuit               action = "quit"
+	actionHelp               action = "help"
+	actionUp                 action = "up"
+	actionDown               action = "down"
+	actionTop                action = "top"
+	actionBottom             action = "bottom"
+	actionPageUp             action = "page_up"
+	actionPageDown           action = "page_down"
+	actionNextFolder         action = "next_folder"
+	actionPrevFolder         action = "prev_folder"
+	actionGoTo               action = "go_to"
+	actionOpen               action = "open"
+	actionParent             action = "parent"
+	actionSelect             action = "select"
+	actionSelectPattern      action = "select_pattern"
+	actionMarkRange          action = "mark_range"
+	actionInvertSelection    action = "invert_selection"
+	actionShowSelection      action = "show_selection"
+	actionSearch             action = "search"
+	actionNextMatch          action = "next_match"
+	actionPrevMatch          action = "prev_match"
+	actionInfo               action = "info"
+	actionHistory            action = "history"
+	actionSharedFolders      action = "shared_folders"
+	actionFolderSize         action = "folder_size"
+	actionDownload           action = "download"
+	actionShallowDownload    action = "download_shallow"
+	actionDownloadZip        action = "download_zip"
+	actionDelete             action = "delete"
+	actionMove               action = "move"
+	actionArchive            action = "archive"
+	actionDuplicate          action = "duplicate"
+	actionEmptyTrash         action = "empty_trash"
+	actionUndo               action = "undo"
+	actionExportListing      action = "export_listing"
+	actionExportTree         action = "export_tree"
+	actionOpenWeb            action = "open_web"
+	actionOpenWebItem        action = "open_web_item"
+	actionOpenLocal          action = "open_local"
+	actionCopyLocalPath      action = "copy_local_path"
+	actionTempLink           action = "temp_link"
+	actionRefresh            action = "refresh"
+	actionClearCache         action = "clear_cache"
+	actionToggleHidden       action = "toggle_hidden"
+	actionToggleFoldersFirst action = "toggle_folders_first"
+	actionToggleFullPath     action = "toggle_full_path"
+	actionToggleDetails      action = "toggle_details"
+	actionTogglePreview      action = "toggle_preview"
+	actionSkipFile           action = "skip_file"
+)
+
+// downloadActions only apply while a download runs, when browse keys don't,
+// so their keys are looked up apart from the others and may be shared with
+// them. They're single keys, not sequences.
+var downloadActions = map[action]bool{
+	actionSkipFile: true,
+}
+
+// defaultKeys are the bindings used for any action the settings file doesn't
+// rebind. A two-key sequence is written with a space between the keys, as in
+// "g g". ctrl+c always quits and isn't listed.
+var defaultKeys = map[action][]string{
+	actionQuit:               {"q"},
+	actionHelp:               {"?"},
+	actionUp:                 {"up", "k"},
+	actionDown:               {"down", "j"},
+	actionTop:                {"g g"},
+	actionBottom:             {"G"},
+	actionPageUp:             {"ctrl+u"},
+	actionPageDown:           {"ctrl+d"},
+	actionNextFolder:         {"}"},
+	actionPrevFolder:         {"{"},
+	actionGoTo:               {":"},
+	actionOpen:               {"enter"},
+	actionParent:             {"esc"},
+	actionSelect:             {"space"},
+	actionSelectPattern:      {"+"},
+	actionMarkRange:          {"v"},
+	actionInvertSelection:    {"*"},
+	actionShowSelection:      {"a"},
+	actionSearch:             {"/"},
+	actionNextMatch:          {"n"},
+	actionPrevMatch:          {"N"},
+	actionInfo:               {"i"},
+	actionHistory:            {"H"},
+	actionSharedFolders:      {"m"},
+	actionFolderSize:         {"s"},
+	actionDownload:           {"d"},
+	actionShallowDownload:    {"S"},
+	actionDownloadZip:        {"z"},
+	actionDelete:             {"D"},
+	actionMove:               {"M"},
+	actionArchive:            {"A"},
+	actionDuplicate:          {"c"},
+	actionEmptyTrash:         {"T"},
+	actionUndo:               {"u"},
+	actionExportListing:      {"e"},
+	actionExportTree:         {"E"},
+	actionOpenWeb:            {"b"},
+	actionOpenWebItem:        {"B"},
+	actionOpenLocal:          {"o"},
+	actionCopyLocalPath:      {"y"},
+	actionTempLink:           {"t"},
+	actionRefresh:            {"R"},
+	actionClearCache:         {"C"},
+	actionToggleHidden:       {"."},
+	actionToggleFoldersFirst: {"F"},
+	actionToggleFullPath:     {"p"},
+	actionToggleDetails:      {"L"},
+	actionTogglePreview:      {"P"},
+	actionSkipFile:           {"s"},
+}
+
+// keyMap resolves pressed keys to actions.
+type keyMap struct {
+	// bindings maps a key, as tea.KeyMsg.String() reports it, to its action.
+	bindings map[string]action
+	// downloadBindings does the same for downloadActions.
+	downloadBindings map[string]action
+	// sequences maps two-key sequences such as gg to their action.
+	sequences map[[2]string]action
+	// prefixes holds the first key of every sequence.
+	prefixes map[string]bool
+	// keys lists each action's bindings as written, for the help view.
+	keys map[action][]string
+}
+
+// newKeyMap builds the key map from the defaults plus the settings file's
+// overrides, which replace an action's default keys entirely. A default key
+// that an override claims for another action is dropped from its default
+// action; overrides that conflict with each other are an error, as are
+// unknown actions. downloadActions only conflict with each other.
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	// parsed key -> overriding action, for browse and downloadActions apart
+	claimed := map[bool]map[[2]string]action{false: {}, true: {}}
+	for name, keys := range overrides {
+		a := action(name)
+		if _, ok := defaultKeys[a]; !ok {
+			return keyMap{}, fmt.Errorf("keys: unknown action %q", name)
+		}
+		for _, key := range keys {
+			parsed, err := parseBinding(key)
+			if err != nil {
+				return keyMap{}, fmt.Errorf("keys: %s: %w", name, err)
+			}
+			if downloadActions[a] && parsed[1] != "" {
+				return keyMap{}, fmt.Errorf("keys: %s: %q: only single keys work while downloading", name, key)
+			}
+			if other, ok := claimed[downloadActions[a]][parsed]; ok && other != a {
+				return keyMap{}, fmt.Errorf("keys: %q is bound to both %s and %s", key, other, a)
+			}
+			claimed[downloadActions[a]][parsed] = a
+		}
+	}
+
+	km := keyMap{
+		bindings:         make(map[string]action),
+		downloadBindings: make(map[string]action),
+		sequences:        make(map[[2]string]action),
+		prefixes:         make(map[string]bool),
+		keys:             make(map[action][]string),
+	}
+	for a, defaults := range defaultKeys {
+		keys, overridden := overrides[string(a)]
+		if !overridden {
+			keys = defaults
+		}
+		for _, key := range keys {
+			parsed, _ := parseBinding(key)
+			if owner, ok := claimed[downloadActions[a]][parsed]; ok && owner != a {
+				continue // taken over by an override
+			}
+			if downloadActions[a] {
+				km.downloadBindings[parsed[0]] = a
+			} else if parsed[1] == "" {
+				km.bindings[parsed[0]] = a
+			} else {
+				km.sequences[parsed] = a
+				km.prefixes[parsed[0]] = true
+			}
+			km.keys[a] = append(km.keys[a], key)
+		}
+	}
+
+	// A key that starts a sequence can't also act on its own, since there'd
+	// be no telling which was meant until the next key.
+	for prefix := range km.prefixes {
+		if a, ok := km.bindings[prefix]; ok {
+			return keyMap{}, fmt.Errorf("keys: %q is bound to %s but also starts a sequence", prefix, a)
+		}
+	}
+	return km, nil
+}
+
+// parseBinding converts a key as written in the settings file to the form
+// bubbletea reports it in ("space" becomes " "). A sequence like "g g" fills
+// both slots; a single key leaves the second empty.
+func parseBinding(key string) ([2]string, error) {
+	fields := strings.Fields(key)
+	for i, f := range fields {
+		if f == "space" {
+			fields[i] = " "
+		}
+	}
+	switch len(fields) {
+	case 1:
+		return [2]string{fields[0]}, nil
+	case 2:
+		return [2]string{fields[0], fields[1]}, nil
+	default:
+		return [2]string{}, fmt.Errorf("%q: bindings are a key or a sequence of two keys", key)
+	}
+}
+
+// action returns the action bound to a single key.
+func (km keyMap) action(key string) (action, bool) {
+	a, ok := km.bindings[key]
+	return a, ok
+}
+
+// downloadAction returns the action bound to key while a download runs.
+func (km keyMap) downloadAction(key string) (action, bool) {
+	a, ok := km.downloadBindings[key]
+	return a, ok
+}
+
+// sequenceAction returns the action bound to the sequence first, second.
+func (km keyMap) sequenceAction(first, second string) (action, bool) {
+	a, ok := km.sequences[[2]string{first, second}]
+	return a, ok
+}
+
+// isPrefix reports whether key starts a sequence, so the next key is needed.
+func (km keyMap) isPrefix(key string) bool {
+	return km.prefixes[key]
+}
+
+// describe lists the keys bound to a for the help view, e.g. "up / k", with
+// sequences written without the space ("gg").
+func (km keyMap) describe(a action) string {
+	if len(km.keys[a]) == 0 {
+		return "(unbound)"
+	}
+	keys := make([]string, len(km.keys[a]))
+	for i, key := range km.keys[a] {
+		keys[i] = strings.Join(strings.Fields(key), "")
+	}
+	return strings.Join(keys, " / ")
+}
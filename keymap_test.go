@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		km, err := newKeyMap(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if a, _ := km.action("j"); a != actionDown {
+			t.Errorf("j = %q, want down", a)
+		}
+		if a, _ := km.action(" "); a != actionSelect {
+			t.Errorf("space = %q, want select", a)
+		}
+		if a, _ := km.sequenceAction("g", "g"); a != actionTop || !km.isPrefix("g") {
+			t.Errorf("gg = %q, want top", a)
+		}
+		if got := km.describe(actionUp); got != "up / k" {
+			t.Errorf("describe(up) = %q", got)
+		}
+	})
+
+	t.Run("overrides replace defaults and take keys over", func(t *testing.T) {
+		// Rebinding down and up drops j and k; n is taken from next_match.
+		km, err := newKeyMap(map[string][]string{
+			"down": {"t", "down"},
+			"up":   {"n", "up"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if a, _ := km.action("t"); a != actionDown {
+			t.Errorf("t = %q, want down", a)
+		}
+		if _, ok := km.action("j"); ok {
+			t.Error("j should no longer be bound once down is rebound")
+		}
+		if a, _ := km.action("n"); a != actionUp {
+			t.Errorf("n = %q, want up (taken from next_match)", a)
+		}
+		if got := km.describe(actionNextMatch); got != "(unbound)" {
+			t.Errorf("describe(next_match) = %q, want (unbound)", got)
+		}
+	})
+
+	t.Run("download keys are apart from browse keys", func(t *testing.T) {
+		km, err := newKeyMap(map[string][]string{"skip_file": {"x"}, "select": {"x"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if a, _ := km.downloadAction("x"); a != actionSkipFile {
+			t.Errorf("x while downloading = %q, want skip_file", a)
+		}
+		if a, _ := km.action("x"); a != actionSelect {
+			t.Errorf("x = %q, want select", a)
+		}
+		if a, _ := km.action("s"); a != actionFolderSize {
+			t.Errorf("s = %q, want folder_size still", a)
+		}
+		if _, ok := km.downloadAction("s"); ok {
+			t.Error("s should no longer skip once skip_file is rebound")
+		}
+	})
+
+	errTests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown action", map[string][]string{"teleport": {"t"}}, "unknown action"},
+		{"conflicting overrides", map[string][]string{"up": {"x"}, "down": {"x"}}, "bound to both"},
+		{"prefix also bound", map[string][]string{"top": {"d d"}}, "also starts a sequence"},
+		{"long sequence", map[string][]string{"top": {"g g g"}}, "two keys"},
+		{"download sequence", map[string][]string{"skip_file": {"s s"}}, "only single keys"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyMap(tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemappedKeys(t *testing.T) {
+	m := initialModel(&Config{Keys: map[string][]string{"down": {"t"}, "top": {"space space"}, "select": {"x"}}})
+	m.setFiles("", []FileItem{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+
+	press := func(key tea.KeyMsg) {
+		next, _ := m.Update(key)
+		m = next.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.cursor != 2 {
+		t.Fatalf("cursor = %d after tt, want 2", m.cursor)
+	}
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d after space space, want 0", m.cursor)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// keyringService is the service name dbox's credentials are stored under in
+// the OS keyring. Each credential is an account named after its environment
+// variable (e.g. DROPBOX_REFRESH_TOKEN).
+const keyringService = "dbox"
+
+// keyringLookup reads a credential from the OS keyring, once per account:
+// credentials are read for every API call, and each read runs a command.
+// It's a variable so tests can stand in for the keyring.
+var keyringLookup = cacheLookups(readKeyring)
+
+// cacheLookups wraps lookup so each account is looked up only the first time
+// it's asked for, including when nothing is found.
+func cacheLookups(lookup func(account string) string) func(account string) string {
+	var mu sync.Mutex
+	found := make(map[string]string)
+	return func(account string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := found[account]; ok {
+			return v
+		}
+		v := lookup(account)
+		found[account] = v
+		return v
+	}
+}
+
+// readKeyring returns the secret stored for account, or "" if there isn't
+// one or the keyring can't be read.
+func readKeyring(account string) string {
+	args, stdin, err := keyringCommand(runtime.GOOS, "get", account, "")
+	if err != nil {
+		return ""
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return ""
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(out), "\r\n")
+}
+
+// writeKeyring stores secret for account, replacing any earlier one.
+func writeKeyring(account, secret string) error {
+	args, stdin, err := keyringCommand(runtime.GOOS, "set", account, secret)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", args[0], msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// keyringCommand builds the command that gets or sets account's secret on
+// goos, and what to write to its stdin. Secrets go through stdin so they
+// don't show up in the process list: macOS's security tool reads its
+// command from stdin (-i), and secret-tool (libsecret, Linux) reads the
+// secret.
+func keyringCommand(goos, op, account, secret string) (args []string, stdin string, err error) {
+	switch goos {
+	case "darwin":
+		if op == "get" {
+			return []string{"security", "find-generic-password", "-s", keyringService, "-a", account, "-w"}, "", nil
+		}
+		return []string{"security", "-i"},
+			fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringService, account, shellQuote(secret)), nil
+	case "windows":
+		return nil, "", fmt.Errorf("the keyring isn't supported on Windows; use environment variables")
+	}
+	if op == "get" {
+		return []string{"secret-tool", "lookup", "service", keyringService, "account", account}, "", nil
+	}
+	return []string{"secret-tool", "store", "--label", keyringService + " " + account, "service", keyringService, "account", account}, secret, nil
+}
+
+// shellQuote single-quotes s for the security tool's command parser.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
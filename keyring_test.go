@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stubKeyring stands in for the OS keyring for the rest of the test.
+func stubKeyring(t *testing.T, secrets map[string]string) {
+	t.Helper()
+	orig := keyringLookup
+	keyringLookup = func(account string) string { return secrets[account] }
+	t.Cleanup(func() { keyringLookup = orig })
+}
+
+func TestCredentialsFromKeyring(t *testing.T) {
+	stubKeyring(t, map[string]string{
+		envAppSecret:    "kr-secret",
+		envRefreshToken: "kr-refresh",
+	})
+	t.Setenv(envAppKey, "key")
+	t.Setenv(envAppSecret, "")
+	t.Setenv(envRefreshToken, "env-refresh")
+
+	// The environment wins; the keyring fills in what it leaves out.
+	k, s, r, err := credentials()
+	if err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+	if k != "key" || s != "kr-secret" || r != "env-refresh" {
+		t.Errorf("got (%q, %q, %q), want (key, kr-secret, env-refresh)", k, s, r)
+	}
+}
+
+func TestCacheLookups(t *testing.T) {
+	calls := 0
+	lookup := cacheLookups(func(account string) string {
+		calls++
+		if account == envAppKey {
+			return "key"
+		}
+		return ""
+	})
+	for range 3 {
+		if lookup(envAppKey) != "key" || lookup(envAppSecret) != "" {
+			t.Fatal("cached lookups should return what the keyring did")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("the keyring was read %d times, want once per account", calls)
+	}
+}
+
+func TestKeyringCommand(t *testing.T) {
+	tests := []struct {
+		goos, op  string
+		wantArgs  []string
+		wantStdin string
+	}{
+		{"darwin", "get", []string{"security", "find-generic-password", "-s", "dbox", "-a", "ACCT", "-w"}, ""},
+		{"darwin", "set", []string{"security", "-i"}, "add-generic-password -U -s dbox -a ACCT -w 'it'\"'\"'s'\n"},
+		{"linux", "get", []string{"secret-tool", "lookup", "service", "dbox", "account", "ACCT"}, ""},
+		{"linux", "set", []string{"secret-tool", "store", "--label", "dbox ACCT", "service", "dbox", "account", "ACCT"}, "it's"},
+	}
+	for _, tt := range tests {
+		args, stdin, err := keyringCommand(tt.goos, tt.op, "ACCT", "it's")
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.goos, tt.op, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) || stdin != tt.wantStdin {
+			t.Errorf("%s %s = %q, %q; want %q, %q", tt.goos, tt.op, args, stdin, tt.wantArgs, tt.wantStdin)
+		}
+	}
+	if _, _, err := keyringCommand("windows", "get", "ACCT", ""); err == nil {
+		t.Error("expected an error on Windows")
+	}
+}
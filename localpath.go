@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Permissions downloads get unless file_mode and dir_mode say otherwise.
+const (
+	defaultFileMode permMode = 0644
+	defaultDirMode  permMode = 0755
+)
+
+// permMode is a permission setting, written in octal in the settings file
+// ("0600", "600", or "0o600") however YAML would read the number.
+type permMode os.FileMode
+
+func (p *permMode) UnmarshalYAML(value *yaml.Node) error {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value.Value, "0o"), "0O")
+	mode, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil || mode > 0777 {
+		return fmt.Errorf("%q isn't an octal permission like 0644", value.Value)
+	}
+	*p = permMode(mode)
+	return nil
+}
+
+// localDownloadPath maps a Dropbox path to where it is written under
+// downloadDir. The path comes from the server, so it is never trusted: after
+// cleaning, anything that would land outside downloadDir (via ".." segments or
+// a symlink inside downloadDir pointing elsewhere) is rejected.
+func localDownloadPath(downloadDir, dropboxPath string) (string, error) {
+	base := filepath.Clean(downloadDir)
+	local := filepath.Join(base, localRelPath(dropboxPath, runtime.GOOS == "windows"))
+	if !withinDir(base, local) {
+		return "", fmt.Errorf("refusing to write %q outside the download directory", dropboxPath)
+	}
+
+	// Follow any symlinks already on disk. Only the deepest existing ancestor
+	// can be resolved; the rest of the path will be created beneath it.
+	realBase, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return local, nil // download dir doesn't exist yet, so it can't hold links
+	}
+	existing := local
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve %s: %w", existing, err)
+	}
+	if !withinDir(realBase, realExisting) {
+		return "", fmt.Errorf("refusing to write %q through a symlink leaving the download directory", dropboxPath)
+	}
+	return local, nil
+}
+
+// extensionFolder names the folder organize_by_extension puts a file in: its
+// extension in lower case ("jpg" for "IMG_1.JPG"), or "other" when it has
+// none. A dotfile's leading dot doesn't start an extension.
+func extensionFolder(name string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimLeft(name, ".")), "."))
+	if ext == "" {
+		return "other"
+	}
+	return ext
+}
+
+// renameCollisions finds files in items that would be saved over one listed
+// before them, which can happen once downloads no longer mirror their Dropbox
+// folders (organize_by_extension), or over one idx (which may be nil) knows an
+// earlier job downloaded from another Dropbox path. Each gets its parent
+// folder's name added, as in "report (2023).pdf", then a number too if that's
+// taken. It returns the new local paths by Dropbox path. Names are compared
+// ignoring case, as macOS and Windows do.
+func renameCollisions(items []FileItem, config *Config, idx *downloadIndex) map[string]string {
+	renamed := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, item := range items {
+		if item.IsFolder {
+			continue
+		}
+		local, err := itemLocalPath(config, item)
+		if err != nil {
+			continue
+		}
+		if alt := claimLocalPath(local, item, taken, idx); alt != local {
+			renamed[item.Path] = alt
+		}
+	}
+	return renamed
+}
+
+// claimLocalPath returns local, or the renamed path renameCollisions would
+// use for item if local is already in taken or held by another Dropbox path
+// in idx (which may be nil), and marks the result taken.
+func claimLocalPath(local string, item FileItem, taken map[string]bool, idx *downloadIndex) string {
+	if !taken[strings.ToLower(local)] && !idx.heldByOther(local, item.Path) {
+		taken[strings.ToLower(local)] = true
+		return local
+	}
+	label := path.Base(path.Dir(item.Path))
+	if label == "/" || label == "." {
+		label = ""
+	} else if runtime.GOOS == "windows" {
+		label = windowsSafeName(label)
+	}
+	alt := withNameSuffix(local, label)
+	for n := 2; alt == "" || taken[strings.ToLower(alt)] || idx.heldByOther(alt, item.Path); n++ {
+		alt = withNameSuffix(local, strings.TrimSpace(fmt.Sprintf("%s %d", label, n)))
+	}
+	taken[strings.ToLower(alt)] = true
+	return alt
+}
+
+// withNameSuffix adds " (suffix)" to the file name in local, ahead of its
+// extension. An empty suffix gives "", so callers fall back to a number.
+func withNameSuffix(local, suffix string) string {
+	if suffix == "" {
+		return ""
+	}
+	ext := filepath.Ext(local)
+	return strings.TrimSuffix(local, ext) + " (" + suffix + ")" + ext
+}
+
+// Path length limits, counted in bytes (UTF-16 units on Windows).
+const (
+	maxNameLen        = 255   // one file or folder name, everywhere
+	maxPathLen        = 4095  // PATH_MAX less its terminating NUL, on Linux
+	maxPathLenDarwin  = 1023  // likewise on macOS
+	maxPathLenWindows = 259   // MAX_PATH less the NUL, without the \\?\ prefix
+	maxPathLenLong    = 32766 // with the \\?\ prefix
+)
+
+// fitPathLimits checks that local, and the .part file written beside it
+// while it downloads, are short enough for goos to create. A Windows path
+// over MAX_PATH gets the \\?\ prefix, which lifts the limit; anything that
+// still doesn't fit is an error naming the limit, rather than the cryptic
+// failure writing it would give.
+func fitPathLimits(local, goos string) (string, error) {
+	windows := goos == "windows"
+	length := func(s string) int {
+		if windows {
+			return len(utf16.Encode([]rune(s)))
+		}
+		return len(s)
+	}
+	isSep := func(r rune) bool { return r == '/' || windows && r == '\\' }
+
+	names := strings.FieldsFunc(local, isSep)
+	for i, name := range names {
+		n := length(name)
+		if i == len(names)-1 {
+			n += len(partSuffix)
+		}
+		if n > maxNameLen {
+			return "", fmt.Errorf("name %q is too long to save here (%d bytes; the limit is %d)", name, n, maxNameLen)
+		}
+	}
+
+	limit := maxPathLen
+	switch goos {
+	case "darwin":
+		limit = maxPathLenDarwin
+	case "windows":
+		limit = maxPathLenWindows
+		if length(local)+len(partSuffix) > limit {
+			local = windowsLongPath(local)
+		}
+		if strings.HasPrefix(local, `\\?\`) {
+			limit = maxPathLenLong
+		}
+	}
+	if n := length(local) + len(partSuffix); n > limit {
+		return "", fmt.Errorf("local path is too long to save (%d bytes; the limit is %d): %s", n, limit, local)
+	}
+	return local, nil
+}
+
+// windowsLongPath adds the \\?\ prefix to an absolute Windows path, which
+// turns off its MAX_PATH limit: C:\x becomes \\?\C:\x and \\server\share\x
+// becomes \\?\UNC\server\share\x. Other paths are returned unchanged.
+func windowsLongPath(p string) string {
+	switch {
+	case strings.HasPrefix(p, `\\?\`):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return `\\?\UNC\` + p[2:]
+	case len(p) >= 3 && p[1] == ':' && p[2] == '\\':
+		return `\\?\` + p
+	}
+	return p
+}
+
+// localRelPath converts a slash-separated Dropbox path to a relative local one.
+// Dropbox allows names Windows doesn't, so on Windows each segment is also
+// made safe with windowsSafeName.
+func localRelPath(dropboxPath string, windows bool) string {
+	segments := strings.Split(dropboxPath, "/")
+	if windows {
+		for i, s := range segments {
+			segments[i] = windowsSafeName(s)
+		}
+	}
+	return filepath.Join(segments...)
+}
+
+// windowsReservedNames are device names Windows won't create files as, with
+// or without an extension ("con.txt" is reserved too).
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsSafeName makes one path segment valid on Windows: characters it
+// forbids (<>:"\|?* and control characters) become "_", trailing dots and
+// spaces (which Windows silently drops) become "_", and reserved device names
+// get a "_" prefix. "." and ".." are left for the escape check to judge.
+func windowsSafeName(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if trimmed := strings.TrimRight(name, ". "); len(trimmed) < len(name) {
+		name = trimmed + strings.Repeat("_", len(name)-len(trimmed))
+	}
+	stem, _, _ := strings.Cut(name, ".")
+	if windowsReservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		name = "_" + name
+	}
+	return name
+}
+
+// withinDir reports whether path is dir itself or lies beneath it. Both must be
+// clean paths.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalDownloadPath(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		name    string
+		path    string
+		want    string // relative to dir; ignored when wantErr
+		wantErr bool
+	}{
+		{name: "nested file", path: "/photos/a.jpg", want: "photos/a.jpg"},
+		{name: "root", path: "", want: "."},
+		{name: "dot segments that stay inside", path: "/photos/../docs/b.txt", want: "docs/b.txt"},
+		{name: "parent escape", path: "/../outside.txt", wantErr: true},
+		{name: "deep escape", path: "/a/b/../../../../etc/passwd", wantErr: true},
+		{name: "bare dotdot", path: "..", wantErr: true},
+		{name: "sibling with shared prefix", path: "/../" + filepath.Base(dir) + "-evil/x", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := localDownloadPath(dir, tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := filepath.Join(dir, filepath.FromSlash(tc.want)); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLocalDownloadPathSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if _, err := localDownloadPath(dir, "/link/secret.txt"); err == nil {
+		t.Error("expected an error writing through a symlink that leaves the download dir")
+	}
+	if _, err := localDownloadPath(dir, "/plain/file.txt"); err != nil {
+		t.Errorf("unexpected error for a normal path: %v", err)
+	}
+}
+
+func TestWindowsSafeName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"report.pdf", "report.pdf"},
+		{"a:b.txt", "a_b.txt"},
+		{"what?.txt", "what_.txt"},
+		{`<x>|"y"*\z`, "_x___y___z"},
+		{"tab\there", "tab_here"},
+		{"trailing.", "trailing_"},
+		{"spaces  ", "spaces__"},
+		{"CON", "_CON"},
+		{"con.txt", "_con.txt"},
+		{"Com1.tar.gz", "_Com1.tar.gz"},
+		{"LPT10", "LPT10"},
+		{"console.log", "console.log"},
+		{"..", ".."},
+	}
+	for _, tt := range tests {
+		if got := windowsSafeName(tt.in); got != tt.want {
+			t.Errorf("windowsSafeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLocalRelPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		windows bool
+		want    string // slash-separated
+	}{
+		{"/photos/a.jpg", false, "photos/a.jpg"},
+		{"/notes/what?.txt", false, "notes/what?.txt"},
+		{"/notes/what?.txt", true, "notes/what_.txt"},
+		{"/aux/2024: q1/con.md", true, "_aux/2024_ q1/_con.md"},
+		{"", true, ""},
+	}
+	for _, tt := range tests {
+		want := filepath.FromSlash(tt.want)
+		if got := localRelPath(tt.path, tt.windows); got != want {
+			t.Errorf("localRelPath(%q, %v) = %q, want %q", tt.path, tt.windows, got, want)
+		}
+	}
+}
+
+func TestFitPathLimits(t *testing.T) {
+	long := func(sep string, segments int) string {
+		return strings.Repeat(sep+strings.Repeat("d", 99), segments)
+	}
+	tests := []struct {
+		name    string
+		local   string
+		goos    string
+		want    string // "" for the input unchanged
+		wantErr bool
+	}{
+		{"short", "/home/me/.dbox/a.txt", "linux", "", false},
+		{"name too long", "/home/me/.dbox/" + strings.Repeat("n", 251), "linux", "", true},
+		{"name fits with .part", "/home/me/.dbox/" + strings.Repeat("n", 250), "linux", "", false},
+		{"under PATH_MAX", long("/", 40), "linux", "", false},
+		{"over PATH_MAX", long("/", 41), "linux", "", true},
+		{"over macOS limit", long("/", 11), "darwin", "", true},
+		{"windows short", `C:\Users\me\.dbox\a.txt`, "windows", "", false},
+		{"windows drive", `C:` + long(`\`, 3), "windows", `\\?\C:` + long(`\`, 3), false},
+		{"windows UNC", `\\nas\share` + long(`\`, 3), "windows", `\\?\UNC\nas\share` + long(`\`, 3), false},
+		{"windows relative", `dbox` + long(`\`, 3), "windows", "", true},
+		{"windows counts UTF-16", `C:\` + strings.Repeat("😀", 126), "windows", "", true},
+	}
+	for _, tt := range tests {
+		got, err := fitPathLimits(tt.local, tt.goos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		want := tt.want
+		if want == "" && !tt.wantErr {
+			want = tt.local
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestDownloadFilesSkipsTooLongPaths(t *testing.T) {
+	dir := t.TempDir()
+	dbx := &fakeDownloadClient{content: "hello"}
+	items := []FileItem{
+		{Name: "long", Path: "/" + strings.Repeat("n", 300), Size: 5},
+		{Name: "a.txt", Path: "/a.txt", Size: 5},
+	}
+	result := downloadFiles(dbx, items, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()), nil)
+
+	if len(result.Downloaded) != 1 || result.Downloaded[0].Path != "/a.txt" {
+		t.Errorf("downloaded = %+v, want only a.txt", result.Downloaded)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Err, "too long") {
+		t.Errorf("errors = %+v, want one about the long name", result.Errors)
+	}
+}
+
+func TestRenameCollisionsAcrossJobs(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{DownloadPath: dir, OrganizeByExtension: true, SkipExisting: skipIfSize}
+	idx := &downloadIndex{entries: map[string]indexEntry{}}
+	dbx := &fakeDownloadClient{content: "hello"}
+	b := FileItem{Name: "report.pdf", Path: "/b/report.pdf", Size: 5, ContentHash: "hb"}
+	a := FileItem{Name: "report.pdf", Path: "/a/report.pdf", Size: 5, ContentHash: "ha"}
+
+	downloadFiles(dbx, []FileItem{b}, config, newDownloadProgress(time.Now()), idx)
+	result := downloadFiles(dbx, []FileItem{a}, config, newDownloadProgress(time.Now()), idx)
+
+	want := filepath.Join(dir, "pdf", "report (a).pdf")
+	if len(result.Downloaded) != 1 || result.Renamed[a.Path] != want {
+		t.Fatalf("result = %+v, want /a/report.pdf saved as %s", result, want)
+	}
+	if idx.entries[filepath.Join(dir, "pdf", "report.pdf")].Path != b.Path || idx.entries[want].Path != a.Path {
+		t.Errorf("index = %v, want each file recorded under its own local path", idx.entries)
+	}
+
+	// The earlier job's file downloads to where it already is.
+	result = downloadFiles(dbx, []FileItem{b}, config, newDownloadProgress(time.Now()), idx)
+	if len(result.Renamed) != 0 || len(result.Skipped) != 1 {
+		t.Errorf("result = %+v, want /b/report.pdf skipped in place", result)
+	}
+}
+
+func TestExtensionFolder(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"a.jpg", "jpg"},
+		{"IMG_1.JPG", "jpg"},
+		{"archive.tar.gz", "gz"},
+		{"README", "other"},
+		{".env", "other"},
+		{".config.yaml", "yaml"},
+	}
+	for _, tt := range tests {
+		if got := extensionFolder(tt.in); got != tt.want {
+			t.Errorf("extensionFolder(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOrganizeByExtension(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{DownloadPath: dir, PaperFormat: "markdown", OrganizeByExtension: true}
+
+	tests := []struct {
+		item FileItem
+		want string // relative to dir
+	}{
+		{FileItem{Path: "/photos/2024/a.JPG"}, "jpg/a.JPG"},
+		{FileItem{Path: "/docs/LICENSE"}, "other/LICENSE"},
+		{FileItem{Path: "/docs/notes.paper", Exportable: true}, "md/notes.md"},
+		{FileItem{Path: "/photos", IsFolder: true}, "."},
+	}
+	for _, tt := range tests {
+		got, err := itemLocalPath(config, tt.item)
+		if err != nil {
+			t.Fatalf("itemLocalPath(%s): %v", tt.item.Path, err)
+		}
+		if want := filepath.Join(dir, filepath.FromSlash(tt.want)); got != want {
+			t.Errorf("itemLocalPath(%s) = %q, want %q", tt.item.Path, got, want)
+		}
+	}
+}
+
+func TestRenameCollisions(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{DownloadPath: dir, OrganizeByExtension: true}
+	items := []FileItem{
+		{Path: "/work"},
+		{Path: "/work/report.pdf"},
+		{Path: "/2023/report.pdf"},
+		{Path: "/archive/2023/Report.PDF"}, // same parent name as the one before
+		{Path: "/notes.txt"},
+		{Path: "/a/notes.txt"},
+	}
+	items[0].IsFolder = true
+
+	got := renameCollisions(items, config, nil)
+	want := map[string]string{
+		"/2023/report.pdf":         filepath.Join(dir, "pdf", "report (2023).pdf"),
+		"/archive/2023/Report.PDF": filepath.Join(dir, "pdf", "Report (2023 2).PDF"),
+		"/a/notes.txt":             filepath.Join(dir, "txt", "notes (a).txt"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("renamed = %v, want %v", got, want)
+	}
+	for p, local := range want {
+		if got[p] != local {
+			t.Errorf("renamed[%s] = %q, want %q", p, got[p], local)
+		}
+	}
+
+	// Mirroring the Dropbox tree never collides.
+	config.OrganizeByExtension = false
+	if got := renameCollisions(items[:3], config, nil); len(got) != 0 {
+		t.Errorf("mirrored downloads renamed %v", got)
+	}
+}
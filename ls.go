@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// runLs implements `dbox ls [--recursive] [--json] <path>`: it prints a
+// folder's contents (or a single file) to stdout without the TUI.
+func runLs(args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	recursive := fs.Bool("recursive", false, "list subfolders' contents too")
+	asJSON := fs.Bool("json", false, "print a JSON array instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox ls [--recursive] [--json] [path]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one path")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return err
+	}
+	items, err := listPath(dbx, fs.Arg(0), *recursive, config)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeManifest(os.Stdout, "json", items)
+	}
+	return writeListing(os.Stdout, items, *recursive, config.BinaryUnits)
+}
+
+// listPath returns the entries of the folder at p, sorted for display, or the
+// file itself if p is a file. A recursive listing keeps each folder's
+// contents right after it, listed with config's list_concurrency.
+func listPath(dbx files.Client, p string, recursive bool, config *Config) ([]FileItem, error) {
+	item, err := lookupFileItem(dbx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", p, explainTeamError(err))
+	}
+	if !item.IsFolder {
+		return []FileItem{item}, nil
+	}
+	if recursive {
+		return getAllFilesInFolder(dbx, item.Path, config.ListConcurrency)
+	}
+	items, err := listFolderEntries(dbx, item.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", p, explainTeamError(err))
+	}
+	sortEntries(items, config.FoldersFirst)
+	return items, nil
+}
+
+// writeListing prints items as a table of type, size, and name (the full path
+// for a recursive listing, so nested entries can be told apart). Sizes are in
+// binary units when binary is set.
+func writeListing(w io.Writer, items []FileItem, fullPaths, binary bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, item := range items {
+		kind, size, name := "file", formatSize(item.Size, binary), item.Name
+		if fullPaths {
+			name = item.displayPath()
+		}
+		if item.IsFolder {
+			kind, size, name = "folder", "-", name+"/"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", kind, size, name)
+	}
+	return tw.Flush()
+}
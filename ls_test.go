@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+func TestListPath(t *testing.T) {
+	dbx := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"":      {fakeFile("/b.txt"), fakeFolder("/docs"), fakeFile("/a.txt")},
+		"/docs": {fakeFile("/docs/c.txt")},
+	}}
+	paths := func(items []FileItem) (out []string) {
+		for _, item := range items {
+			out = append(out, item.Path)
+		}
+		return out
+	}
+
+	items, err := listPath(dbx, "/", false, &Config{FoldersFirst: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(items); len(got) != 3 || got[0] != "/docs" || got[1] != "/a.txt" {
+		t.Errorf("folders first: got %v", got)
+	}
+
+	items, err = listPath(dbx, "", true, &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(items); len(got) != 4 || got[2] != "/docs" || got[3] != "/docs/c.txt" {
+		t.Errorf("recursive: got %v", got)
+	}
+}
+
+func TestWriteListing(t *testing.T) {
+	items := []FileItem{
+		{Name: "docs", Path: "/docs", DisplayPath: "/Docs", IsFolder: true},
+		{Name: "a.txt", Path: "/docs/a.txt", DisplayPath: "/Docs/a.txt", Size: 2048},
+	}
+	var out bytes.Buffer
+	if err := writeListing(&out, items, false, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "folder  -        docs/\nfile    2.0 KiB  a.txt\n"
+	if out.String() != want {
+		t.Errorf("listing:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeListing(&out, items, true, true)
+	if want := "folder  -        /Docs/\nfile    2.0 KiB  /Docs/a.txt\n"; out.String() != want {
+		t.Errorf("recursive listing:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
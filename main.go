@@ -8,21 +8,101 @@ import (
 )
 
 func main() {
+	configureColor()
+
+	for _, warning := range credentialWarnings() {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	// --no-altscreen can go anywhere on the command line; the TUI then draws
+	// in the normal screen, so its last frame stays in the scrollback.
+	args, noAltScreen := takeFlag(os.Args[1:], "--no-altscreen")
+	// --no-cache, likewise, lists every folder afresh as it's opened.
+	args, noCache := takeFlag(args, "--no-cache")
+
 	// `dbox login` runs the one-time OAuth flow and exits.
-	if len(os.Args) >= 2 && os.Args[1] == "login" {
-		if err := runLogin(); err != nil {
+	if len(args) >= 1 && args[0] == "login" {
+		if err := runLogin(args[1:]); err != nil {
 			fmt.Printf("Login failed: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
+	// `dbox download <path>...` downloads without the TUI, for scripting.
+	if len(args) >= 1 && args[0] == "download" {
+		if err := runDownload(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Download failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// `dbox ls [path]` prints a folder's contents, for scripting.
+	if len(args) >= 1 && args[0] == "ls" {
+		if err := runLs(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Listing failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// `dbox dupes [path]` reports files with the same contents.
+	if len(args) >= 1 && args[0] == "dupes" {
+		if err := runDupes(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Duplicate search failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// `dbox cat <path>` streams a file to stdout, for piping.
+	if len(args) >= 1 && args[0] == "cat" {
+		if err := runCat(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Cat failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// `dbox transfer <path>... <dest>` copies into another account.
+	if len(args) >= 1 && args[0] == "transfer" {
+		if err := runTransfer(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Transfer failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	config, err := LoadConfig()
 	if err != nil {
 		fmt.Printf("Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// `dbox link <url>` browses a shared link instead of the account.
+	linkMode := len(args) >= 1 && args[0] == "link"
+	if linkMode {
+		link, err := runLink(args[1:], config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Shared link failed: %v\n", err)
+			os.Exit(1)
+		}
+		if link == nil {
+			return // a file link, already downloaded
+		}
+		config.link = link
+	}
+
+	if linkMode {
+		dir, err := localDownloadPath(linkDownloadDir(config.DownloadPath), "/"+config.link.Name)
+		if err != nil {
+			fmt.Printf("Error choosing download directory: %v\n", err)
+			os.Exit(1)
+		}
+		config.DownloadPath = dir
+	}
+
 	// All other modes need credentials in the environment.
 	if _, _, _, err := credentials(); err != nil {
 		fmt.Println(err)
@@ -32,25 +112,60 @@ func main() {
 	// With a config-file argument we enter management mode (push local files
 	// up to Dropbox); otherwise we open the browse/download TUI.
 	var m tea.Model
-	if len(os.Args) >= 2 {
-		m = newManageProgram(config, os.Args[1])
+	var opts []tea.ProgramOption
+	if noCache {
+		config.DisableCache = true
+	}
+	if config.AltScreen && !noAltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if len(args) >= 1 && !linkMode {
+		m = newManageProgram(config, args[0])
 	} else {
 		// Ensure download directory exists
 		if err := config.EnsureDownloadPath(); err != nil {
 			fmt.Printf("Error creating download directory: %v\n", err)
 			os.Exit(1)
 		}
-		m = initialModel(config)
+		model := initialModel(config)
+		// Offer to finish a download job an earlier run didn't. The queue
+		// holds account paths, so a shared link leaves it for a normal run.
+		if !linkMode {
+			if queue, err := loadDownloadQueue(); err != nil {
+				fmt.Fprintf(os.Stderr, "Ignoring download queue: %v\n", err)
+			} else if queue != nil {
+				model.offerResume(queue)
+			}
+		}
+		m = model
+		if config.RefreshOnFocus {
+			opts = append(opts, tea.WithReportFocus())
+		}
 	}
 
 	// Create and run the program
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, opts...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// takeFlag removes every occurrence of flag from args and reports whether
+// there was one.
+func takeFlag(args []string, flag string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, a := range args {
+		if a == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, found
+}
+
 // newManageProgram loads the management-mode config and current directory,
 // exiting with a clear message on any error before the TUI starts.
 func newManageProgram(config *Config, configPath string) tea.Model {
@@ -23,13 +23,13 @@ const (
 // loadCollaboratorsCmd reads the folder's current Dropbox membership and diffs
 // it against the configured collaborators. It is strictly read-only: it never
 // creates or shares the folder.
-func loadCollaboratorsCmd(cfg *DboxConfig) tea.Cmd {
+func loadCollaboratorsCmd(config *Config, cfg *DboxConfig) tea.Cmd {
 	return func() tea.Msg {
-		fc, err := newFilesClient()
+		fc, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
-		sc, err := newSharingClient()
+		sc, err := newSharingClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
@@ -60,13 +60,13 @@ func loadCollaboratorsCmd(cfg *DboxConfig) tea.Cmd {
 // configured collaborators: it shares the folder if needed, adds anyone
 // missing (as editor), and removes anyone present who isn't in the config. The
 // owner is never removed.
-func reconcileCollaboratorsCmd(cfg *DboxConfig) tea.Cmd {
+func reconcileCollaboratorsCmd(config *Config, cfg *DboxConfig) tea.Cmd {
 	return func() tea.Msg {
-		fc, err := newFilesClient()
+		fc, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
-		sc, err := newSharingClient()
+		sc, err := newSharingClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
@@ -79,9 +79,9 @@ func scanLocalFiles(cwd string, cfg *DboxConfig) ([]ManageFileItem, error) {
 // checkSyncStatusCmd determines each file's sync state relative to the remote
 // folder so the list reflects what's already uploaded on launch. It is
 // read-only (only GetMetadata + local hashing).
-func checkSyncStatusCmd(cfg *DboxConfig, items []ManageFileItem) tea.Cmd {
+func checkSyncStatusCmd(config *Config, cfg *DboxConfig, items []ManageFileItem) tea.Cmd {
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
+		dbx, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
@@ -103,16 +103,17 @@ func checkSyncStatusCmd(cfg *DboxConfig, items []ManageFileItem) tea.Cmd {
 		return SyncStatusMsg{
 			Statuses:   statuses,
 			Errors:     errs,
-			RemoteOnly: remoteOnlyFiles(dbx, cfg, localRel),
+			RemoteOnly: remoteOnlyFiles(dbx, cfg, localRel, config.ListConcurrency),
 		}
 	}
 }
 
 // remoteOnlyFiles lists files in the remote folder (of the configured types)
 // that have no local counterpart. Comparison is case-insensitive, matching
-// Dropbox. Returns nil if the remote folder doesn't exist yet.
-func remoteOnlyFiles(dbx files.Client, cfg *DboxConfig, localRel map[string]bool) []ManageFileItem {
-	remoteFiles, err := getAllFilesInFolder(dbx, cfg.Remote)
+// Dropbox. Returns nil if the remote folder doesn't exist yet. concurrency is
+// passed to getAllFilesInFolder.
+func remoteOnlyFiles(dbx files.Client, cfg *DboxConfig, localRel map[string]bool, concurrency int) []ManageFileItem {
+	remoteFiles, err := getAllFilesInFolder(dbx, cfg.Remote, concurrency)
 	if err != nil {
 		return nil
 	}
@@ -142,15 +143,18 @@ func remoteOnlyFiles(dbx files.Client, cfg *DboxConfig, localRel map[string]bool
 // downloadRemoteFileCmd downloads a remote-only file into the local folder at
 // the matching relative path, creating parent directories as needed. The file
 // is streamed to disk so large files don't load into memory.
-func downloadRemoteFileCmd(cfg *DboxConfig, cwd string, item ManageFileItem) tea.Cmd {
+func downloadRemoteFileCmd(config *Config, cfg *DboxConfig, cwd string, item ManageFileItem) tea.Cmd {
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
+		dbx, err := newFilesClient(config)
 		if err != nil {
 			return RemoteDownloadedMsg{Rel: item.Rel, Err: err.Error()}
 		}
 
 		remotePath := cfg.Remote + "/" + item.Rel
-		localPath := filepath.Join(cwd, filepath.FromSlash(item.Rel))
+		localPath, err := localDownloadPath(cwd, item.Rel)
+		if err != nil {
+			return RemoteDownloadedMsg{Rel: item.Rel, Err: err.Error()}
+		}
 		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
 			return RemoteDownloadedMsg{Rel: item.Rel, Err: err.Error()}
 		}
@@ -210,15 +214,15 @@ func remoteFileState(dbx files.Client, localPath, remotePath string, localSize i
 // pushFilesCmd uploads each file to the configured remote folder, skipping any
 // whose content already matches what's on Dropbox. It mirrors downloadFilesCmd:
 // the whole batch runs synchronously and reports a single completion message.
-func pushFilesCmd(cfg *DboxConfig, items []ManageFileItem) tea.Cmd {
+func pushFilesCmd(config *Config, cfg *DboxConfig, items []ManageFileItem) tea.Cmd {
 	return func() tea.Msg {
-		dbx, err := newFilesClient()
+		dbx, err := newFilesClient(config)
 		if err != nil {
 			return ErrorMsg{Error: err.Error()}
 		}
 
 		if err := ensureRemoteFolder(dbx, cfg.Remote); err != nil {
-			return ErrorMsg{Error: fmt.Sprintf("Failed to create remote folder %s: %v", cfg.Remote, err)}
+			return ErrorMsg{Error: fmt.Sprintf("Failed to create remote folder %s: %v", cfg.Remote, explainTeamError(err))}
 		}
 
 		var uploaded, skipped, errs []string
@@ -305,11 +309,16 @@ func uploadFileSession(dbx files.Client, localPath, remotePath, contentHash stri
 		return err
 	}
 	defer f.Close()
+	return uploadSession(dbx, f, remotePath, contentHash, size, overwriteMode())
+}
 
+// uploadSession uploads size bytes read from r to remotePath in chunks, so
+// only one chunk is held in memory at a time.
+func uploadSession(dbx files.Client, r io.Reader, remotePath, contentHash string, size int64, mode *files.WriteMode) error {
 	buf := make([]byte, uploadChunkSize)
 
 	// Start the session with the first chunk.
-	n, err := readChunk(f, buf)
+	n, err := readChunk(r, buf)
 	if err != nil {
 		return err
 	}
@@ -322,13 +331,13 @@ func uploadFileSession(dbx files.Client, localPath, remotePath, contentHash stri
 
 	// Append the remaining chunks, finishing on the last one.
 	for offset < uint64(size) {
-		n, err := readChunk(f, buf)
+		n, err := readChunk(r, buf)
 		if err != nil {
 			return err
 		}
 		cursor := files.NewUploadSessionCursor(sessionID, offset)
 		if offset+uint64(n) >= uint64(size) {
-			return finishSession(dbx, cursor, remotePath, contentHash, buf[:n])
+			return finishSession(dbx, cursor, remotePath, contentHash, mode, buf[:n])
 		}
 		appendArg := files.NewUploadSessionAppendArg(cursor)
 		if err := dbx.UploadSessionAppendV2(appendArg, bytes.NewReader(buf[:n])); err != nil {
@@ -339,14 +348,13 @@ func uploadFileSession(dbx files.Client, localPath, remotePath, contentHash stri
 
 	// Reached only when the file fit in the first chunk; finish with no data.
 	cursor := files.NewUploadSessionCursor(sessionID, offset)
-	return finishSession(dbx, cursor, remotePath, contentHash, nil)
+	return finishSession(dbx, cursor, remotePath, contentHash, mode, nil)
 }
 
-// finishSession commits an upload session at remotePath, overwriting any
-// existing file.
-func finishSession(dbx files.Client, cursor *files.UploadSessionCursor, remotePath, contentHash string, content []byte) error {
+// finishSession commits an upload session at remotePath, writing it with mode.
+func finishSession(dbx files.Client, cursor *files.UploadSessionCursor, remotePath, contentHash string, mode *files.WriteMode, content []byte) error {
 	commit := files.NewCommitInfo(remotePath)
-	commit.Mode = overwriteMode()
+	commit.Mode = mode
 	arg := files.NewUploadSessionFinishArg(cursor, commit)
 	arg.ContentHash = contentHash
 	_, err := dbx.UploadSessionFinish(arg, bytes.NewReader(content))
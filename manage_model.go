@@ -157,16 +157,15 @@ func initialManageModel(config *Config, dbox *DboxConfig, cwd string) ManageMode
 	return m
 }
 
-// Init initializes the model. It enters the alt screen, checks which files are
-// already in sync with the remote, and (when configured) loads the
-// collaborator diff.
+// Init initializes the model. It checks which files are already in sync with
+// the remote and (when configured) loads the collaborator diff.
 func (m ManageModel) Init() tea.Cmd {
-	cmds := []tea.Cmd{tea.EnterAltScreen}
+	var cmds []tea.Cmd
 	if len(m.files) > 0 {
-		cmds = append(cmds, checkSyncStatusCmd(m.dbox, m.files))
+		cmds = append(cmds, checkSyncStatusCmd(&m.config, m.dbox, m.files))
 	}
 	if m.managesCollaborators() {
-		cmds = append(cmds, loadCollaboratorsCmd(m.dbox))
+		cmds = append(cmds, loadCollaboratorsCmd(&m.config, m.dbox))
 	}
 	return tea.Batch(cmds...)
 }
@@ -214,7 +213,7 @@ func (m ManageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = max(0, len(m.files)-1)
 		}
 		if len(files) > 0 {
-			return m, checkSyncStatusCmd(m.dbox, files)
+			return m, checkSyncStatusCmd(&m.config, m.dbox, files)
 		}
 		return m, nil
 	case UploadCompleteMsg:
@@ -268,7 +267,7 @@ func (m ManageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Refresh the diff to reflect the new state.
 		m.collabLoading = true
-		return m, loadCollaboratorsCmd(m.dbox)
+		return m, loadCollaboratorsCmd(&m.config, m.dbox)
 	}
 	return m, nil
 }
@@ -360,7 +359,7 @@ func (m ManageModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.status = fmt.Sprintf("rescanned: %d file(s)", len(files))
 		m.statusTime = time.Now()
 		if len(files) > 0 {
-			return m, checkSyncStatusCmd(m.dbox, files)
+			return m, checkSyncStatusCmd(&m.config, m.dbox, files)
 		}
 	case "P":
 		local := pushableFiles(m.files)
@@ -368,7 +367,7 @@ func (m ManageModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return StatusMsg{Message: "nothing to push"} }
 		}
 		m.pushing = true
-		return m, pushFilesCmd(m.dbox, local)
+		return m, pushFilesCmd(&m.config, m.dbox, local)
 	case "C":
 		if !m.managesCollaborators() {
 			return m, func() tea.Msg { return StatusMsg{Message: "no collaborators configured"} }
@@ -377,7 +376,7 @@ func (m ManageModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil // wait for the current diff to finish loading
 		}
 		m.reconciling = true
-		return m, reconcileCollaboratorsCmd(m.dbox)
+		return m, reconcileCollaboratorsCmd(&m.config, m.dbox)
 	case "d":
 		if m.cursor >= len(m.files) {
 			return m, nil
@@ -387,7 +386,7 @@ func (m ManageModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return StatusMsg{Message: "only remote-only files can be downloaded"} }
 		}
 		m.downloading = true
-		return m, downloadRemoteFileCmd(m.dbox, m.cwd, file)
+		return m, downloadRemoteFileCmd(&m.config, m.dbox, m.cwd, file)
 	}
 	return m, nil
 }
@@ -475,7 +474,7 @@ func (m ManageModel) renderFileList() string {
 			}
 		}
 
-		line := fmt.Sprintf("%s 📄 %-40s %10s   %s", cursor, file.Rel, humanizeSize(file.Size), status)
+		line := fmt.Sprintf("%s 📄 %s %10s   %s", cursor, padWidth(file.Rel, 40), formatSize(file.Size, m.config.BinaryUnits), status)
 		s.WriteString(style.Render(line) + "\n")
 	}
 
@@ -516,7 +515,7 @@ func (m ManageModel) renderCollaborators() string {
 		if color != "" {
 			style = style.Foreground(lipgloss.Color(color))
 		}
-		line := fmt.Sprintf("  %s %-36s %s", marker, c.Email, label)
+		line := fmt.Sprintf("  %s %s %s", marker, padWidth(c.Email, 36), label)
 		s.WriteString(style.Render(line) + "\n")
 	}
 	return s.String()
@@ -567,20 +566,6 @@ func statusLabel(file ManageFileItem) (string, string) {
 	}
 }
 
-// humanizeSize formats a byte count as a short human-readable string.
-func humanizeSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
-}
-
 // renderHelpView renders the management-mode help screen.
 func (m ManageModel) renderHelpView() string {
 	var s strings.Builder
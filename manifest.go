@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// manifestEntry is one row of an exported folder listing.
+type manifestEntry struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified,omitempty"` // RFC 3339; empty for folders
+	Type     string `json:"type"`               // "file" or "folder"
+}
+
+// manifestEntries converts items into manifest rows.
+func manifestEntries(items []FileItem) []manifestEntry {
+	entries := make([]manifestEntry, 0, len(items))
+	for _, item := range items {
+		e := manifestEntry{Name: item.Name, Path: item.Path, Size: item.Size, Type: "file"}
+		if item.IsFolder {
+			// Dropbox doesn't report modification times for folders.
+			e.Type = "folder"
+		} else {
+			e.Modified = item.Modified.UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// writeManifest writes items as CSV (with a header row) or as a JSON array.
+func writeManifest(w io.Writer, format string, items []FileItem) error {
+	entries := manifestEntries(items)
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"name", "path", "size", "modified", "type"})
+		for _, e := range entries {
+			cw.Write([]string{e.Name, e.Path, strconv.FormatInt(e.Size, 10), e.Modified, e.Type})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown listing format %q", format)
+	}
+}
+
+// manifestFileName names an exported listing after the folder and the time,
+// e.g. "photos-listing-20240102-150405.csv".
+func manifestFileName(folder, format string, recursive bool, now time.Time) string {
+	name := path.Base(folder)
+	if folder == "" {
+		name = "dropbox"
+	}
+	kind := "listing"
+	if recursive {
+		kind = "tree"
+	}
+	return fmt.Sprintf("%s-%s-%s.%s", name, kind, now.Format("20060102-150405"), format)
+}
+
+// exportListingCmd writes a listing of folder to the download directory. The
+// folder's own entries are passed in; a recursive listing is fetched instead.
+func exportListingCmd(config *Config, folder string, entries []FileItem, recursive bool, format string) tea.Cmd {
+	return func() tea.Msg {
+		items := entries
+		if recursive {
+			dbx, err := newFilesClient(config)
+			if err != nil {
+				return ErrorMsg{Error: err.Error()}
+			}
+			items, err = getAllFilesInFolder(dbx, folder, config.ListConcurrency)
+			if err != nil {
+				return ErrorMsg{Error: fmt.Sprintf("Failed to list %s/: %v", folder, err)}
+			}
+		}
+
+		file := filepath.Join(config.DownloadPath, manifestFileName(folder, format, recursive, time.Now()))
+		out, err := os.Create(file)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to write listing: %v", err)}
+		}
+		if err := writeManifest(out, format, items); err != nil {
+			out.Close()
+			return ErrorMsg{Error: fmt.Sprintf("Failed to write listing: %v", err)}
+		}
+		if err := out.Close(); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to write listing: %v", err)}
+		}
+		noun := "entries"
+		if len(items) == 1 {
+			noun = "entry"
+		}
+		return StatusMsg{Message: fmt.Sprintf("Wrote %d %s to %s", len(items), noun, file)}
+	}
+}
+
+// promptExportListing asks which format to export the current folder's
+// listing in.
+func (m Model) promptExportListing(recursive bool) (tea.Model, tea.Cmd) {
+	m.exportRecursive = recursive
+	m.openPrompt(promptListingFormat)
+	return m, nil
+}
+
+// chooseListingFormat handles a key at the listing format prompt: c for CSV,
+// j for JSON. Other keys leave the prompt open.
+func (m Model) chooseListingFormat(key string) (tea.Model, tea.Cmd) {
+	var format string
+	switch key {
+	case "c":
+		format = "csv"
+	case "j":
+		format = "json"
+	default:
+		return m, nil
+	}
+	m.closePrompt()
+	return m, exportListingCmd(&m.config, m.currentPath, m.files, m.exportRecursive, format)
+}
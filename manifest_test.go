@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteManifest(t *testing.T) {
+	items := []FileItem{
+		{Name: "docs", Path: "/docs", IsFolder: true},
+		{Name: "a, b.txt", Path: "/docs/a, b.txt", Size: 42, Modified: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+	}
+
+	var buf bytes.Buffer
+	if err := writeManifest(&buf, "csv", items); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	wantCSV := "name,path,size,modified,type\n" +
+		"docs,/docs,0,,folder\n" +
+		"\"a, b.txt\",\"/docs/a, b.txt\",42,2024-01-02T15:04:05Z,file\n"
+	if buf.String() != wantCSV {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), wantCSV)
+	}
+
+	buf.Reset()
+	if err := writeManifest(&buf, "json", items); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"modified": "2024-01-02T15:04:05Z"`) || !strings.Contains(buf.String(), `"type": "folder"`) {
+		t.Errorf("json = %s", buf.String())
+	}
+}
+
+func TestManifestFileName(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	if got := manifestFileName("/photos/2024", "csv", false, now); got != "2024-listing-20240102-150405.csv" {
+		t.Errorf("got %q", got)
+	}
+	if got := manifestFileName("", "json", true, now); got != "dropbox-tree-20240102-150405.json" {
+		t.Errorf("got %q", got)
+	}
+}
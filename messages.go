@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Default status_timeout and error_timeout.
+const (
+	defaultStatusTimeout = 3 * time.Second
+	defaultErrorTimeout  = 5 * time.Second
+)
+
+// messageExpiredMsg redraws the screen once a status or error message has
+// timed out, so it disappears even if nothing else happens.
+type messageExpiredMsg struct{}
+
+// shown reports whether a message set at set is still on screen after
+// timeout; a timeout of 0 never hides it.
+func shown(set time.Time, timeout time.Duration) bool {
+	return timeout == 0 || time.Since(set) < timeout
+}
+
+// expiryCmd schedules a redraw for when a status or error message set since
+// before times out.
+func (m Model) expiryCmd(before Model) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, msg := range []struct {
+		set, was time.Time
+		timeout  time.Duration
+	}{
+		{m.statusTime, before.statusTime, m.config.StatusTimeout},
+		{m.errorTime, before.errorTime, m.config.ErrorTimeout},
+	} {
+		if msg.set.Equal(msg.was) || msg.timeout == 0 {
+			continue
+		}
+		cmds = append(cmds, tea.Tick(msg.timeout, func(time.Time) tea.Msg {
+			return messageExpiredMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
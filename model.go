@@ -2,34 +2,87 @@ package main
 
 import (
 	"fmt"
-	"net/url"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 // FileItem represents a file or folder in Dropbox
 type FileItem struct {
-	Name     string
-	Path     string
-	IsFolder bool
-	Size     int64
-	Modified time.Time
+	Name        string
+	Path        string // lowercased, as Dropbox matches paths
+	DisplayPath string // Path with its original casing
+	IsFolder    bool
+	Size        int64
+	Modified    time.Time
+	ContentHash string // Dropbox content hash of a file (see dropboxContentHash)
+	// Exportable marks Paper docs, which can't be downloaded directly and
+	// are exported to PaperFormat instead
+	Exportable bool
+	// Shared marks files shared directly with other people, as opposed to
+	// just sitting in a shared folder
+	Shared bool
 }
 
 // Model represents the application state
 type Model struct {
 	// File browser state
 	currentPath string
-	files       []FileItem
+	files       []FileItem // every entry in the current folder
+	visible     []FileItem // entries as displayed; cursor indexes into this
 	cursor      int
-	selected    map[int]bool
+	listOffset  int                 // first entry on screen (see listWindow)
+	selected    map[string]FileItem // by path; kept across folders
 
-	// Cache for folder contents
+	// Path of the entry a range selection started at (see markRange), or ""
+	markPath string
+
+	// Whether dotfiles are listed
+	showHidden bool
+
+	// Whether folders sort ahead of files (see compareEntries)
+	foldersFirst bool
+
+	// Whether entries show their full path instead of just their name
+	showFullPath bool
+
+	// Whether the list shows just names or columns of details too
+	viewMode viewMode
+
+	// Whether the folder preview panel is on, the folders previewed so far
+	// by path, and the folder being listed now (see previewCmd)
+	showPreview    bool
+	previews       map[string]folderPreview
+	previewPending string
+
+	// A preview held only while the cursor is on its folder, which isn't
+	// kept in previews (see handleFolderPreview)
+	previewLast     folderPreview
+	previewLastPath string
+
+	// The last delete, move, or duplicate, for u to undo (nil if none)
+	lastOp *undoOp
+
+	// Revision counts of files by path when show_revisions is on, and the
+	// file being counted now (see revisionCmd)
+	revisions       map[string]int
+	revisionPending string
+
+	// The current folder's next listing page, when it has more, and whether
+	// it's being fetched (see moreCmd)
+	moreCursor  string
+	loadingMore bool
+
+	// Cache for folder contents, and the cached paths from least to most
+	// recently used (see cacheFolder)
 	folderCache map[string][]FileItem
+	cacheOrder  []string
 
 	// UI state
 	width  int
@@ -38,19 +91,116 @@ type Model struct {
 	// Help view state
 	showHelp bool
 
+	// Key bindings (defaults plus the settings file's keys section)
+	keys keyMap
+
+	// Multi-key sequences (e.g. "gg"): the first key waits in pendingKey until
+	// the next key arrives or keySequenceTimeout passes. pendingSeq identifies
+	// the latest wait so stale timeouts are ignored.
+	pendingKey string
+	pendingSeq int
+
+	// Type-ahead (alt+letters): the name prefix typed so far, and a number
+	// identifying the latest letter so stale timeouts are ignored
+	typeAheadBuf string
+	typeAheadSeq int
+
+	// Count typed before a motion (the 5 in 5j); 0 when none is pending
+	count int
+
+	// Text prompt state; prompt is promptNone when no prompt is open
+	prompt      promptKind
+	promptInput string
+	promptHint  string // shown after the input, e.g. completions
+
+	// Files waiting on the Paper export format prompt before downloading
+	pendingDownload []FileItem
+	pendingShallow  bool
+
+	// Download job left over from an earlier run, while asking to resume it
+	resumeQueue *downloadQueue
+
+	// Whether the listing being exported includes subfolders
+	exportRecursive bool
+
+	// Entries waiting on the delete or archive confirmation, or the move
+	// destination prompt
+	pendingDelete  []FileItem
+	pendingMove    []FileItem
+	pendingArchive []FileItem
+
+	// The file waiting on the temporary link prompt (see promptTempLink)
+	pendingLink FileItem
+
+	// The Dropbox batch job in progress ("delete" or "move"), or "" when idle;
+	// only one runs at a time
+	job string
+
+	// Search state (see search.go): the last submitted term, for n/N and
+	// highlighting, and where the cursor was when the search began
+	searchTerm   string
+	searchOrigin int
+
 	// Status messages
 	status     string
 	statusTime time.Time
 
-	// Loading state
-	loading bool
+	// Set when a folder failed to load for lack of network (or timed out),
+	// with the folder, so refresh retries it (see handleOffline)
+	offline     bool
+	offlinePath string
+
+	// Loading state. spinning is set while spinner ticks are scheduled, and
+	// connected once the first folder has loaded. loadFailed is set when the
+	// first load failed, so the empty list says so rather than "No files".
+	loading      bool
+	spinning     bool
+	spinnerFrame int
+	connected    bool
+	loadFailed   bool
 
 	// Error state
 	error     string
 	errorTime time.Time
 
-	// Download state
-	downloading bool
+	// Download state; progress is shared with the running download job, which
+	// reports each finished file on downloadEvents for the tally
+	downloading    bool
+	progress       *downloadProgress
+	downloadEvents <-chan tea.Msg
+	tally          downloadTally
+	scan           ScanProgressMsg // how far the scan of selected folders has got
+
+	// Metadata shown in the details panel, or nil when it's closed
+	info *fileInfo
+
+	// Total sizes of folders measured with folder_size, by path, and the
+	// folder being measured now (nil if none)
+	folderSizes map[string]int64
+	sizing      *folderSizing
+
+	// Folders shared with the user, while the shared folders screen is open
+	// (nil when it's closed), and the one under its cursor
+	shared       []sharedFolder
+	sharedCursor int
+
+	// Past downloads, newest first, while the history screen is open (nil
+	// when it's closed), and the one under its cursor
+	history       []historyEntry
+	historyCursor int
+
+	// The selection screen's entries while it's open (nil otherwise)
+	cart       []FileItem
+	cartCursor int
+
+	// Results of the last download, shown until dismissed (see results.go)
+	results       *DownloadCompleteMsg
+	resultsOffset int
+
+	// The last download job started, and the entries it failed on, for r on
+	// the results screen to retry with the same options
+	lastDownload DownloadMsg
+	failed       []FileItem
 
 	// Configuration
 	config Config
@@ -78,35 +228,112 @@ type LoadingMsg struct {
 type FilesLoadedMsg struct {
 	Files []FileItem
 	Path  string
+	// Cursor is set when the listing has more pages (see loadMoreCmd)
+	Cursor string
 }
 
 // DownloadMsg represents a download operation
 type DownloadMsg struct {
 	Files []FileItem
+	// PaperFormat overrides the configured Paper export format when set
+	PaperFormat string
+	// MaxDepth overrides the max_depth setting when set
+	MaxDepth *int
+	// Resume marks a job restarted from the download queue
+	Resume bool
+	// Zip downloads folders as single .zip files (see downloadZips)
+	Zip bool
+	// Archive deletes each file from Dropbox once it's downloaded and
+	// verified (see archiveFiles)
+	Archive bool
 }
 
 // DownloadCompleteMsg represents when download is complete
 type DownloadCompleteMsg struct {
-	Downloaded []string
-	Skipped    []string
-	Errors     []string
+	Downloaded []FileItem
+	// Overwritten lists the downloaded files that replaced a file already at
+	// their local path (they're in Downloaded too).
+	Overwritten []FileItem
+	Skipped     []FileItem
+	Ignored     []FileItem // left out of folders by .dboxignore
+	TooDeep     []FileItem // folders not followed because of max_depth
+	Errors      []ItemError
+	// ErrorLog is the file the job's errors were written to, if any.
+	ErrorLog string
+	// Renamed maps the Dropbox path of each file saved under another name,
+	// so it didn't overwrite a file of the same name, to where it went.
+	Renamed map[string]string
+	// Archived lists the files an archive job deleted from Dropbox after
+	// verifying their download, and ArchivedRevs their last revisions, for
+	// undo.
+	Archived     []FileItem
+	ArchivedRevs map[string]string
+}
+
+// localPath returns where item was (or would have been) saved by the job.
+func (r DownloadCompleteMsg) localPath(config *Config, item FileItem) string {
+	if local, ok := r.Renamed[item.Path]; ok {
+		return local
+	}
+	if local, err := itemLocalPath(config, item); err == nil {
+		return local
+	}
+	return ""
+}
+
+// ItemError is a file or folder an operation (such as a download) failed on,
+// with a message describing why.
+type ItemError struct {
+	Item FileItem
+	Err  string
 }
 
+// keySequenceTimeoutMsg fires when a pending multi-key sequence has waited too
+// long for its next key.
+type keySequenceTimeoutMsg struct {
+	seq int
+}
+
+// keySequenceTimeout is how long the first key of a sequence like "gg" waits
+// for the second.
+const keySequenceTimeout = time.Second
+
+// maxCount bounds a typed count prefix so runaway digits can't overflow.
+const maxCount = 99999
+
+// minWidth and minHeight are the smallest terminal browse mode will draw in;
+// below them the layout wraps and overlaps, so View shows a notice instead.
+const (
+	minWidth  = 40
+	minHeight = 10
+)
+
 // initialModel creates a new model with default values
 func initialModel(config *Config) Model {
+	// LoadConfig has already validated the bindings; fall back to the
+	// defaults if they're somehow invalid anyway.
+	keys, err := newKeyMap(config.Keys)
+	if err != nil {
+		keys, _ = newKeyMap(nil)
+	}
+	if config.Theme == (Theme{}) {
+		config.Theme = themes[defaultTheme]
+	}
 	return Model{
-		currentPath: "",
-		files:       []FileItem{},
-		cursor:      0,
-		selected:    make(map[int]bool),
-		folderCache: make(map[string][]FileItem),
-		width:       80,
-		height:      24,
-		status:      "welcome to dbox",
-		statusTime:  time.Now(),
-		loading:     false,
-		downloading: false,
-		config:      *config,
+		keys:         keys,
+		currentPath:  "",
+		files:        []FileItem{},
+		cursor:       0,
+		selected:     make(map[string]FileItem),
+		folderCache:  make(map[string][]FileItem),
+		width:        80,
+		height:       24,
+		status:       "welcome to dbox",
+		statusTime:   time.Now(),
+		loading:      false,
+		downloading:  false,
+		foldersFirst: config.FoldersFirst,
+		config:       *config,
 	}
 }
 
@@ -117,19 +344,37 @@ func (m Model) Init() tea.Cmd {
 			// Set loading state for initial file load
 			return LoadingMsg{Loading: true}
 		},
-		loadFilesCmd(""),
-		tea.EnterAltScreen,
+		loadFilesCmd(&m.config, ""),
 	)
 }
 
-// Update handles messages and returns the updated model
+// Update handles messages and returns the updated model, scheduling a redraw
+// for when any status or error message it shows should disappear.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		nm.listOffset, _ = nm.listWindow()
+		next = nm
+		if expiry := nm.expiryCmd(m); expiry != nil {
+			cmd = tea.Batch(cmd, expiry)
+		}
+	}
+	return next, cmd
+}
+
+// update handles one message for Update.
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case messageExpiredMsg:
+		return m, nil // just redraw
 	case tea.KeyMsg:
-		if m.downloading {
-			return m, nil
+		next, cmd := m.handleKeyPress(msg)
+		if nm, ok := next.(Model); ok {
+			nm.forgetLastPreview()
+			cmd = tea.Batch(cmd, nm.revisionCmd(), nm.moreCmd(), nm.previewCmd())
+			return nm, cmd
 		}
-		return m.handleKeyPress(msg)
+		return next, cmd
 	case tea.WindowSizeMsg:
 		return m.handleWindowSize(msg)
 	case StatusMsg:
@@ -138,36 +383,207 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case ErrorMsg:
 		m.downloading = false
+		if m.loading && !m.connected {
+			m.loadFailed = true
+		}
+		m.loading = false // a failed load shouldn't spin forever
+		m.loadingMore = false
 		m.error = msg.Error
 		m.errorTime = time.Now()
 		return m, nil
 	case LoadingMsg:
 		m.loading = msg.Loading
+		if m.loading {
+			return m, m.startSpinner()
+		}
+		return m, nil
+	case spinnerTickMsg:
+		return m.handleSpinnerTick()
+	case tea.FocusMsg:
+		// Pick up changes made elsewhere (e.g. the desktop app) while dbox
+		// was in the background.
+		if !m.config.RefreshOnFocus || m.loading || m.prompt != promptNone {
+			return m, nil
+		}
+		m.forgetFolder(m.currentPath)
+		m.forgetPreviews(m.currentPath)
+		return m, m.load(m.currentPath)
+	case keySequenceTimeoutMsg:
+		if msg.seq == m.pendingSeq {
+			m.pendingKey = ""
+		}
+		return m, nil
+	case typeAheadTimeoutMsg:
+		if msg.seq == m.typeAheadSeq {
+			m.typeAheadBuf = ""
+		}
 		return m, nil
 	case FilesLoadedMsg:
-		m.files = msg.Files
-		m.currentPath = msg.Path
-		m.cursor = 0
-		m.selected = make(map[int]bool)
+		if msg.Path == m.currentPath {
+			// A reload of the folder on screen: keep the cursor on the
+			// same entry, and the selection on entries still there.
+			cursorPath := m.cursorPath()
+			m.files = msg.Files
+			m.refreshVisible()
+			m.restoreCursor(cursorPath)
+			m.pruneSelection()
+		} else {
+			m.setFiles(msg.Path, msg.Files)
+		}
 		m.loading = false
-		// Cache the loaded files
-		m.folderCache[msg.Path] = msg.Files
-		return m, nil
+		m.connected = true
+		m.loadFailed = false
+		m.offline = false
+		m.moreCursor = msg.Cursor
+		m.loadingMore = false
+		// Cache the loaded files, once they're all here
+		if msg.Cursor == "" {
+			m.cacheFolder(msg.Path, msg.Files)
+		}
+		cmd := tea.Batch(m.revisionCmd(), m.moreCmd(), m.previewCmd())
+		return m, cmd
+	case FilesMoreMsg:
+		return m.handleFilesMore(msg)
+	case OfflineMsg:
+		return m.handleOffline(msg)
+	case revisionTickMsg:
+		return m.handleRevisionTick(msg)
+	case RevisionCountMsg:
+		return m.handleRevisionCount(msg)
+	case previewTickMsg:
+		return m.handlePreviewTick(msg)
+	case PathCompletionMsg:
+		return m.handlePathCompletion(msg)
+	case FolderPreviewMsg:
+		return m.handleFolderPreview(msg)
 	case DownloadMsg:
+		m.lastDownload = msg
 		m.downloading = true
-		return m, downloadFilesCmd(msg.Files, &m.config)
+		m.progress = newDownloadProgress(time.Now())
+		m.progress.resumed = msg.Resume
+		m.tally = downloadTally{}
+		m.scan = ScanProgressMsg{}
+		config := m.config
+		if msg.PaperFormat != "" {
+			config.PaperFormat = msg.PaperFormat
+		}
+		if msg.MaxDepth != nil {
+			config.MaxDepth = msg.MaxDepth
+		}
+		events := make(chan tea.Msg, 16)
+		m.downloadEvents = events
+		return m, tea.Batch(
+			downloadFilesCmd(msg, &config, m.progress, events),
+			waitForDownloadEvent(events),
+			progressTickCmd(),
+		)
+	case FileDoneMsg:
+		m.tally.add(msg)
+		return m, waitForDownloadEvent(m.downloadEvents)
+	case ScanProgressMsg:
+		m.scan = msg
+		return m, waitForDownloadEvent(m.downloadEvents)
+	case deleteJobMsg:
+		m.status = fmt.Sprintf("Deleting %s... waiting on Dropbox (%v)",
+			pluralize(len(msg.items), "item"), time.Duration(msg.polls)*batchPollInterval)
+		m.statusTime = time.Now()
+		return m, deleteCheckCmd(&m.config, msg)
+	case DeleteCompleteMsg:
+		m.job = ""
+		m.invalidatePaths(append(msg.Deleted, itemsOf(msg.Errors)...))
+		if op := deleteUndo(msg); op != nil {
+			m.lastOp = op
+		}
+		m.status = "Deleted " + pluralize(len(msg.Deleted), "item")
+		m.statusTime = time.Now()
+		if len(msg.Errors) > 0 {
+			var errs []string
+			for _, e := range msg.Errors {
+				errs = append(errs, e.Err)
+			}
+			m.error = fmt.Sprintf("Failed to delete %d: %s", len(msg.Errors), strings.Join(errs, ", "))
+			m.errorTime = time.Now()
+		}
+		return m, m.load(m.currentPath)
+	case moveJobMsg:
+		m.status = fmt.Sprintf("Moving %s... waiting on Dropbox (%v)",
+			pluralize(len(msg.items), "item"), time.Duration(msg.polls)*batchPollInterval)
+		m.statusTime = time.Now()
+		return m, moveCheckCmd(&m.config, msg)
+	case MoveCompleteMsg:
+		m.job = ""
+		// The sources' folders and the destination both changed.
+		m.invalidatePaths(append(msg.Moved, itemsOf(msg.Errors)...))
+		m.forgetFolder(msg.Dest)
+		if op := moveUndo(msg); op != nil {
+			m.lastOp = op
+		}
+		m.status = fmt.Sprintf("Moved %s to %s/", pluralize(len(msg.Moved), "item"), msg.Dest)
+		m.statusTime = time.Now()
+		if len(msg.Errors) > 0 {
+			var errs []string
+			for _, e := range msg.Errors {
+				errs = append(errs, e.Err)
+			}
+			m.error = fmt.Sprintf("Failed to move %d: %s", len(msg.Errors), strings.Join(errs, ", "))
+			m.errorTime = time.Now()
+		}
+		return m, m.load(m.currentPath)
+	case DuplicateCompleteMsg:
+		return m.handleDuplicateComplete(msg)
+	case UndoCompleteMsg:
+		return m.handleUndoComplete(msg)
+	case FileInfoMsg:
+		return m.handleFileInfo(msg)
+	case LocalHashMsg:
+		return m.handleLocalHash(msg)
+	case HistoryMsg:
+		return m.handleHistory(msg)
+	case SharedFoldersMsg:
+		return m.handleSharedFolders(msg)
+	case FolderMountedMsg:
+		return m.handleFolderMounted(msg)
+	case FolderSizeMsg:
+		return m.handleFolderSize(msg)
+	case progressTickMsg:
+		if !m.downloading {
+			return m, nil
+		}
+		m.progress.sample(time.Now())
+		return m, progressTickCmd()
 
 	case DownloadCompleteMsg:
-		// Return to file list
+		// Show the full results until dismissed, then the file list again
+		// with a one-line summary.
 		m.downloading = false
-		message := fmt.Sprintf("Download complete. Downloaded: %d, Skipped: %d, Errors: %d",
+		m.downloadEvents = nil
+		m.results = &msg
+		m.resultsOffset = 0
+		m.failed = failedItems(msg.Errors)
+		m.status = fmt.Sprintf("Download complete. Downloaded: %d, Skipped: %d, Errors: %d",
 			len(msg.Downloaded), len(msg.Skipped), len(msg.Errors))
-		if len(msg.Errors) > 0 {
-			message += fmt.Sprintf(" - Errors: %s", strings.Join(msg.Errors, ", "))
+		if len(msg.Overwritten) > 0 {
+			m.status += fmt.Sprintf(", Overwritten: %d", len(msg.Overwritten))
+		}
+		if len(msg.Ignored) > 0 {
+			m.status += fmt.Sprintf(", Ignored: %d", len(msg.Ignored))
+		}
+		if len(msg.TooDeep) > 0 {
+			m.status += fmt.Sprintf(", Not followed: %d", len(msg.TooDeep))
+		}
+		if len(msg.Archived) > 0 {
+			m.status += fmt.Sprintf(", Archived: %d", len(msg.Archived))
+		}
+		if msg.ErrorLog != "" {
+			m.status += " (errors in " + msg.ErrorLog + ")"
 		}
-		// Store completion message in status
-		m.status = message
 		m.statusTime = time.Now()
+		if len(msg.Archived) > 0 {
+			// Archived files are gone from Dropbox: u brings them back.
+			m.invalidatePaths(msg.Archived)
+			m.lastOp = deleteUndo(DeleteCompleteMsg{Deleted: msg.Archived, Revs: msg.ArchivedRevs})
+			return m, m.load(m.currentPath)
+		}
 		return m, nil
 	}
 	return m, nil
@@ -175,40 +591,81 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the UI
 func (m Model) View() string {
+	if m.width > 0 && (m.width < minWidth || m.height < minHeight) {
+		return fmt.Sprintf("Terminal too small (need at least %dx%d)", minWidth, minHeight)
+	}
 	if m.downloading {
-		return "📥 Downloading...\n"
+		return m.renderDownloadProgress()
 	}
 	if m.width == 0 {
-		return "Loading..."
+		return m.loadingLine()
 	}
 
 	if m.showHelp {
 		return m.renderHelpView()
 	}
+	if m.results != nil {
+		return m.renderResultsView()
+	}
+	if m.info != nil {
+		return m.renderInfoView()
+	}
+	if m.history != nil {
+		return m.renderHistoryView()
+	}
+	if m.cart != nil {
+		return m.renderCartView()
+	}
+	if m.shared != nil {
+		return m.renderSharedView()
+	}
 
 	var s strings.Builder
 
 	// Current path
 	pathStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(m.config.Theme.Path)
 
 	currentPath := m.currentPath
-	s.WriteString(pathStyle.Render(currentPath+"/") + "\n\n")
+	if m.config.link != nil {
+		currentPath = "🔗 " + m.config.link.Name + currentPath
+	}
+	// Item counts after the path, when there's room for them beside it
+	header := pathStyle.Render(truncateMiddle(currentPath+"/", m.width))
+	if counts := m.countLabel(); counts != "" {
+		room := m.width - runewidth.StringWidth(counts) - 2
+		if room >= runewidth.StringWidth(currentPath+"/") || room >= minWidth/2 {
+			header = pathStyle.Render(truncateMiddle(currentPath+"/", room)) + "  " +
+				lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(counts)
+		}
+	}
+	s.WriteString(header + "\n\n")
 
 	// File list
 	if m.loading {
-		s.WriteString("Loading files...\n")
-	} else if len(m.files) == 0 {
+		s.WriteString(m.loadingLine() + "\n")
+	} else if m.loadFailed {
+		s.WriteString("⚠️  Failed to load — press " + m.keys.describe(actionRefresh) + " to retry\n")
+	} else if len(m.visible) == 0 {
 		s.WriteString("🪹 No files found\n")
 	} else {
 		fileList := m.renderFileList()
+		if m.previewShown() {
+			// The preview sits beside the list, at least as tall as it
+			// shows entries.
+			list := lipgloss.NewStyle().Width(m.listWidth()).Render(strings.TrimSuffix(fileList, "\n"))
+			height := max(lipgloss.Height(list), previewEntries+2)
+			fileList = lipgloss.JoinHorizontal(lipgloss.Top, list, m.renderPreview(height)) + "\n"
+		}
 		s.WriteString(fileList)
 	}
 
-	// Status/Error messages
-	if m.error != "" && time.Since(m.errorTime) < 5*time.Second {
+	// Prompt, or status/error messages
+	if m.prompt != promptNone {
+		s.WriteString("\n " + m.renderPrompt())
+	} else if m.error != "" && shown(m.errorTime, m.config.ErrorTimeout) {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("203")).
+			Foreground(m.config.Theme.Error).
 			Padding(0, 1)
 
 		// Wrap error message to fit terminal width
@@ -221,13 +678,17 @@ func (m Model) View() string {
 			}
 		}
 		s.WriteString("\n" + errorStyle.Render(errorText))
-	} else if m.status != "" && time.Since(m.statusTime) < 3*time.Second {
+	} else if status := m.status; m.sizing != nil || (status != "" && shown(m.statusTime, m.config.StatusTimeout)) {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("156")).
+			Foreground(m.config.Theme.Status).
 			Padding(0, 1)
 
+		// A folder being measured shows its progress in place of the status.
+		if m.sizing != nil {
+			status = m.sizingLine()
+		}
 		// Wrap status message to fit terminal width
-		statusText := "ℹ️  " + m.status
+		statusText := "ℹ️  " + status
 		if m.width > 0 {
 			// Reserve some space for padding and ensure we don't exceed terminal width
 			maxWidth := m.width - 4 // Account for padding and margins
@@ -241,65 +702,167 @@ func (m Model) View() string {
 	return s.String()
 }
 
+// renderDownloadProgress renders the downloading screen: bytes received so far
+// and, once the job's total size is known, a progress bar, speed, and ETA.
+func (m Model) renderDownloadProgress() string {
+	if m.progress == nil {
+		return "📥 Downloading...\n"
+	}
+	received := formatSize(m.progress.done(), m.config.BinaryUnits)
+	fraction, ok := m.progress.fraction()
+	if !ok {
+		// Still listing folders; the total isn't known yet.
+		s := fmt.Sprintf("📥 Downloading... %s · %s\n", received, formatRate(m.progress.rate(), m.config.BinaryUnits))
+		if m.scan.Folders > 0 {
+			s += fmt.Sprintf("Scanned %s folders, found %s files...\n", groupDigits(m.scan.Folders), groupDigits(m.scan.Files))
+		}
+		return s
+	}
+	eta, etaOK := m.progress.eta()
+	s := fmt.Sprintf("📥 Downloading... %s of %s\n%s %3.0f%%  %s  %s\n",
+		received, formatSize(m.progress.total.Load(), m.config.BinaryUnits),
+		progressBar(fraction), fraction*100, formatRate(m.progress.rate(), m.config.BinaryUnits), formatETA(eta, etaOK))
+	if files := m.progress.files.Load(); files > 0 {
+		s += fmt.Sprintf("%s/%s files\n", groupDigits(m.progress.completed.Load()), groupDigits(files))
+	}
+	if t := m.tally; t.last != "" {
+		last := t.last
+		if m.width > 0 {
+			last = truncateMiddle(last, m.width)
+		}
+		s += fmt.Sprintf("Downloaded: %d, Skipped: %d, Errors: %d\n", t.downloaded, t.skipped, t.failed)
+		s += lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(last) + "\n"
+	}
+	s += lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(m.keys.describe(actionSkipFile)+" to skip the current file") + "\n"
+	return s
+}
+
 // handleKeyPress processes keyboard input
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.downloading {
+		// The skip_file key skips the file being downloaded; nothing else
+		// works until the job is done.
+		if a, ok := m.keys.downloadAction(msg.String()); ok && a == actionSkipFile && m.progress != nil {
+			m.progress.skipFile()
+		}
 		return m, nil
 	}
+	if m.results != nil {
+		return m.handleResultsKey(msg)
+	}
+	if m.info != nil {
+		return m.handleInfoKey(msg)
+	}
+	if m.history != nil {
+		return m.handleHistoryKey(msg)
+	}
+	if m.cart != nil {
+		return m.handleCartKey(msg)
+	}
+	if m.shared != nil {
+		return m.handleSharedKey(msg)
+	}
+	// An open prompt captures all input until it's submitted or cancelled.
+	if m.prompt != promptNone {
+		return m.handlePromptKey(msg)
+	}
 	// When the help view is open, only allow closing it or quitting.
 	if m.showHelp {
-		switch msg.String() {
-		case "q", "ctrl+c":
+		a, _ := m.keys.action(msg.String())
+		switch {
+		case msg.String() == "ctrl+c":
 			return m, tea.Quit
-		case "?", "esc":
+		case a == actionQuit:
+			m.showHelp = false
+			return m.runAction(actionQuit, 0)
+		case msg.String() == "esc" || a == actionHelp:
 			m.showHelp = false
 		}
 		return m, nil
 	}
-	switch msg.String() {
-	case "q", "ctrl+c":
+	key := msg.String()
+	if key == "ctrl+c" {
 		return m, tea.Quit
-	case "?":
+	}
+	if m.pendingKey != "" {
+		first := m.pendingKey
+		m.pendingKey = ""
+		count := m.count
+		m.count = 0
+		if a, ok := m.keys.sequenceAction(first, key); ok {
+			return m.runAction(a, count)
+		}
+		// Not a sequence we know; handle this key on its own.
+	}
+
+	// Digits build a count for the next motion. A leading 0 isn't a count.
+	if len(key) == 1 && key >= "0" && key <= "9" && (key != "0" || m.count > 0) {
+		m.count = min(m.count*10+int(key[0]-'0'), maxCount)
+		return m, nil
+	}
+	if m.keys.isPrefix(key) {
+		// Start a sequence, keeping any count for when it completes
+		m.pendingKey = key
+		m.pendingSeq++
+		seq := m.pendingSeq
+		return m, tea.Tick(keySequenceTimeout, func(time.Time) tea.Msg {
+			return keySequenceTimeoutMsg{seq: seq}
+		})
+	}
+	count := m.count
+	m.count = 0 // any other key consumes the count
+
+	if a, ok := m.keys.action(key); ok {
+		return m.runAction(a, count)
+	}
+	// Unbound alt+letters jump to the entry whose name starts with them.
+	if msg.Alt && msg.Type == tea.KeyRunes {
+		return m.typeAhead(msg.Runes)
+	}
+	return m, nil
+}
+
+// runAction performs a bound action. count is the number typed before the key,
+// or 0 if there wasn't one.
+func (m Model) runAction(a action, count int) (tea.Model, tea.Cmd) {
+	switch a {
+	case actionQuit:
+		if m.config.ConfirmLeaveSelection && len(m.selected) > 0 {
+			m.openPrompt(promptConfirmQuit)
+			return m, nil
+		}
+		return m, tea.Quit
+	case actionHelp:
 		m.showHelp = true
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-		}
-	case "down", "j":
-		if m.cursor < len(m.files)-1 {
-			m.cursor++
-		}
-	case "g":
-		// Jump to top
-		m.cursor = 0
-	case "G":
-		// Jump to bottom
-		if len(m.files) > 0 {
-			m.cursor = len(m.files) - 1
-		}
-	case "ctrl+u":
+	case actionUp:
+		m.moveCursor(-max(1, count))
+	case actionDown:
+		m.moveCursor(max(1, count))
+	case actionTop:
+		// Jump to top, or to line N with a count
+		m.jumpToLine(count, 0)
+	case actionBottom:
+		// Jump to bottom, or to line N with a count
+		m.jumpToLine(count, len(m.visible)-1)
+	case actionNextFolder:
+		m.jumpToFolder(max(1, count))
+	case actionPrevFolder:
+		m.jumpToFolder(-max(1, count))
+	case actionGoTo:
+		m.openPrompt(promptGoTo)
+	case actionPageUp:
 		// Go up 5 items
 		m.cursor = max(0, m.cursor-5)
-	case "ctrl+d":
+	case actionPageDown:
 		// Go down 5 items
-		if len(m.files) > 0 {
-			m.cursor = min(len(m.files)-1, m.cursor+5)
+		if len(m.visible) > 0 {
+			m.cursor = min(len(m.visible)-1, m.cursor+5)
 		}
-	case "enter":
-		if len(m.files) > 0 && m.cursor < len(m.files) {
-			file := m.files[m.cursor]
+	case actionOpen:
+		if len(m.visible) > 0 && m.cursor < len(m.visible) {
+			file := m.visible[m.cursor]
 			if file.IsFolder {
-				// Check if folder is cached
-				if cachedFiles, exists := m.folderCache[file.Path]; exists {
-					m.files = cachedFiles
-					m.currentPath = file.Path
-					m.cursor = 0
-					m.selected = make(map[int]bool)
-					return m, nil
-				} else {
-					m.loading = true
-					return m, loadFilesCmd(file.Path)
-				}
+				return m.openFolder(file.Path)
 			} else {
 				// TODO: Handle file opening
 				return m, func() tea.Msg {
@@ -307,50 +870,146 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
-	case " ":
-		if len(m.files) > 0 && m.cursor < len(m.files) {
-			if m.selected[m.cursor] {
-				delete(m.selected, m.cursor)
-			} else {
-				m.selected[m.cursor] = true
-			}
+	case actionSharedFolders:
+		if m.config.link != nil {
+			m.error = "Shared folders belong to your account, not the link"
+			m.errorTime = time.Now()
+			return m, nil
 		}
-	case "esc":
+		return m.openSharedFolders()
+	case actionFolderSize:
+		return m.sizeFolder()
+	case actionShowSelection:
+		return m.openCart()
+	case actionHistory:
+		if m.config.link != nil {
+			m.error = "Download history belongs to your account, not the link"
+			m.errorTime = time.Now()
+			return m, nil
+		}
+		return m.openHistory()
+	case actionInfo:
+		// Show full metadata for the entry under the cursor
+		if m.cursor < len(m.visible) {
+			return m, fileInfoCmd(&m.config, m.visible[m.cursor])
+		}
+	case actionSelectPattern:
+		m.openPrompt(promptSelectPattern)
+	case actionInvertSelection:
+		m.invertSelection()
+	case actionSearch:
+		m.startSearch()
+	case actionNextMatch:
+		return m.nextMatch(1)
+	case actionPrevMatch:
+		return m.nextMatch(-1)
+	case actionMarkRange:
+		m.markRange()
+	case actionSelect:
+		if m.markPath != "" {
+			// Finish a range started with mark_range
+			m.selectRange()
+		} else if len(m.visible) > 0 && m.cursor < len(m.visible) {
+			m.toggleSelected(m.visible[m.cursor])
+		}
+	case actionParent:
 		if m.currentPath != "" {
-			parent := filepath.Dir(m.currentPath)
-			if parent == "." || parent == "/" {
-				parent = ""
-			}
-			// Check if parent is cached
-			if cachedFiles, exists := m.folderCache[parent]; exists {
-				m.files = cachedFiles
-				m.currentPath = parent
-				m.cursor = 0
-				m.selected = make(map[int]bool)
-				return m, nil
-			} else {
-				m.loading = true
-				return m, loadFilesCmd(parent)
+			return m.openFolder(parentPath(m.currentPath))
+		}
+	case actionToggleHidden:
+		m.toggleHidden()
+		state := "hidden"
+		if m.showHidden {
+			state = "shown"
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Hidden files " + state}
+		}
+	case actionToggleFoldersFirst:
+		m.toggleFoldersFirst()
+		order := "mixed with files"
+		if m.foldersFirst {
+			order = "listed first"
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Folders " + order}
+		}
+	case actionToggleFullPath:
+		m.showFullPath = !m.showFullPath
+		state := "names"
+		if m.showFullPath {
+			state = "full paths"
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Showing " + state}
+		}
+	case actionTogglePreview:
+		m.showPreview = !m.showPreview
+		msg := "Folder preview off"
+		if m.showPreview {
+			msg = "Folder preview on"
+			if m.width < minPreviewTermWidth {
+				msg = fmt.Sprintf("Folder preview on; it shows once the terminal is %d columns wide", minPreviewTermWidth)
 			}
 		}
-	case "R":
-		m.loading = true
-		return m, loadFilesCmd(m.currentPath)
-	case "C":
+		return m, func() tea.Msg {
+			return StatusMsg{Message: msg}
+		}
+	case actionToggleDetails:
+		state := "names only"
+		if m.viewMode == viewCompact {
+			m.viewMode = viewDetailed
+			state = "sizes and modified times"
+		} else {
+			m.viewMode = viewCompact
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Showing " + state}
+		}
+	case actionRefresh:
+		// After a network failure, retry the folder that didn't load.
+		p := m.currentPath
+		if m.offline {
+			p, m.offline = m.offlinePath, false
+		}
+		m.forgetPreviews(p)
+		return m, m.load(p)
+	case actionClearCache:
 		// Clear the cache
-		m.folderCache = make(map[string][]FileItem)
+		m.clearCache()
 		return m, func() tea.Msg {
 			return StatusMsg{Message: "Cache cleared"}
 		}
-	case "b":
+	case actionOpenWebItem:
+		if m.config.link != nil || m.cursor >= len(m.visible) {
+			// Links have no per-entry pages, so open the link itself
+			return m.runAction(actionOpenWeb, 0)
+		}
+		// Open the entry under the cursor in Dropbox web UI
+		item := m.visible[m.cursor]
+		dropboxURL := dropboxPreviewURL(item)
+		return m, func() tea.Msg {
+			if err := openBrowser(dropboxURL); err != nil {
+				return StatusMsg{Message: fmt.Sprintf("Failed to open browser: %v", err)}
+			}
+			return StatusMsg{Message: fmt.Sprintf("Opened %s in browser", item.displayPath())}
+		}
+	case actionOpenWeb:
+		if m.config.link != nil {
+			// The link's own page is the only web view of it
+			return m, func() tea.Msg {
+				if err := openBrowser(m.config.link.URL); err != nil {
+					return StatusMsg{Message: fmt.Sprintf("Failed to open browser: %v", err)}
+				}
+				return StatusMsg{Message: "Opened shared link in browser"}
+			}
+		}
 		// Open current folder in Dropbox web UI
 		webPath := m.currentPath
 		if webPath == "" {
 			webPath = "/"
 		}
-		// Properly URL encode the path for the web URL
-		encodedPath := url.PathEscape(webPath)
-		dropboxURL := fmt.Sprintf("https://www.dropbox.com/home%s", encodedPath)
+		dropboxURL := dropboxWebURL(m.currentPath)
 
 		// Open the URL in the default browser
 		return m, func() tea.Msg {
@@ -359,41 +1018,339 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return StatusMsg{Message: fmt.Sprintf("Opened %s in browser", webPath)}
 		}
-	case "d":
-		// Download selected files
-		if len(m.selected) > 0 {
-			var selectedFiles []FileItem
-			for i, selected := range m.selected {
-				if selected && i < len(m.files) {
-					selectedFiles = append(selectedFiles, m.files[i])
-				}
+	case actionOpenLocal:
+		// Reveal downloaded files in the system file manager
+		target := m.localOpenTarget()
+		return m, func() tea.Msg {
+			if err := openLocal(target); err != nil {
+				return StatusMsg{Message: fmt.Sprintf("Failed to open %s: %v", target, err)}
 			}
-			if len(selectedFiles) > 0 {
-				return m, func() tea.Msg {
-					return DownloadMsg{Files: selectedFiles}
-				}
+			return StatusMsg{Message: fmt.Sprintf("Opened %s", target)}
+		}
+	case actionTempLink:
+		return m.promptTempLink()
+	case actionCopyLocalPath:
+		// Copy where the entry under the cursor is (or would be) downloaded
+		target := m.config.DownloadPath
+		if m.cursor < len(m.visible) {
+			if local, err := itemLocalPath(&m.config, m.visible[m.cursor]); err == nil {
+				target = local
 			}
-		} else {
-			return m, func() tea.Msg {
-				return StatusMsg{Message: "No files selected for download"}
+		}
+		return m, func() tea.Msg {
+			if err := copyToClipboard(target); err != nil {
+				return ErrorMsg{Error: fmt.Sprintf("Failed to copy path: %v", err)}
 			}
+			return StatusMsg{Message: "Copied " + target}
+		}
+	case actionDownload, actionShallowDownload:
+		// Download selected files, or the one under the cursor if none are.
+		// A shallow download takes only the files directly inside folders.
+		shallow := a == actionShallowDownload
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m.startDownload(selectedFiles, shallow)
+		}
+		if m.config.DownloadCursor && m.cursor < len(m.visible) {
+			return m.startDownload([]FileItem{m.visible[m.cursor]}, shallow)
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "No files selected for download"}
+		}
+	case actionDownloadZip:
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m.startZipDownload(selectedFiles)
+		}
+		if m.cursor < len(m.visible) {
+			return m.startZipDownload([]FileItem{m.visible[m.cursor]})
+		}
+		return m, nil
+	case actionDelete:
+		if m.config.link != nil {
+			return m, linkReadOnlyCmd()
+		}
+		// Delete selected files, after confirming
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m.confirmDelete(selectedFiles)
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "No files selected for deletion"}
+		}
+	case actionArchive:
+		// Archive selected files, or the one under the cursor if none are.
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m.confirmArchive(selectedFiles)
+		}
+		if m.cursor < len(m.visible) {
+			return m.confirmArchive([]FileItem{m.visible[m.cursor]})
+		}
+		return m, nil
+	case actionExportListing:
+		return m.promptExportListing(false)
+	case actionExportTree:
+		return m.promptExportListing(true)
+	case actionMove:
+		if m.config.link != nil {
+			return m, linkReadOnlyCmd()
+		}
+		// Move selected files to another folder
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m.promptMove(selectedFiles)
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "No files selected to move"}
+		}
+	case actionEmptyTrash:
+		if m.config.link != nil {
+			return m, linkReadOnlyCmd()
+		}
+		return m.confirmEmptyTrash()
+	case actionUndo:
+		if m.config.link != nil {
+			return m, linkReadOnlyCmd()
+		}
+		return m.confirmUndo()
+	case actionDuplicate:
+		if m.config.link != nil {
+			return m, linkReadOnlyCmd()
+		}
+		// Copy selected files alongside themselves
+		if selectedFiles := m.selectedItems(); len(selectedFiles) > 0 {
+			return m, duplicateCmd(&m.config, selectedFiles)
+		}
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "No files selected to duplicate"}
 		}
 	}
 	return m, nil
 }
 
+// openFolder shows folder p, from the cache if it's there.
+func (m Model) openFolder(p string) (tea.Model, tea.Cmd) {
+	if cachedFiles, exists := m.cachedFolder(p); exists {
+		m.setFiles(p, cachedFiles)
+		return m, nil
+	}
+	return m, m.load(p)
+}
+
+// answerQuit handles y/n at the prompt to quit with a selection. Other keys
+// leave the prompt open.
+func (m Model) answerQuit(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		m.closePrompt()
+		return m, tea.Quit
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
+
+// moveCursor moves the cursor by delta rows, clamped to the list bounds. With
+// wrap_cursor on, moving past the end it's already at wraps to the other end.
+func (m *Model) moveCursor(delta int) {
+	if len(m.visible) == 0 {
+		return
+	}
+	last := len(m.visible) - 1
+	if m.config.WrapCursor {
+		if delta < 0 && m.cursor == 0 {
+			m.cursor = last
+			return
+		}
+		if delta > 0 && m.cursor == last {
+			m.cursor = 0
+			return
+		}
+	}
+	m.cursor = max(0, min(len(m.visible)-1, m.cursor+delta))
+}
+
+// jumpToLine moves the cursor to the 1-based line n, clamped to the list. With
+// no count (n == 0) it moves to fallback instead.
+func (m *Model) jumpToLine(n, fallback int) {
+	if len(m.visible) == 0 {
+		return
+	}
+	target := fallback
+	if n > 0 {
+		target = n - 1
+	}
+	m.cursor = max(0, min(len(m.visible)-1, target))
+}
+
+// countLabel summarizes the current folder's entries, e.g. "42 items (5
+// folders, 37 files)", prefixed with "showing 8 of" when hidden files are
+// filtered out and followed by how many entries are selected, in any folder.
+// It's empty while the folder is loading.
+func (m Model) countLabel() string {
+	if m.loading || len(m.files) == 0 {
+		return ""
+	}
+	folders := 0
+	for _, f := range m.files {
+		if f.IsFolder {
+			folders++
+		}
+	}
+	label := fmt.Sprintf("%s (%s, %s)", pluralize(len(m.files), "item"),
+		pluralize(folders, "folder"), pluralize(len(m.files)-folders, "file"))
+	if len(m.visible) < len(m.files) {
+		label = fmt.Sprintf("showing %d of %s", len(m.visible), label)
+	}
+	if len(m.selected) > 0 {
+		label += fmt.Sprintf(" · %d selected", len(m.selected))
+	}
+	return label
+}
+
+// jumpToFolder moves the cursor to the nth folder below it (or above, for a
+// negative n), skipping files. It stops at the last folder found rather than
+// wrapping, like moving past either end of the list.
+func (m *Model) jumpToFolder(n int) {
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	for i := m.cursor + dir; i >= 0 && i < len(m.visible) && n > 0; i += dir {
+		if m.visible[i].IsFolder {
+			m.cursor = i
+			n--
+		}
+	}
+}
+
+// setFiles shows the entries of path, resetting the cursor. The selection is
+// kept, so it can gather entries from several folders.
+func (m *Model) setFiles(path string, files []FileItem) {
+	m.files = files
+	m.currentPath = path
+	m.moreCursor = ""
+	m.loadingMore = false
+	m.cursor = 0
+	m.markPath = ""
+	m.refreshVisible()
+}
+
+// refreshVisible rebuilds the displayed list from m.files, leaving out
+// dotfiles unless they're shown and sorting for display. m.files itself is
+// never filtered or reordered, so toggling is cheap and reversible.
+func (m *Model) refreshVisible() {
+	visible := make([]FileItem, 0, len(m.files))
+	for _, file := range m.files {
+		if !m.showHidden && isHidden(file.Name) {
+			continue
+		}
+		visible = append(visible, file)
+	}
+	sortEntries(visible, m.foldersFirst)
+	m.visible = visible
+}
+
+// toggleFoldersFirst flips whether folders sort ahead of files, keeping the
+// cursor on the same entry.
+func (m *Model) toggleFoldersFirst() {
+	cursorPath := m.cursorPath()
+	m.foldersFirst = !m.foldersFirst
+	m.refreshVisible()
+	m.restoreCursor(cursorPath)
+}
+
+// toggleHidden flips whether dotfiles are listed, keeping the cursor on the
+// same entry where it remains visible. Dotfiles being hidden drop out of the
+// selection, so nothing unseen is downloaded.
+func (m *Model) toggleHidden() {
+	cursorPath := m.cursorPath()
+	m.showHidden = !m.showHidden
+	m.refreshVisible()
+	if !m.showHidden {
+		for _, file := range m.files {
+			if isHidden(file.Name) {
+				delete(m.selected, file.Path)
+			}
+		}
+	}
+	m.restoreCursor(cursorPath)
+}
+
+// cursorPath returns the path of the entry under the cursor, so it can be
+// found again after the visible list is rebuilt.
+func (m Model) cursorPath() string {
+	if m.cursor < len(m.visible) {
+		return m.visible[m.cursor].Path
+	}
+	return ""
+}
+
+// restoreCursor puts the cursor back on the entry at cursorPath, or at the
+// top if it's gone.
+func (m *Model) restoreCursor(cursorPath string) {
+	m.cursor = 0
+	for i, file := range m.visible {
+		if file.Path == cursorPath {
+			m.cursor = i
+		}
+	}
+}
+
+// isHidden reports whether name is a dotfile.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// displayPath returns the item's path in its original casing, falling back to
+// the lowercased Path when Dropbox didn't provide one.
+func (f FileItem) displayPath() string {
+	if f.DisplayPath != "" {
+		return f.DisplayPath
+	}
+	return f.Path
+}
+
+// parentPath returns the Dropbox folder containing p, with the root as "".
+func parentPath(p string) string {
+	return normalizeRemotePath(path.Dir(normalizeRemotePath(p)))
+}
+
+// withinRemote reports whether the Dropbox path p is inside the folder dir.
+func withinRemote(p, dir string) bool {
+	return strings.HasPrefix(p, dir+"/")
+}
+
+// localOpenTarget returns the local directory to reveal for the entry under the
+// cursor: the downloaded folder itself, or the folder a file was downloaded
+// into. It falls back to the download directory when nothing has been
+// downloaded there yet.
+func (m Model) localOpenTarget() string {
+	if m.cursor < len(m.visible) {
+		file := m.visible[m.cursor]
+		if dir, err := itemLocalPath(&m.config, file); err == nil {
+			if !file.IsFolder {
+				dir = filepath.Dir(dir)
+			}
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return dir
+			}
+		}
+	}
+	return m.config.DownloadPath
+}
+
 // handleWindowSize processes window size changes
 func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.width = msg.Width
 	m.height = msg.Height
-	return m, nil
+	return m, m.previewCmd()
 }
 
 // renderFileList renders the list of files
 func (m Model) renderFileList() string {
 	var s strings.Builder
+	searchTerm := m.activeSearchTerm()
 
-	for i, file := range m.files {
+	start, end := m.listWindow()
+	for i := start; i < end; i++ {
+		file := m.visible[i]
 		// Cursor indicator
 		cursor := " "
 		if m.cursor == i {
@@ -402,44 +1359,129 @@ func (m Model) renderFileList() string {
 
 		// Selection indicator
 		selected := " "
-		if m.selected[i] {
+		if m.isSelected(file) {
 			selected = "✓"
+		} else if m.markPath != "" && file.Path == m.markPath {
+			selected = "•"
 		}
 
 		// File icon and name
 		icon := "📄"
 		if file.IsFolder {
 			icon = "📁"
+		} else if file.Exportable {
+			icon = "📝"
+		} else if file.Shared {
+			icon = "👥"
 		}
 
 		// Style based on selection and cursor
 		style := lipgloss.NewStyle()
 		if m.cursor == i {
-			style = style.Bold(true).Foreground(lipgloss.Color("63"))
+			style = style.Bold(true).Foreground(m.config.Theme.Cursor)
 		}
-		if m.selected[i] {
-			style = style.Foreground(lipgloss.Color("156"))
+		if m.isSelected(file) {
+			style = style.Foreground(m.config.Theme.Selected)
 		}
 
-		line := fmt.Sprintf("%s %s %s %s", cursor, selected, icon, file.Name)
-		s.WriteString(style.Render(line) + "\n")
+		prefix := fmt.Sprintf("%s %s %s ", cursor, selected, icon)
+		// Long names are cut from the middle to fit the terminal width
+		// (counted in display cells, so wide glyphs don't wrap the line),
+		// keeping the extension visible.
+		label := file.Name
+		if m.showFullPath {
+			label = file.displayPath()
+		}
+		// Empty files and revision counts (show_revisions) follow the
+		// name, muted.
+		suffix := ""
+		if !file.IsFolder {
+			if file.Size == 0 && !file.Exportable {
+				suffix = "  empty"
+			}
+			count, ok := m.revisions[file.Path]
+			if l := revisionLabel(count, ok); l != "" {
+				suffix += "  " + l
+			}
+		}
+		// The detailed view adds size and modified columns at the right
+		// edge, padding names out to line them up.
+		details := ""
+		if m.viewMode == viewDetailed {
+			details = "  " + m.detailColumns(file)
+		}
+		room := m.listWidth() - runewidth.StringWidth(prefix) - runewidth.StringWidth(suffix) - runewidth.StringWidth(details)
+		displayName := truncateMiddle(label, room)
+		highlight := style.Background(m.config.Theme.Match).Foreground(lipgloss.Color("0"))
+		name := highlightMatches(displayName, searchTerm, style, highlight)
+		if suffix != "" {
+			name += lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(suffix)
+		}
+		if details != "" {
+			name += strings.Repeat(" ", max(0, room-runewidth.StringWidth(displayName))) +
+				lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(details)
+		}
+		s.WriteString(style.Render(prefix) + name + "\n")
+	}
+	if m.moreCursor != "" {
+		s.WriteString(lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render("    … more entries (loading as you scroll)") + "\n")
 	}
 
 	return s.String()
 }
 
+// listChrome is the number of lines browse mode uses around the file list
+// (path, blank line, the more-entries line, blank line, and status).
+const listChrome = 5
+
+// listWindow returns the range of m.visible that fits on screen: from
+// listOffset, moved just enough to keep the cursor in view.
+func (m Model) listWindow() (start, end int) {
+	page := max(1, m.height-listChrome)
+	start = min(m.listOffset, max(0, len(m.visible)-page))
+	if m.cursor < start {
+		start = m.cursor
+	} else if m.cursor >= start+page {
+		start = m.cursor - page + 1
+	}
+	start = max(0, start)
+	return start, min(len(m.visible), start+page)
+}
+
+// viewMode is how much the file list shows about each entry.
+type viewMode int
+
+const (
+	viewCompact  viewMode = iota // icon and name
+	viewDetailed                 // also size and modified time
+)
+
+// detailColumns returns an entry's size and modified time as fixed-width
+// columns for the detailed view. Folders show their size only once measured,
+// and no time, since Dropbox doesn't track one for them.
+func (m Model) detailColumns(file FileItem) string {
+	size, modified := "", ""
+	if !file.IsFolder {
+		size = formatSize(file.Size, m.config.BinaryUnits)
+		modified = file.Modified.Local().Format("2006-01-02 15:04")
+	} else if total, ok := m.folderSizes[file.Path]; ok {
+		size = formatSize(total, m.config.BinaryUnits)
+	}
+	return fmt.Sprintf("%10s  %16s", size, modified)
+}
+
 // renderHelpView renders the help screen listing all key bindings
 func (m Model) renderHelpView() string {
 	var s strings.Builder
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("63"))
+		Foreground(m.config.Theme.Accent)
 	keyStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("156"))
+		Foreground(m.config.Theme.Selected)
 	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(m.config.Theme.Muted)
 
 	type binding struct {
 		keys string
@@ -452,31 +1494,67 @@ func (m Model) renderHelpView() string {
 		{
 			title: "Navigation",
 			bindings: []binding{
-				{"up / k", "move up"},
-				{"down / j", "move down"},
-				{"g", "jump to top"},
-				{"G", "jump to bottom"},
-				{"ctrl+u", "move up 5 items"},
-				{"ctrl+d", "move down 5 items"},
-				{"enter", "open folder"},
-				{"esc", "go to parent folder"},
+				{m.keys.describe(actionUp), "move up"},
+				{m.keys.describe(actionDown), "move down"},
+				{m.keys.describe(actionTop), "jump to top"},
+				{m.keys.describe(actionBottom), "jump to bottom"},
+				{m.keys.describe(actionPageUp), "move up 5 items"},
+				{m.keys.describe(actionPageDown), "move down 5 items"},
+				{m.keys.describe(actionNextFolder), "next folder (skips files)"},
+				{m.keys.describe(actionPrevFolder), "previous folder (skips files)"},
+				{m.keys.describe(actionGoTo), "go to line (type its number) or folder (type /path, tab completes)"},
+				{"alt+<letters>", "jump to the entry whose name starts with the letters"},
+				{"<n> + key", "repeat a move n times (top / bottom go to line n)"},
+				{m.keys.describe(actionOpen), "open folder"},
+				{m.keys.describe(actionParent), "go to parent folder"},
 			},
 		},
 		{
 			title: "Files",
 			bindings: []binding{
-				{"space", "toggle selection"},
-				{"d", "download selected files"},
-				{"b", "open current folder in browser"},
+				{m.keys.describe(actionSelect), "toggle selection"},
+				{m.keys.describe(actionSelectPattern), "select entries matching a pattern"},
+				{m.keys.describe(actionMarkRange), "start a range, then select it"},
+				{m.keys.describe(actionInvertSelection), "invert selection"},
+				{m.keys.describe(actionShowSelection), "show everything selected, across folders (download or remove)"},
+				{m.keys.describe(actionSearch), "search names (moves the cursor as you type)"},
+				{m.keys.describe(actionNextMatch), "next search match"},
+				{m.keys.describe(actionPrevMatch), "previous search match"},
+				{m.keys.describe(actionInfo), "show details (size, hash, rev...) of the current entry"},
+				{m.keys.describe(actionSharedFolders), "list folders shared with you"},
+				{m.keys.describe(actionHistory), "show download history"},
+				{m.keys.describe(actionFolderSize), "measure the total size of the folder under the cursor"},
+				{m.keys.describe(actionDownload), "download selected (or current) files"},
+				{m.keys.describe(actionShallowDownload), "download only the files directly inside folders"},
+				{m.keys.describe(actionDownloadZip), "download selected (or current) folders as .zip files"},
+				{m.keys.describe(actionSkipFile), "while downloading, skip the file being downloaded"},
+				{m.keys.describe(actionDelete), "delete selected files, or move them to the trash folder (asks first)"},
+				{m.keys.describe(actionEmptyTrash), "empty the trash folder (asks first)"},
+				{m.keys.describe(actionMove), "move selected files to another folder"},
+				{m.keys.describe(actionArchive), "archive: download, verify, then delete from Dropbox (needs allow_archive)"},
+				{m.keys.describe(actionDuplicate), "duplicate selected files in place (name (copy).ext)"},
+				{m.keys.describe(actionUndo), "undo the last delete, move, or duplicate (asks first)"},
+				{m.keys.describe(actionExportListing), "export this folder's listing to CSV/JSON"},
+				{m.keys.describe(actionExportTree), "export a recursive listing to CSV/JSON"},
+				{m.keys.describe(actionOpenWeb), "open current folder in browser"},
+				{m.keys.describe(actionOpenWebItem), "open current entry in browser"},
+				{m.keys.describe(actionOpenLocal), "open downloaded location locally"},
+				{m.keys.describe(actionCopyLocalPath), "copy the current entry's local download path"},
+				{m.keys.describe(actionTempLink), "copy or open a temporary link to stream the current file"},
 			},
 		},
 		{
 			title: "General",
 			bindings: []binding{
-				{"R", "refresh current folder"},
-				{"C", "clear folder cache"},
-				{"?", "toggle this help"},
-				{"q / ctrl+c", "quit"},
+				{m.keys.describe(actionRefresh), "refresh current folder, or retry one that failed to load"},
+				{m.keys.describe(actionClearCache), "clear folder cache"},
+				{m.keys.describe(actionToggleHidden), "show/hide hidden files"},
+				{m.keys.describe(actionToggleFoldersFirst), "toggle folders first / mixed with files"},
+				{m.keys.describe(actionToggleFullPath), "toggle showing full paths instead of names"},
+				{m.keys.describe(actionToggleDetails), "toggle showing sizes and modified times"},
+				{m.keys.describe(actionTogglePreview), "toggle a preview of the folder under the cursor"},
+				{m.keys.describe(actionHelp), "toggle this help"},
+				{m.keys.describe(actionQuit) + " / ctrl+c", "quit"},
 			},
 		},
 	}
@@ -0,0 +1,690 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestToggleHidden(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{
+		{Name: ".env", Path: "/.env"},
+		{Name: "a.txt", Path: "/a.txt"},
+		{Name: "b.txt", Path: "/b.txt"},
+	})
+	if len(m.visible) != 2 {
+		t.Fatalf("dotfiles should be hidden by default, got %d visible", len(m.visible))
+	}
+
+	m.cursor = 1 // b.txt
+	selectAt(&m, 0)
+	m.toggleHidden()
+
+	if len(m.visible) != 3 || len(m.files) != 3 {
+		t.Fatalf("visible = %d, files = %d, want 3 and 3", len(m.visible), len(m.files))
+	}
+	if got := m.visible[m.cursor].Name; got != "b.txt" {
+		t.Errorf("cursor moved to %s, want b.txt", got)
+	}
+	if got := selectedPaths(m); got != "/a.txt" {
+		t.Errorf("selection = %s, want only a.txt", got)
+	}
+
+	// Hiding dotfiles again drops them from the selection.
+	selectAt(&m, 0) // .env
+	m.toggleHidden()
+	if got := selectedPaths(m); got != "/a.txt" {
+		t.Errorf("selection = %s, want .env dropped", got)
+	}
+}
+
+func TestKeySequence(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	m.cursor = 2
+
+	g := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+
+	updated, _ := m.handleKeyPress(g)
+	m = updated.(Model)
+	if m.cursor != 2 || m.pendingKey != "g" {
+		t.Fatalf("a single g should only start a sequence (cursor=%d, pending=%q)", m.cursor, m.pendingKey)
+	}
+	updated, _ = m.handleKeyPress(g)
+	m = updated.(Model)
+	if m.cursor != 0 || m.pendingKey != "" {
+		t.Errorf("gg: cursor=%d pending=%q, want 0 and none", m.cursor, m.pendingKey)
+	}
+
+	// An unrelated key after g cancels the sequence and is handled normally.
+	updated, _ = m.handleKeyPress(g)
+	updated, _ = updated.(Model).handleKeyPress(j)
+	if m = updated.(Model); m.cursor != 1 || m.pendingKey != "" {
+		t.Errorf("gj: cursor=%d pending=%q, want 1 and none", m.cursor, m.pendingKey)
+	}
+
+	// A stale timeout doesn't clear a newer pending key.
+	updated, _ = m.handleKeyPress(g)
+	m = updated.(Model)
+	updated, _ = m.Update(keySequenceTimeoutMsg{seq: m.pendingSeq - 1})
+	if updated.(Model).pendingKey != "g" {
+		t.Error("stale timeout cleared the pending key")
+	}
+	updated, _ = m.Update(keySequenceTimeoutMsg{seq: m.pendingSeq})
+	if updated.(Model).pendingKey != "" {
+		t.Error("timeout did not clear the pending key")
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	m := initialModel(&Config{})
+	var items []FileItem
+	for i := 0; i < 20; i++ {
+		items = append(items, FileItem{Name: string(rune('a' + i))})
+	}
+	m.setFiles("", items)
+
+	press := func(keys string) {
+		for _, r := range keys {
+			updated, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = updated.(Model)
+		}
+	}
+
+	press("5j")
+	if m.cursor != 5 {
+		t.Errorf("5j: cursor = %d, want 5", m.cursor)
+	}
+	press("12k")
+	if m.cursor != 0 {
+		t.Errorf("12k should clamp at the top, cursor = %d", m.cursor)
+	}
+	press("10G")
+	if m.cursor != 9 {
+		t.Errorf("10G: cursor = %d, want 9", m.cursor)
+	}
+	press("3gg")
+	if m.cursor != 2 {
+		t.Errorf("3gg: cursor = %d, want 2", m.cursor)
+	}
+	press("99j")
+	if m.cursor != 19 {
+		t.Errorf("99j should clamp at the bottom, cursor = %d", m.cursor)
+	}
+	press("j")
+	if m.cursor != 19 || m.count != 0 {
+		t.Errorf("count should reset after a motion (cursor=%d, count=%d)", m.cursor, m.count)
+	}
+}
+
+func TestJumpToFolder(t *testing.T) {
+	m := initialModel(&Config{})
+	m.foldersFirst = false
+	m.setFiles("", []FileItem{
+		{Name: "a.txt"},
+		{Name: "b", IsFolder: true},
+		{Name: "c.txt"},
+		{Name: "d", IsFolder: true},
+		{Name: "e.txt"},
+	})
+
+	press := func(keys string) {
+		for _, r := range keys {
+			updated, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = updated.(Model)
+		}
+	}
+
+	press("}")
+	if m.cursor != 1 {
+		t.Errorf("}: cursor = %d, want 1", m.cursor)
+	}
+	press("}}")
+	if m.cursor != 3 {
+		t.Errorf("} past the last folder should stop on it, cursor = %d", m.cursor)
+	}
+	press("G{")
+	if m.cursor != 3 {
+		t.Errorf("{ from the bottom: cursor = %d, want 3", m.cursor)
+	}
+	press("2{")
+	if m.cursor != 1 {
+		t.Errorf("2{ should stop at the first folder, cursor = %d", m.cursor)
+	}
+}
+
+func TestResultsScreen(t *testing.T) {
+	m := initialModel(&Config{})
+	m.height = 6 // two result lines per page
+	m.downloading = true
+
+	next, _ := m.Update(DownloadCompleteMsg{
+		Downloaded: []FileItem{{Path: "/a"}, {Path: "/b"}},
+		Errors:     []ItemError{{Err: "boom"}},
+	})
+	m = next.(Model)
+	if m.downloading || m.results == nil {
+		t.Fatal("completing a download should show the results screen")
+	}
+
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+	for i := 0; i < 20; i++ {
+		next, _ = m.Update(j)
+		m = next.(Model)
+	}
+	if want := len(m.resultLines()) - 2; m.resultsOffset != want {
+		t.Errorf("scrolling stopped at %d, want %d", m.resultsOffset, want)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if next.(Model).results != nil {
+		t.Error("any other key should dismiss the results screen")
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width, m.height = 80, 20
+	depth := 0
+	next, _ := m.Update(DownloadMsg{Files: []FileItem{{Path: "/a"}, {Path: "/b"}, {Path: "/c"}}, MaxDepth: &depth})
+	m = next.(Model)
+	m.downloadEvents = nil // the job itself isn't run here
+
+	next, _ = m.Update(DownloadCompleteMsg{
+		Downloaded: []FileItem{{Path: "/a"}},
+		Errors: []ItemError{
+			{Item: FileItem{Path: "/b"}, Err: "Failed to download b: timeout"},
+			{Item: FileItem{Path: "/b"}, Err: "Failed to download b: again"},
+			{Item: FileItem{Path: "/c"}, Err: "Cancelled c"},
+			{Err: "Failed to update download index: disk full"},
+		},
+	})
+	m = next.(Model)
+	if view := m.View(); !strings.Contains(view, "r to retry 2 failed items") {
+		t.Errorf("results should offer to retry:\n%s", view)
+	}
+
+	next, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || m.results != nil {
+		t.Fatalf("r should close the results and start a download, got %#v", cmd())
+	}
+	if len(msg.Files) != 2 || msg.Files[0].Path != "/b" || msg.Files[1].Path != "/c" || msg.MaxDepth != &depth {
+		t.Errorf("retry = %+v, want b and c with the job's options", msg)
+	}
+
+	// Nothing failed: r just dismisses the results like any other key.
+	next, _ = m.Update(DownloadCompleteMsg{Downloaded: []FileItem{{Path: "/b"}}})
+	m = next.(Model)
+	if strings.Contains(m.View(), "retry") {
+		t.Error("a job without failures shouldn't offer a retry")
+	}
+	next, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if next.(Model).results != nil || cmd != nil {
+		t.Error("r with nothing to retry should just dismiss the results")
+	}
+}
+
+func TestRefreshOnFocus(t *testing.T) {
+	files := []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	m := initialModel(&Config{})
+	m.setFiles("", files)
+	m.folderCache[""] = files
+
+	if _, cmd := m.Update(tea.FocusMsg{}); cmd != nil {
+		t.Fatal("focus shouldn't reload unless refresh_on_focus is set")
+	}
+
+	m.config.RefreshOnFocus = true
+	m.cursor = 1
+	next, cmd := m.Update(tea.FocusMsg{})
+	m = next.(Model)
+	if cmd == nil || !m.loading {
+		t.Fatal("focus should reload the current folder")
+	}
+	if _, ok := m.folderCache[""]; ok {
+		t.Error("focus should invalidate the current folder's cache entry")
+	}
+
+	// The reload adds an entry; the cursor stays on b.
+	next, _ = m.Update(FilesLoadedMsg{Path: "", Files: []FileItem{{Name: "0", Path: "/0"}, files[0], files[1]}})
+	m = next.(Model)
+	if got := m.visible[m.cursor].Name; got != "b" {
+		t.Errorf("cursor on %s after reload, want b", got)
+	}
+}
+
+func TestTerminalTooSmall(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a", Path: "/a"}})
+
+	for _, size := range []tea.WindowSizeMsg{{Width: 39, Height: 24}, {Width: 80, Height: 9}} {
+		next, _ := m.Update(size)
+		if got := next.(Model).View(); !strings.Contains(got, "Terminal too small") {
+			t.Errorf("%dx%d: view = %q, want the too-small notice", size.Width, size.Height, got)
+		}
+	}
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 10})
+	if got := next.(Model).View(); strings.Contains(got, "Terminal too small") {
+		t.Error("40x10 should render normally once the window grows")
+	}
+}
+
+func TestFileListFitsWidth(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 40
+	m.setFiles("", []FileItem{
+		{Name: "短い.txt", Path: "/短い.txt"},
+		{Name: "とても長い日本語のファイル名がここにあります.txt", Path: "/long-ja.txt"},
+		{Name: "🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉.txt", Path: "/party.txt"},
+	})
+
+	m.currentPath = "/projects/2024/a-folder-with-a-rather-long-name/drafts"
+	for _, line := range strings.Split(m.View(), "\n") {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("line %q is %d cells wide, more than %d", line, w, m.width)
+		}
+	}
+}
+
+func TestFileListMarkers(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 80
+	m.setFiles("", []FileItem{
+		{Name: "empty.txt", Path: "/empty.txt"},
+		{Name: "full.txt", Path: "/full.txt", Size: 5},
+		{Name: "shared.txt", Path: "/shared.txt", Size: 5, Shared: true},
+	})
+	lines := strings.Split(m.renderFileList(), "\n")
+	if !strings.Contains(lines[0], "empty.txt  empty") {
+		t.Errorf("0-byte file should be tagged: %q", lines[0])
+	}
+	if strings.Contains(lines[1], "empty") || strings.Contains(lines[1], "👥") {
+		t.Errorf("plain file shouldn't be marked: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "👥") {
+		t.Errorf("shared file should have the shared icon: %q", lines[2])
+	}
+}
+
+func TestDetailedView(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+	m := initialModel(&Config{BinaryUnits: true})
+	m.width = 60
+	m.setFiles("", []FileItem{
+		{Name: "a-report-with-a-very-long-name-indeed.pdf", Path: "/a-report-with-a-very-long-name-indeed.pdf", Size: 2048, Modified: modified},
+		{Name: "b.txt", Path: "/b.txt", Size: 5, Modified: modified},
+	})
+	if strings.Contains(m.renderFileList(), "2024-03-01") {
+		t.Fatal("compact view shouldn't show details")
+	}
+
+	updated, _ := m.runAction(actionToggleDetails, 1)
+	m = updated.(Model)
+	lines := strings.Split(m.renderFileList(), "\n")
+	for i, want := range []string{"2.0 KiB  2024-03-01 09:30", "5 B  2024-03-01 09:30"} {
+		line := lines[i]
+		if !strings.HasSuffix(line, want) || runewidth.StringWidth(line) != m.width {
+			t.Errorf("line %d = %q, want it %d wide ending in %q", i, line, m.width, want)
+		}
+	}
+}
+
+func TestGoToLine(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"3", 2, false},
+		{"99", 4, false},
+		{"0", 0, false},
+		{"x", 1, true},
+	}
+	for _, tt := range tests {
+		m := initialModel(&Config{})
+		m.setFiles("", []FileItem{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}})
+		m.cursor = 1
+		for _, r := range ":" + tt.input {
+			updated, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = updated.(Model)
+		}
+		updated, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updated.(Model)
+		if m.cursor != tt.want || (m.error != "") != tt.wantErr {
+			t.Errorf(":%s: cursor = %d, error = %q; want cursor %d", tt.input, m.cursor, m.error, tt.want)
+		}
+	}
+}
+
+func TestSelectionAcrossFolders(t *testing.T) {
+	m := initialModel(&Config{})
+	m.cacheFolder("", []FileItem{{Name: "docs", Path: "/docs", IsFolder: true}, {Name: "z.txt", Path: "/z.txt"}})
+	m.cacheFolder("/docs", []FileItem{{Name: "a", Path: "/docs/a"}, {Name: "b", Path: "/docs/b"}})
+	m.setFiles("/docs", m.folderCache["/docs"])
+	selectAt(&m, 1)
+
+	updated, _ := m.runAction(actionParent, 0)
+	m = updated.(Model)
+	if m.currentPath != "" || !m.isSelected(FileItem{Path: "/docs/b"}) {
+		t.Fatalf("the selection should survive leaving the folder: path %q, selected %s", m.currentPath, selectedPaths(m))
+	}
+	selectAt(&m, 1) // z.txt
+	if got := m.countLabel(); !strings.HasSuffix(got, "· 2 selected") {
+		t.Errorf("count label = %q, want the selection counted", got)
+	}
+
+	// The selection screen lists both, and downloads them together.
+	updated, _ = m.runAction(actionShowSelection, 0)
+	m = updated.(Model)
+	if len(m.cart) != 2 || m.cart[0].Path != "/z.txt" || m.cart[1].Path != "/docs/b" {
+		t.Fatalf("cart = %+v, want z.txt here then docs/b", m.cart)
+	}
+	updated, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(Model)
+	if msg, ok := cmd().(DownloadMsg); m.cart != nil || !ok || len(msg.Files) != 2 {
+		t.Errorf("d should download the whole selection, got %#v", cmd())
+	}
+
+	// x removes an entry from the selection, c clears the rest.
+	updated, _ = m.runAction(actionShowSelection, 0)
+	m = updated.(Model)
+	updated, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(Model)
+	if got := selectedPaths(m); got != "/docs/b" || len(m.cart) != 1 {
+		t.Errorf("after x: selection %s, cart %+v", got, m.cart)
+	}
+	updated, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = updated.(Model)
+	if len(m.selected) != 0 || m.cart == nil || len(m.cart) != 0 {
+		t.Errorf("after c: selection %s, cart %+v", selectedPaths(m), m.cart)
+	}
+
+	// Confirmations say how much of the selection isn't in view.
+	m.cart = nil
+	selectAt(&m, 1)
+	m.selected["/docs/b"] = FileItem{Name: "b", Path: "/docs/b"}
+	updated, _ = m.confirmDelete(m.selectedItems())
+	m = updated.(Model)
+	if label := m.promptLabel(); label != "delete 2 items (1 in other folders)? (y/n) " {
+		t.Errorf("delete prompt = %q", label)
+	}
+	m.closePrompt()
+	updated, _ = m.promptMove(m.selectedItems())
+	m = updated.(Model)
+	if label := m.promptLabel(); label != "move 2 items (1 in other folders) to: " {
+		t.Errorf("move prompt = %q", label)
+	}
+}
+
+func TestConfirmLeaveSelection(t *testing.T) {
+	m := initialModel(&Config{ConfirmLeaveSelection: true})
+	m.setFiles("", []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}})
+	press := func(r rune) tea.Cmd {
+		updated, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+		return cmd
+	}
+
+	// Nothing selected: q quits straight away.
+	if cmd := press('q'); cmd == nil || m.prompt != promptNone {
+		t.Fatal("q with nothing selected should quit without asking")
+	}
+
+	selectAt(&m, 0)
+	if cmd := press('q'); cmd != nil || m.prompt != promptConfirmQuit {
+		t.Fatalf("q with a selection should ask first: prompt %v", m.prompt)
+	}
+	if label := m.promptLabel(); !strings.Contains(label, "1 item selected") {
+		t.Errorf("label = %q", label)
+	}
+	press('n')
+	if m.prompt != promptNone || len(m.selected) != 1 {
+		t.Fatal("n should keep the selection")
+	}
+	press('q')
+	if cmd := press('y'); cmd == nil {
+		t.Error("y should quit")
+	}
+
+	// Clearing the selection screen asks too.
+	m.closePrompt()
+	updated, _ := m.runAction(actionShowSelection, 0)
+	m = updated.(Model)
+	press('c')
+	if m.prompt != promptConfirmClear || len(m.selected) != 1 {
+		t.Fatalf("c should ask before clearing: prompt %v, selected %s", m.prompt, selectedPaths(m))
+	}
+	if !strings.Contains(m.renderCartView(), "clear all 1 item") {
+		t.Error("the selection screen should show the prompt")
+	}
+	press('y')
+	if m.prompt != promptNone || len(m.selected) != 0 || len(m.cart) != 0 {
+		t.Errorf("y should clear: selected %s, cart %+v", selectedPaths(m), m.cart)
+	}
+}
+
+func TestCountLabel(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/docs", []FileItem{
+		{Name: "a", IsFolder: true},
+		{Name: "b.txt"},
+		{Name: "c.txt"},
+		{Name: ".hidden"},
+	})
+	if got, want := m.countLabel(), "showing 3 of 4 items (1 folder, 3 files)"; got != want {
+		t.Errorf("with a dotfile hidden: %q, want %q", got, want)
+	}
+	m.showHidden = true
+	m.refreshVisible()
+	if got, want := m.countLabel(), "4 items (1 folder, 3 files)"; got != want {
+		t.Errorf("all shown: %q, want %q", got, want)
+	}
+	if header := strings.SplitN(m.View(), "\n", 2)[0]; !strings.Contains(header, "4 items") {
+		t.Errorf("header %q should include the counts", header)
+	}
+}
+
+func TestToggleFullPath(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/docs", []FileItem{
+		{Name: "Report.pdf", Path: "/docs/report.pdf", DisplayPath: "/Docs/Report.pdf"},
+		{Name: "notes.txt", Path: "/docs/notes.txt"},
+	})
+	if list := m.renderFileList(); strings.Contains(list, "/Docs/Report.pdf") {
+		t.Fatal("full paths shouldn't show by default")
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = next.(Model)
+	list := m.renderFileList()
+	// The original casing is shown, falling back to Path when there isn't one.
+	if !strings.Contains(list, "/Docs/Report.pdf") || !strings.Contains(list, "/docs/notes.txt") {
+		t.Errorf("list with full paths = %q", list)
+	}
+}
+
+func TestTypeAhead(t *testing.T) {
+	m := initialModel(&Config{})
+	m.foldersFirst = false
+	m.setFiles("", []FileItem{
+		{Name: "Documents", IsFolder: true},
+		{Name: "photos", IsFolder: true},
+		{Name: "Photoshop.psd"},
+		{Name: "public", IsFolder: true},
+	})
+	alt := func(r rune) {
+		next, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true})
+		m = next.(Model)
+	}
+
+	alt('p')
+	if m.cursor != 1 {
+		t.Errorf("alt+p: cursor = %d, want 1", m.cursor)
+	}
+	alt('u')
+	if m.cursor != 3 || m.typeAheadBuf != "pu" {
+		t.Errorf("alt+p alt+u: cursor = %d, buffer %q; want 3 and pu", m.cursor, m.typeAheadBuf)
+	}
+
+	// After the timeout a new prefix starts.
+	next, _ := m.Update(typeAheadTimeoutMsg{seq: m.typeAheadSeq})
+	m = next.(Model)
+	alt('D')
+	if m.cursor != 0 || m.typeAheadBuf != "D" {
+		t.Errorf("alt+D after timeout: cursor = %d, buffer %q; want 0 and D", m.cursor, m.typeAheadBuf)
+	}
+	alt('x')
+	if m.cursor != 0 || m.error == "" {
+		t.Error("an unmatched prefix should leave the cursor and say so")
+	}
+}
+
+func TestLoadingSpinner(t *testing.T) {
+	m := initialModel(&Config{})
+	next, cmd := m.Update(LoadingMsg{Loading: true})
+	m = next.(Model)
+	if cmd == nil || !m.spinning {
+		t.Fatal("loading should start the spinner")
+	}
+	if view := m.View(); !strings.Contains(view, spinnerFrames[0]+" Connecting to Dropbox") {
+		t.Errorf("first load should say it's connecting:\n%s", view)
+	}
+
+	// A second load while spinning doesn't schedule a second tick.
+	if m.load("/docs") == nil || m.startSpinner() != nil {
+		t.Error("only one spinner tick should be scheduled at a time")
+	}
+	next, _ = m.Update(spinnerTickMsg{})
+	m = next.(Model)
+	if m.spinnerFrame != 1 {
+		t.Errorf("frame = %d, want 1", m.spinnerFrame)
+	}
+
+	next, _ = m.Update(FilesLoadedMsg{Path: "", Files: []FileItem{{Name: "a.txt"}}})
+	m = next.(Model)
+	next, cmd = m.Update(spinnerTickMsg{})
+	m = next.(Model)
+	if cmd != nil || m.spinning {
+		t.Error("the spinner should stop once loading finishes")
+	}
+	next, _ = m.Update(LoadingMsg{Loading: true})
+	if view := next.(Model).View(); !strings.Contains(view, "Loading files...") {
+		t.Errorf("later loads should say loading files:\n%s", view)
+	}
+}
+
+func TestDownloadCursorFallback(t *testing.T) {
+	m := initialModel(&Config{DownloadCursor: true})
+	m.setFiles("", []FileItem{{Name: "a.txt", Path: "/a.txt"}, {Name: "b.txt", Path: "/b.txt"}})
+	m.cursor = 1
+
+	_, cmd := m.runAction(actionDownload, 1)
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || len(msg.Files) != 1 || msg.Files[0].Path != "/b.txt" {
+		t.Fatalf("with nothing selected, got %#v, want a download of b.txt", cmd())
+	}
+
+	m.config.DownloadCursor = false
+	_, cmd = m.runAction(actionDownload, 1)
+	if _, ok := cmd().(StatusMsg); !ok {
+		t.Errorf("with download_cursor off, got %#v, want a status message", cmd())
+	}
+}
+
+func TestWrapCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		wrap   bool
+		cursor int
+		delta  int
+		want   int
+	}{
+		{"up at top stays", false, 0, -1, 0},
+		{"down at bottom stays", false, 2, 1, 2},
+		{"up at top wraps", true, 0, -1, 2},
+		{"down at bottom wraps", true, 2, 1, 0},
+		{"count clamps before wrapping", true, 1, 5, 2},
+		{"middle moves normally", true, 1, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(&Config{WrapCursor: tt.wrap})
+			m.setFiles("", []FileItem{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+			m.cursor = tt.cursor
+			m.moveCursor(tt.delta)
+			if m.cursor != tt.want {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.want)
+			}
+		})
+	}
+}
+
+func TestShallowDownload(t *testing.T) {
+	m := initialModel(&Config{DownloadCursor: true})
+	m.setFiles("", []FileItem{{Name: "docs", Path: "/docs", IsFolder: true}})
+
+	_, cmd := m.runAction(actionShallowDownload, 1)
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || msg.MaxDepth == nil || *msg.MaxDepth != 0 {
+		t.Fatalf("got %#v, want a download limited to depth 0", cmd())
+	}
+
+	_, cmd = m.runAction(actionDownload, 1)
+	if msg := cmd().(DownloadMsg); msg.MaxDepth != nil {
+		t.Errorf("a normal download shouldn't override max_depth, got %d", *msg.MaxDepth)
+	}
+}
+
+func TestMessageExpiry(t *testing.T) {
+	m := initialModel(&Config{StatusTimeout: time.Second})
+	next, cmd := m.Update(StatusMsg{Message: "saved"})
+	if cmd == nil {
+		t.Fatal("a status message should schedule its own expiry")
+	}
+	m = next.(Model)
+	if !shown(m.statusTime, time.Second) || shown(m.statusTime.Add(-2*time.Second), time.Second) {
+		t.Error("status should show for its timeout and no longer")
+	}
+
+	// Sticky messages never expire, so nothing is scheduled.
+	m.config.StatusTimeout = 0
+	if _, cmd := m.Update(StatusMsg{Message: "again"}); cmd != nil {
+		t.Error("a sticky status shouldn't schedule an expiry")
+	}
+	if !shown(time.Now().Add(-time.Hour), 0) {
+		t.Error("a timeout of 0 should never hide a message")
+	}
+}
+
+func TestInitialLoadFailed(t *testing.T) {
+	m := initialModel(&Config{})
+	m.loading = true
+	next, _ := m.Update(ErrorMsg{Error: "Failed to load files from path '': boom"})
+	m = next.(Model)
+	if view := m.View(); !m.loadFailed || !strings.Contains(view, "Failed to load — press R to retry") || strings.Contains(view, "No files found") {
+		t.Fatalf("a failed first load should say so and how to retry:\n%s", view)
+	}
+
+	next, _ = m.Update(FilesLoadedMsg{Path: ""})
+	m = next.(Model)
+	if view := m.View(); m.loadFailed || !strings.Contains(view, "No files found") {
+		t.Fatalf("once loaded, an empty folder should read as empty:\n%s", view)
+	}
+
+	// Later failures leave the folder on screen as it was.
+	m.loading = true
+	next, _ = m.Update(ErrorMsg{Error: "boom"})
+	m = next.(Model)
+	if m.loadFailed {
+		t.Error("only the first load failing should be flagged")
+	}
+}
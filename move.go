@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/async"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// moveJobMsg reports that a batch move is still running on Dropbox's side;
+// polls counts the status checks made so far.
+type moveJobMsg struct {
+	jobID string
+	items []FileItem
+	dest  string
+	polls int
+}
+
+// MoveCompleteMsg reports the outcome of a batch move into Dest, entry by
+// entry. NewPaths maps each moved item's old path to where it ended up (which
+// differs from Dest/name when Dropbox renamed it), so the move can be undone.
+type MoveCompleteMsg struct {
+	Dest     string
+	Moved    []FileItem
+	Errors   []ItemError
+	NewPaths map[string]string
+}
+
+// promptMove asks where to move the selected entries, starting from the
+// current folder.
+func (m Model) promptMove(items []FileItem) (tea.Model, tea.Cmd) {
+	if m.job != "" {
+		m.error = "Wait for the current " + m.job + " to finish"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.pendingMove = items
+	m.openPrompt(promptMoveDest)
+	m.promptInput = m.currentPath + "/"
+	return m, nil
+}
+
+// submitMove starts moving the pending entries into the folder dest. Entries
+// that can't go there (already in it, or a folder into itself) are reported
+// as failures without being sent to Dropbox.
+func (m Model) submitMove(dest string) (tea.Model, tea.Cmd) {
+	dest = normalizeRemotePath(dest)
+	items := m.pendingMove
+	m.pendingMove = nil
+	m.deselect(items)
+
+	toMove, rejected := movable(items, dest)
+	if len(toMove) == 0 {
+		return m, func() tea.Msg {
+			return MoveCompleteMsg{Dest: dest, Errors: rejected}
+		}
+	}
+
+	m.job = "move"
+	m.status = fmt.Sprintf("Moving %s to %s/...", pluralize(len(toMove), "item"), dest)
+	m.statusTime = time.Now()
+	return m, moveBatchCmd(&m.config, toMove, dest, rejected, false)
+}
+
+// movable splits items into those that can be moved into dest and those that
+// can't (already in it, or a folder into itself).
+func movable(items []FileItem, dest string) (toMove []FileItem, rejected []ItemError) {
+	for _, item := range items {
+		switch {
+		case parentPath(item.Path) == dest:
+			rejected = append(rejected, ItemError{Item: item, Err: fmt.Sprintf("%s: already in %s/", item.Name, dest)})
+		case item.IsFolder && (dest == item.Path || withinRemote(dest, item.Path)):
+			rejected = append(rejected, ItemError{Item: item, Err: fmt.Sprintf("%s: can't move a folder into itself", item.Name)})
+		default:
+			toMove = append(toMove, item)
+		}
+	}
+	return toMove, rejected
+}
+
+// moveBatchCmd starts a Dropbox batch move of items into dest. Small batches
+// may finish immediately; otherwise the job is polled with moveCheckCmd.
+// rejected entries are carried into the final result. With autorename, names
+// already taken in dest get a suffix instead of failing.
+func moveBatchCmd(config *Config, items []FileItem, dest string, rejected []ItemError, autorename bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := startMove(config, items, dest, autorename)
+		if done, ok := msg.(MoveCompleteMsg); ok {
+			done.Errors = append(rejected, done.Errors...)
+			return done
+		}
+		return msg
+	}
+}
+
+// startMove launches the batch move, returning a moveJobMsg to poll or the
+// finished MoveCompleteMsg.
+func startMove(config *Config, items []FileItem, dest string, autorename bool) tea.Msg {
+	dbx, err := newFilesClient(config)
+	if err != nil {
+		return moveFailed(items, dest, err)
+	}
+	entries := make([]*files.RelocationPath, len(items))
+	for i, item := range items {
+		entries[i] = files.NewRelocationPath(item.Path, path.Join(dest, item.Name))
+	}
+	arg := files.NewMoveBatchArg(entries)
+	arg.Autorename = autorename
+	launch, err := dbx.MoveBatchV2(arg)
+	if err != nil {
+		return moveFailed(items, dest, err)
+	}
+	switch launch.Tag {
+	case files.RelocationBatchV2LaunchComplete:
+		return moveOutcome(items, dest, launch.Complete)
+	case files.RelocationBatchV2LaunchAsyncJobId:
+		return moveJobMsg{jobID: launch.AsyncJobId, items: items, dest: dest}
+	default:
+		return moveFailed(items, dest, fmt.Errorf("unexpected response %q", launch.Tag))
+	}
+}
+
+// moveCheckCmd waits batchPollInterval, then checks on a running batch move.
+func moveCheckCmd(config *Config, job moveJobMsg) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(batchPollInterval)
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return moveFailed(job.items, job.dest, err)
+		}
+		return checkMoveJob(dbx, job)
+	}
+}
+
+// checkMoveJob polls a batch move once, returning the next moveJobMsg while
+// it's running or a MoveCompleteMsg once it has finished.
+func checkMoveJob(dbx files.Client, job moveJobMsg) tea.Msg {
+	status, err := dbx.MoveBatchCheckV2(async.NewPollArg(job.jobID))
+	if err != nil {
+		return moveFailed(job.items, job.dest, err)
+	}
+	switch status.Tag {
+	case files.RelocationBatchV2JobStatusInProgress:
+		job.polls++
+		return job
+	case files.RelocationBatchV2JobStatusComplete:
+		return moveOutcome(job.items, job.dest, status.Complete)
+	default:
+		return moveFailed(job.items, job.dest, fmt.Errorf("unexpected status %q", status.Tag))
+	}
+}
+
+// moveOutcome pairs each requested item with its entry in the batch result,
+// which Dropbox returns in the same order as the request.
+func moveOutcome(items []FileItem, dest string, result *files.RelocationBatchV2Result) MoveCompleteMsg {
+	msg := MoveCompleteMsg{Dest: dest, NewPaths: make(map[string]string)}
+	for i, item := range items {
+		if i >= len(result.Entries) {
+			msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: no result returned", item.Name)})
+			continue
+		}
+		entry := result.Entries[i]
+		if entry.Tag == files.RelocationBatchResultEntrySuccess {
+			msg.Moved = append(msg.Moved, item)
+			msg.NewPaths[item.Path] = movedPath(entry.Success, path.Join(dest, item.Name))
+			continue
+		}
+		msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: %s", item.Name, describeRelocationError(entry.Failure))})
+	}
+	return msg
+}
+
+// movedPath returns the path Dropbox reports an entry was moved to, or
+// fallback if it didn't say.
+func movedPath(meta files.IsMetadata, fallback string) string {
+	switch v := meta.(type) {
+	case *files.FileMetadata:
+		return v.PathLower
+	case *files.FolderMetadata:
+		return v.PathLower
+	}
+	return fallback
+}
+
+// moveFailed reports every item as failed when the whole batch did.
+func moveFailed(items []FileItem, dest string, err error) MoveCompleteMsg {
+	msg := MoveCompleteMsg{Dest: dest}
+	for _, item := range items {
+		msg.Errors = append(msg.Errors, ItemError{Item: item, Err: fmt.Sprintf("%s: %v", item.Name, err)})
+	}
+	return msg
+}
+
+// describeRelocationError renders a per-entry move failure such as
+// "to/conflict" or "from_lookup/not_found".
+func describeRelocationError(e *files.RelocationBatchErrorEntry) string {
+	if e == nil {
+		return "unknown error"
+	}
+	r := e.RelocationError
+	switch {
+	case r == nil:
+		return e.Tag
+	case r.FromLookup != nil:
+		return r.Tag + "/" + r.FromLookup.Tag
+	case r.FromWrite != nil:
+		return r.Tag + "/" + r.FromWrite.Tag
+	case r.To != nil:
+		return r.Tag + "/" + r.To.Tag
+	default:
+		return r.Tag
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+func TestSubmitMoveRejectsImpossibleMoves(t *testing.T) {
+	m := initialModel(&Config{})
+	m.pendingMove = []FileItem{
+		{Name: "a.txt", Path: "/docs/a.txt"},
+		{Name: "docs", Path: "/docs", IsFolder: true},
+	}
+
+	next, cmd := m.submitMove("docs/")
+	if next.(Model).job != "" {
+		t.Fatal("nothing movable, so no job should start")
+	}
+	done := cmd().(MoveCompleteMsg)
+	if done.Dest != "/docs" || len(done.Moved) != 0 || len(done.Errors) != 2 {
+		t.Fatalf("got %+v, want both entries rejected", done)
+	}
+	if got := done.Errors[0].Err; got != "a.txt: already in /docs/" {
+		t.Errorf("first error = %q", got)
+	}
+	if got := done.Errors[1].Err; got != "docs: can't move a folder into itself" {
+		t.Errorf("second error = %q", got)
+	}
+}
+
+func TestMoveOutcome(t *testing.T) {
+	items := []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}}
+	result := &files.RelocationBatchV2Result{Entries: []*files.RelocationBatchResultEntry{
+		{Tagged: dropbox.Tagged{Tag: files.RelocationBatchResultEntrySuccess}},
+		{
+			Tagged: dropbox.Tagged{Tag: files.RelocationBatchResultEntryFailure},
+			Failure: &files.RelocationBatchErrorEntry{
+				Tagged: dropbox.Tagged{Tag: files.RelocationBatchErrorEntryRelocationError},
+				RelocationError: &files.RelocationError{
+					Tagged: dropbox.Tagged{Tag: files.RelocationErrorTo},
+					To:     &files.WriteError{Tagged: dropbox.Tagged{Tag: files.WriteErrorConflict}},
+				},
+			},
+		},
+	}}
+
+	msg := moveOutcome(items, "/dest", result)
+	if len(msg.Moved) != 1 || msg.Moved[0].Name != "a" {
+		t.Errorf("moved = %v, want [a]", msg.Moved)
+	}
+	if len(msg.Errors) != 1 || msg.Errors[0].Err != "b: to/conflict" {
+		t.Errorf("errors = %v, want b: to/conflict", msg.Errors)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// OfflineMsg reports that loading Path failed because Dropbox couldn't be
+// reached, or (with TimedOut) didn't answer within list_timeout.
+type OfflineMsg struct {
+	Path     string
+	TimedOut bool
+}
+
+// networkErrorHints are fragments of the messages Go's network stack gives
+// when there's no connection. They catch errors that reach us flattened to
+// text, as the OAuth token refresh does.
+var networkErrorHints = []string{
+	"no such host",
+	"dial tcp",
+	"network is unreachable",
+	"connection refused",
+	"i/o timeout",
+	"TLS handshake timeout",
+}
+
+// isNetworkError reports whether err means Dropbox couldn't be reached at
+// all (DNS or connection failures), rather than an error from Dropbox itself.
+func isNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return true
+	}
+	msg := err.Error()
+	for _, hint := range networkErrorHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOffline explains that the network is down and remembers the folder
+// that didn't load, for refresh to retry. The folder on screen stays as it
+// was, and cached folders still open without the network.
+func (m Model) handleOffline(msg OfflineMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.loadingMore = false
+	m.offline = true
+	m.offlinePath = msg.Path
+	m.loadFailed = !m.connected
+	m.error = "No network connection — check your internet, then press " + m.keys.describe(actionRefresh) + " to retry"
+	if msg.TimedOut {
+		folder := msg.Path
+		if folder == "" {
+			folder = "/"
+		}
+		m.error = "Dropbox took too long to list " + folder + " — press " + m.keys.describe(actionRefresh) + " to retry"
+	}
+	m.errorTime = time.Now()
+	return m, nil
+}
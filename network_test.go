@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIsNetworkError(t *testing.T) {
+	dns := &net.DNSError{Err: "no such host", Name: "api.dropboxapi.com"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"dns", dns, true},
+		{"wrapped", &url.Error{Op: "Post", URL: "https://api.dropboxapi.com", Err: dns}, true},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: network is unreachable")}, true},
+		{"flattened", fmt.Errorf("oauth2: cannot fetch token: Post \"https://api.dropboxapi.com/oauth2/token\": dial tcp: lookup api.dropboxapi.com: no such host"), true},
+		{"api error", errors.New("path/not_found/"), false},
+	}
+	for _, tt := range tests {
+		if got := isNetworkError(tt.err); got != tt.want {
+			t.Errorf("%s: isNetworkError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOfflineRetry(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/docs", []FileItem{{Name: "a.txt", Path: "/docs/a.txt"}})
+	m.loading = true
+
+	// Opening /docs/sub failed: /docs stays on screen.
+	next, _ := m.Update(OfflineMsg{Path: "/docs/sub"})
+	m = next.(Model)
+	if m.loading || m.currentPath != "/docs" || len(m.visible) != 1 {
+		t.Errorf("the folder on screen should stay put (loading %v, path %q)", m.loading, m.currentPath)
+	}
+	if !strings.Contains(m.error, "No network connection") || !strings.Contains(m.error, "R to retry") {
+		t.Errorf("error = %q", m.error)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = next.(Model)
+	if cmd == nil || m.offline || !m.loading {
+		t.Error("refresh should retry the folder that didn't load")
+	}
+}
+
+func TestListingTimedOut(t *testing.T) {
+	m := initialModel(&Config{})
+	m.loading = true
+	next, _ := m.Update(OfflineMsg{Path: "/huge", TimedOut: true})
+	m = next.(Model)
+	if !m.offline || m.offlinePath != "/huge" {
+		t.Error("a timed-out folder should be retried on refresh")
+	}
+	if !strings.Contains(m.error, "took too long to list /huge") || !strings.Contains(m.error, "R to retry") {
+		t.Errorf("error = %q", m.error)
+	}
+}
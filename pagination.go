@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxListPageSize is the most entries Dropbox returns in one listing page.
+const maxListPageSize = 2000
+
+// moreMargin is how close to the end of what's loaded the list scrolls
+// before the next page of a listing is fetched.
+const moreMargin = 5
+
+// FilesMoreMsg carries the next page of a folder listing. Cursor is set when
+// there are more pages still.
+type FilesMoreMsg struct {
+	Path   string
+	Files  []FileItem
+	Cursor string
+}
+
+// loadMoreCmd fetches the page of path's listing that cursor points to.
+func loadMoreCmd(config *Config, path, cursor string) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		items, next, err := listFolderMore(dbx, cursor)
+		if isTimeoutError(err) {
+			return OfflineMsg{Path: path, TimedOut: true}
+		}
+		if isNetworkError(err) {
+			return OfflineMsg{Path: path}
+		}
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to load more of '%s': %v", path, err)}
+		}
+		return FilesMoreMsg{Path: path, Files: items, Cursor: next}
+	}
+}
+
+// moreCmd fetches the next page of the current folder once the part of the
+// list on screen (see listWindow) nears the end of what's loaded, unless a
+// page is already on its way.
+func (m *Model) moreCmd() tea.Cmd {
+	_, end := m.listWindow()
+	if m.moreCursor == "" || m.loadingMore || end < len(m.visible)-moreMargin {
+		return nil
+	}
+	m.loadingMore = true
+	return loadMoreCmd(&m.config, m.currentPath, m.moreCursor)
+}
+
+// handleFilesMore adds the next page of a listing to the folder on screen,
+// keeping the cursor where it was. The listing is cached
+// once its last page arrives.
+func (m Model) handleFilesMore(msg FilesMoreMsg) (tea.Model, tea.Cmd) {
+	if msg.Path != m.currentPath || !m.loadingMore {
+		return m, nil // the user moved on; the page is for another folder
+	}
+	m.loadingMore = false
+	cursorPath := m.cursorPath()
+	m.files = append(m.files, msg.Files...)
+	m.refreshVisible()
+	m.restoreCursor(cursorPath)
+	m.moreCursor = msg.Cursor
+	if m.moreCursor == "" {
+		m.cacheFolder(m.currentPath, m.files)
+	}
+	cmd := m.moreCmd()
+	return m, cmd
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListingLoadsMoreNearTheEnd(t *testing.T) {
+	m := initialModel(&Config{})
+	m.height = minHeight // room for 5 entries
+	var first []FileItem
+	for i := 0; i < 20; i++ {
+		name := string(rune('a' + i))
+		first = append(first, FileItem{Name: name, Path: "/big/" + name})
+	}
+	next, _ := m.Update(FilesLoadedMsg{Path: "/big", Files: first, Cursor: "page2"})
+	m = next.(Model)
+	if m.loadingMore {
+		t.Fatal("the next page shouldn't load while the cursor is at the top")
+	}
+	if _, cached := m.folderCache["/big"]; cached {
+		t.Error("a partial listing shouldn't be cached")
+	}
+	if !strings.Contains(m.renderFileList(), "more entries") {
+		t.Error("the list should say there's more to load")
+	}
+
+	m.cursor = 13
+	if m.moreCmd() != nil {
+		t.Fatal("the next page shouldn't load while the end is well off screen")
+	}
+	m.cursor = 14
+	if m.moreCmd() == nil || !m.loadingMore {
+		t.Fatal("scrolling near the end should fetch the next page")
+	}
+	if m.moreCmd() != nil {
+		t.Error("only one page should be fetched at a time")
+	}
+
+	selectAt(&m, 14) // "o"
+	next, _ = m.Update(FilesMoreMsg{Path: "/big", Files: []FileItem{{Name: "u", Path: "/big/u"}}})
+	m = next.(Model)
+	if len(m.visible) != 21 || m.moreCursor != "" || m.loadingMore {
+		t.Errorf("visible = %d, cursor %q, loading %v; want all 21 loaded", len(m.visible), m.moreCursor, m.loadingMore)
+	}
+	if m.visible[m.cursor].Name != "o" || !m.isSelected(m.visible[m.cursor]) {
+		t.Error("the cursor and selection should stay on their entries")
+	}
+	if _, cached := m.folderCache["/big"]; !cached {
+		t.Error("the full listing should be cached once the last page arrives")
+	}
+}
+
+func TestStalePageIgnored(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/other", []FileItem{{Name: "x", Path: "/other/x"}})
+	next, _ := m.Update(FilesMoreMsg{Path: "/big", Files: []FileItem{{Name: "k", Path: "/big/k"}}})
+	if m = next.(Model); len(m.files) != 1 {
+		t.Error("a page for a folder no longer on screen should be dropped")
+	}
+}
+
+func TestFailedPageRetried(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/big", []FileItem{{Name: "a", Path: "/big/a"}})
+	m.moreCursor = "page2"
+	if m.moreCmd() == nil {
+		t.Fatal("the next page should be fetched")
+	}
+	next, _ := m.Update(ErrorMsg{Error: "Failed to load more of '/big': boom"})
+	m = next.(Model)
+	if m.loadingMore || m.moreCmd() == nil {
+		t.Error("a page that failed to load should be fetched again")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// defaultPaperFormat is the export format used for Paper docs unless one is
+// chosen when downloading.
+const defaultPaperFormat = "markdown"
+
+// paperFormatExts maps each supported Paper export format to the extension
+// the exported file is saved with.
+var paperFormatExts = map[string]string{
+	"markdown": ".md",
+	"html":     ".html",
+}
+
+// isExportOnly reports whether a file has no downloadable content of its own
+// and must be exported instead, as Paper docs do.
+func isExportOnly(f *files.FileMetadata) bool {
+	return !f.IsDownloadable && f.ExportInfo != nil
+}
+
+// validPaperFormat reports whether format is a supported Paper export format.
+func validPaperFormat(format string) bool {
+	_, ok := paperFormatExts[format]
+	return ok
+}
+
+// itemLocalPath returns where a file is saved in the download directory. Paper
+// docs are saved with the extension of the export format in place of .paper.
+// With organize_by_extension, files go into their extension's folder and
+// folders aren't recreated, so a folder maps to the download directory.
+func itemLocalPath(config *Config, item FileItem) (string, error) {
+	var localPath string
+	var err error
+	switch {
+	case config.OrganizeByExtension && item.IsFolder:
+		return filepath.Clean(config.DownloadPath), nil
+	case config.OrganizeByExtension:
+		name := path.Base(item.Path)
+		if item.Exportable {
+			name = exportedName(name, config.PaperFormat)
+		}
+		localPath, err = localDownloadPath(config.DownloadPath, "/"+extensionFolder(name)+"/"+name)
+	default:
+		localPath, err = localDownloadPath(config.DownloadPath, item.Path)
+		if err == nil && item.Exportable {
+			localPath = exportedName(localPath, config.PaperFormat)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return fitPathLimits(localPath, runtime.GOOS)
+}
+
+// exportedName replaces a Paper doc's extension with the one for format, so
+// "notes.paper" exported as markdown becomes "notes.md".
+func exportedName(name, format string) string {
+	ext := filepath.Ext(name)
+	if !strings.EqualFold(ext, ".paper") {
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + paperFormatExts[format]
+}
+
+// exportToFile exports a Paper doc in format and saves it to localPath,
+// counting bytes into counter like downloadToFile.
+func exportToFile(dbx files.Client, dropboxPath, localPath, format string, mode os.FileMode, counter *atomic.Int64) error {
+	arg := files.NewExportArg(dropboxPath)
+	arg.ExportFormat = format
+	_, contents, err := dbx.Export(arg)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return writeLocalFile(contents, localPath, mode, counter)
+}
+
+// startDownload downloads files, first asking which format to export Paper
+// docs in when any are among them. Paper docs found inside selected folders
+// use the same choice, or the configured format if there was no prompt. A
+// shallow download takes only the files directly inside selected folders.
+func (m Model) startDownload(selected []FileItem, shallow bool) (tea.Model, tea.Cmd) {
+	for _, file := range selected {
+		if file.Exportable {
+			m.pendingDownload = selected
+			m.pendingShallow = shallow
+			m.openPrompt(promptPaperFormat)
+			return m, nil
+		}
+	}
+	return m, func() tea.Msg {
+		return downloadMsg(selected, "", shallow)
+	}
+}
+
+// downloadMsg starts downloading selected, exporting Paper docs in
+// paperFormat (or the configured one if it's "").
+func downloadMsg(selected []FileItem, paperFormat string, shallow bool) DownloadMsg {
+	msg := DownloadMsg{Files: selected, PaperFormat: paperFormat}
+	if shallow {
+		msg.MaxDepth = new(int)
+	}
+	return msg
+}
+
+// choosePaperFormat handles a key pressed at the Paper export format prompt:
+// m for Markdown, h for HTML. Other keys leave the prompt open.
+func (m Model) choosePaperFormat(key string) (tea.Model, tea.Cmd) {
+	var format string
+	switch key {
+	case "m":
+		format = "markdown"
+	case "h":
+		format = "html"
+	default:
+		return m, nil
+	}
+	selected, shallow := m.pendingDownload, m.pendingShallow
+	m.pendingDownload, m.pendingShallow = nil, false
+	m.closePrompt()
+	return m, func() tea.Msg {
+		return downloadMsg(selected, format, shallow)
+	}
+}
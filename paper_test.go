@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		name, format, want string
+	}{
+		{"notes.paper", "markdown", "notes.md"},
+		{"Plan.PAPER", "html", "Plan.html"},
+		{"untitled", "markdown", "untitled.md"},
+		{"v1.2 notes", "html", "v1.2 notes.html"},
+	}
+	for _, tt := range tests {
+		if got := exportedName(tt.name, tt.format); got != tt.want {
+			t.Errorf("exportedName(%q, %q) = %q, want %q", tt.name, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestPaperFormatPrompt(t *testing.T) {
+	m := initialModel(&Config{PaperFormat: defaultPaperFormat})
+	m.setFiles("", []FileItem{
+		{Name: "a.txt", Path: "/a.txt"},
+		{Name: "notes.paper", Path: "/notes.paper", Exportable: true},
+	})
+	selectAt(&m, 0, 1)
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = next.(Model)
+	if m.prompt != promptPaperFormat || cmd != nil {
+		t.Fatal("downloading a Paper doc should ask for an export format first")
+	}
+
+	// Keys that don't answer the prompt are ignored.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = next.(Model)
+	if m.prompt != promptPaperFormat {
+		t.Fatal("unrelated key closed the prompt")
+	}
+
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	m = next.(Model)
+	if m.prompt != promptNone || cmd == nil {
+		t.Fatal("choosing a format should start the download")
+	}
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || msg.PaperFormat != "html" || len(msg.Files) != 2 {
+		t.Errorf("got %#v, want a DownloadMsg for both files as html", msg)
+	}
+}
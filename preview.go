@@ -0,0 +1,210 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewDelay is how long the cursor has to rest on a folder before it's
+// listed for the preview, so scrolling past folders doesn't list each one.
+const previewDelay = 300 * time.Millisecond
+
+// previewEntries is how many of a folder's entries the preview shows.
+const previewEntries = 10
+
+// previewWidth is how many columns the preview panel takes, border included,
+// and minPreviewTermWidth how wide the terminal must be to fit it beside the
+// list.
+const (
+	previewWidth        = 32
+	minPreviewTermWidth = 90
+)
+
+// folderPreview is the start of a folder's listing, for the preview panel.
+// Stored previews keep the entries as listed; preview filters and sorts them
+// as the list is shown at the time.
+type folderPreview struct {
+	files []FileItem // at most previewEntries, or the whole cached listing
+	more  bool       // whether the folder holds more than files
+	err   string     // why it couldn't be listed, if it couldn't
+}
+
+// previewTickMsg fires once the cursor may have rested on path for
+// previewDelay.
+type previewTickMsg struct {
+	path string
+}
+
+// FolderPreviewMsg carries the first entries of a folder for the preview.
+type FolderPreviewMsg struct {
+	Path  string
+	Files []FileItem
+	More  bool
+	Err   string
+}
+
+// previewShown reports whether the preview panel is on and the terminal is
+// wide enough for it.
+func (m Model) previewShown() bool {
+	return m.showPreview && m.width >= minPreviewTermWidth
+}
+
+// listWidth is how many columns the file list has, leaving room for the
+// preview panel when it's shown.
+func (m Model) listWidth() int {
+	if m.previewShown() {
+		return m.width - previewWidth
+	}
+	return m.width
+}
+
+// preview returns what the preview shows for folder p: its cached listing
+// when there is one, so no call is made, or the entries fetched for it, with
+// hidden entries left out and sorted as the list is now.
+func (m Model) preview(p string) (folderPreview, bool) {
+	pv, ok := m.previews[p]
+	if p == m.previewLastPath {
+		pv, ok = m.previewLast, true
+	}
+	if files, cached := m.folderCache[p]; cached {
+		pv, ok = folderPreview{files: files}, true
+	}
+	if !ok || pv.err != "" {
+		return pv, ok
+	}
+	var listed []FileItem
+	for _, file := range pv.files {
+		if m.showHidden || !isHidden(file.Name) {
+			listed = append(listed, file)
+		}
+	}
+	sortEntries(listed, m.foldersFirst)
+	more := pv.more || len(listed) > previewEntries
+	return folderPreview{files: listed[:min(len(listed), previewEntries)], more: more}, true
+}
+
+// forgetLastPreview drops the preview held only for the folder under the
+// cursor (see handleFolderPreview) once the cursor has left it, so coming
+// back lists the folder again.
+func (m *Model) forgetLastPreview() {
+	if m.previewLastPath == "" {
+		return
+	}
+	if m.cursor >= len(m.visible) || m.visible[m.cursor].Path != m.previewLastPath {
+		m.previewLast, m.previewLastPath = folderPreview{}, ""
+	}
+}
+
+// previewCmd schedules listing the folder under the cursor for the preview,
+// when the panel is shown and the folder isn't cached or previewed yet. Only
+// one folder is listed at a time; the rest wait for the cursor to come back.
+func (m Model) previewCmd() tea.Cmd {
+	if !m.previewShown() || m.previewPending != "" || m.cursor >= len(m.visible) {
+		return nil
+	}
+	folder := m.visible[m.cursor]
+	if !folder.IsFolder {
+		return nil
+	}
+	if _, ok := m.preview(folder.Path); ok {
+		return nil
+	}
+	return tea.Tick(previewDelay, func(time.Time) tea.Msg {
+		return previewTickMsg{path: folder.Path}
+	})
+}
+
+// handlePreviewTick lists msg.path if the cursor is still on it.
+func (m Model) handlePreviewTick(msg previewTickMsg) (tea.Model, tea.Cmd) {
+	if !m.previewShown() || m.previewPending != "" || m.cursor >= len(m.visible) || m.visible[m.cursor].Path != msg.path {
+		return m, nil
+	}
+	if _, ok := m.preview(msg.path); ok {
+		return m, nil
+	}
+	m.previewPending = msg.path
+	return m, folderPreviewCmd(&m.config, msg.path)
+}
+
+// handleFolderPreview stores a folder's preview, then moves on to the folder
+// under the cursor if that one hasn't been previewed. A failed listing, or
+// any listing with disable_cache, isn't stored: it's held only while the
+// cursor stays on the folder, so it's tried again on the way back.
+func (m Model) handleFolderPreview(msg FolderPreviewMsg) (tea.Model, tea.Cmd) {
+	pv := folderPreview{files: msg.Files, more: msg.More, err: msg.Err}
+	m.previewPending = ""
+	switch {
+	case msg.Err != "" || m.config.DisableCache:
+		if m.cursor < len(m.visible) && m.visible[m.cursor].Path == msg.Path {
+			m.previewLast, m.previewLastPath = pv, msg.Path
+		}
+	default:
+		if m.previews == nil {
+			m.previews = make(map[string]folderPreview)
+		}
+		m.previews[msg.Path] = pv
+	}
+	return m, m.previewCmd()
+}
+
+// folderPreviewCmd lists the first page of the folder at p for the preview.
+// Dropbox lists in no particular order, so these are some of its entries
+// rather than the first by name; preview sorts them as the list would be.
+func folderPreviewCmd(config *Config, p string) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return FolderPreviewMsg{Path: p, Err: err.Error()}
+		}
+		items, cursor, err := listFolderPage(dbx, p, previewEntries)
+		if err != nil {
+			return FolderPreviewMsg{Path: p, Err: err.Error()}
+		}
+		return FolderPreviewMsg{Path: p, Files: items, More: cursor != ""}
+	}
+}
+
+// renderPreview renders the preview panel for the entry under the cursor,
+// height lines tall.
+func (m Model) renderPreview(height int) string {
+	muted := lipgloss.NewStyle().Foreground(m.config.Theme.Muted)
+	room := previewWidth - 3 // border and padding
+	var lines []string
+	if m.cursor < len(m.visible) && m.visible[m.cursor].IsFolder {
+		folder := m.visible[m.cursor]
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render(truncateMiddle(folder.Name+"/", room)))
+		pv, ok := m.preview(folder.Path)
+		switch {
+		case !ok:
+			lines = append(lines, muted.Render("Loading..."))
+		case pv.err != "":
+			lines = append(lines, muted.Render(truncateMiddle("Couldn't list: "+pv.err, room)))
+		case len(pv.files) == 0 && !pv.more:
+			lines = append(lines, muted.Render("Empty"))
+		default:
+			for _, file := range pv.files {
+				icon := "📄"
+				if file.IsFolder {
+					icon = "📁"
+				}
+				lines = append(lines, icon+" "+truncateMiddle(file.Name, room-3))
+			}
+			if pv.more {
+				lines = append(lines, muted.Render("…"))
+			}
+		}
+	} else {
+		lines = append(lines, muted.Render("Move onto a folder to preview it"))
+	}
+	return lipgloss.NewStyle().
+		Width(previewWidth - 1).
+		Height(height).
+		PaddingLeft(1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(m.config.Theme.Muted).
+		Render(strings.Join(lines, "\n"))
+}
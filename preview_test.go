@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFolderPreview(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width, m.height = 100, 40
+	m.setFiles("", []FileItem{
+		{Name: "cached", Path: "/cached", IsFolder: true},
+		{Name: "fresh", Path: "/fresh", IsFolder: true},
+		{Name: "notes.txt", Path: "/notes.txt"},
+	})
+	m.cacheFolder("/cached", []FileItem{{Name: "z.txt", Path: "/cached/z.txt"}, {Name: "inner", Path: "/cached/inner", IsFolder: true}})
+	if m.previewCmd() != nil || strings.Contains(m.View(), "z.txt") {
+		t.Fatal("the preview should be off until toggled")
+	}
+
+	updated, _ := m.runAction(actionTogglePreview, 1)
+	m = updated.(Model)
+	// A cached folder is previewed straight away, sorted as the list would be.
+	if m.previewCmd() != nil {
+		t.Error("a cached folder shouldn't be listed again")
+	}
+	view := m.View()
+	if inner, z := strings.Index(view, "inner"), strings.Index(view, "z.txt"); inner < 0 || z < inner {
+		t.Errorf("preview should list inner then z.txt:\n%s", view)
+	}
+
+	// Others are listed once the cursor rests on them, and not if it moved on.
+	m.cursor = 1
+	if m.previewCmd() == nil || !strings.Contains(m.View(), "Loading...") {
+		t.Fatal("an uncached folder should be scheduled for listing")
+	}
+	next, cmd := m.handlePreviewTick(previewTickMsg{path: "/cached"})
+	if m = next.(Model); cmd != nil || m.previewPending != "" {
+		t.Error("a folder the cursor left shouldn't be listed")
+	}
+	next, cmd = m.handlePreviewTick(previewTickMsg{path: "/fresh"})
+	if m = next.(Model); cmd == nil || m.previewPending != "/fresh" {
+		t.Fatal("the folder under the cursor should be listed")
+	}
+	next, _ = m.handleFolderPreview(FolderPreviewMsg{Path: "/fresh", Files: []FileItem{{Name: "photo.jpg", Path: "/fresh/photo.jpg"}}, More: true})
+	m = next.(Model)
+	if m.previewPending != "" || m.previewCmd() != nil {
+		t.Error("a previewed folder shouldn't be listed again")
+	}
+	if view := m.View(); !strings.Contains(view, "photo.jpg") || !strings.Contains(view, "…") {
+		t.Errorf("preview should show the listed entry and that there are more:\n%s", view)
+	}
+
+	// Too narrow a terminal leaves the panel out.
+	m.width = minPreviewTermWidth - 1
+	if m.previewCmd() != nil || strings.Contains(m.View(), "photo.jpg") || m.listWidth() != m.width {
+		t.Error("the preview shouldn't show in a narrow terminal")
+	}
+}
+
+func TestFolderPreviewFreshness(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width, m.height = 100, 40
+	m.showPreview = true
+	m.setFiles("", []FileItem{
+		{Name: "a", Path: "/a", IsFolder: true},
+		{Name: "b", Path: "/b", IsFolder: true},
+	})
+	next, _ := m.handleFolderPreview(FolderPreviewMsg{Path: "/a", Files: []FileItem{
+		{Name: "z.txt", Path: "/a/z.txt"},
+		{Name: ".hidden", Path: "/a/.hidden"},
+		{Name: "sub", Path: "/a/sub", IsFolder: true},
+	}})
+	m = next.(Model)
+
+	// Hidden entries and order follow the list's current settings.
+	names := func() string {
+		pv, _ := m.preview("/a")
+		var out []string
+		for _, file := range pv.files {
+			out = append(out, file.Name)
+		}
+		return strings.Join(out, " ")
+	}
+	if got := names(); got != "sub z.txt" {
+		t.Errorf("preview = %q, want sub z.txt", got)
+	}
+	m.showHidden, m.foldersFirst = true, false
+	if got := names(); got != ".hidden sub z.txt" {
+		t.Errorf("with hidden shown, mixed: preview = %q", got)
+	}
+
+	// Refreshing the folder forgets its subfolders' previews.
+	updated, _ := m.runAction(actionRefresh, 0)
+	if m = updated.(Model); len(m.previews) != 0 {
+		t.Error("refresh should drop the previews below the folder")
+	}
+
+	// A failed listing shows while the cursor stays, and is retried after
+	// it leaves and comes back.
+	next, _ = m.handleFolderPreview(FolderPreviewMsg{Path: "/a", Err: "boom"})
+	m = next.(Model)
+	if pv, ok := m.preview("/a"); !ok || pv.err != "boom" || m.previews["/a"].err != "" {
+		t.Fatalf("the error should show but not be stored: %+v", pv)
+	}
+	press := func(key string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(Model)
+	}
+	press("j")
+	press("k")
+	if _, ok := m.preview("/a"); ok {
+		t.Error("coming back to a folder that failed should list it again")
+	}
+
+	// With disable_cache nothing is stored either.
+	m.config.DisableCache = true
+	next, _ = m.handleFolderPreview(FolderPreviewMsg{Path: "/a", Files: []FileItem{{Name: "x", Path: "/a/x"}}})
+	m = next.(Model)
+	if _, ok := m.previews["/a"]; ok {
+		t.Error("disable_cache shouldn't keep previews")
+	}
+	if _, ok := m.preview("/a"); !ok {
+		t.Error("the preview should still show while the cursor is on it")
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// throughputWindow is how far back the moving-average download speed looks.
+const throughputWindow = 5 * time.Second
+
+// progressTickMsg asks the UI to sample download progress and redraw.
+type progressTickMsg struct{}
+
+// progressTickCmd schedules the next progress sample, roughly once a second.
+func progressTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return progressTickMsg{}
+	})
+}
+
+// etaMinSamples is how many samples (about one per second) are needed before
+// an ETA is shown; early speed readings are too noisy to extrapolate from.
+const etaMinSamples = 3
+
+// progressBarWidth is the width of the download progress bar in cells.
+const progressBarWidth = 30
+
+// downloadProgress tracks a running download job. The job adds to bytes and
+// total from its own goroutine; samples belong to the UI, which records one per
+// tick.
+type downloadProgress struct {
+	bytes atomic.Int64 // bytes received so far
+	total atomic.Int64 // bytes the whole job expects to receive; 0 until known
+
+	// files is how many files the job has (0 until known) and completed how
+	// many of them have finished, whatever the outcome.
+	files     atomic.Int64
+	completed atomic.Int64
+
+	// resumed marks a job restarted from the download queue. Its files
+	// already downloaded by the earlier run are counted as done up front,
+	// their bytes in preloaded rather than bytes, so they don't count toward
+	// the rate. So are the bytes a file resumes from in its .part file (see
+	// downloadToFile).
+	resumed   bool
+	preloaded atomic.Int64
+
+	// cancelFile cuts short the file being downloaded, when one is (see
+	// startFile and skipFile).
+	mu         sync.Mutex
+	cancelFile context.CancelFunc
+
+	// onFile, if set, is called from the job's goroutine as each file
+	// finishes (see fileDone).
+	onFile func(FileDoneMsg)
+
+	// Folders listed and files found so far while selected folders are
+	// scanned, before anything is downloaded. onScan, if set, is called with
+	// the counts at most once per scanReportInterval (see folderScanned).
+	scannedFolders atomic.Int64
+	scannedFiles   atomic.Int64
+	lastScanReport atomic.Int64 // UnixNano of the last onScan call
+	onScan         func(ScanProgressMsg)
+
+	samples []progressSample
+}
+
+// fileOutcome is how one file of a download job ended.
+type fileOutcome int
+
+const (
+	fileDownloaded fileOutcome = iota
+	fileSkipped
+	fileFailed
+)
+
+// FileDoneMsg reports that one file of a running download job has finished.
+type FileDoneMsg struct {
+	Item    FileItem
+	Outcome fileOutcome
+}
+
+// scanReportInterval is the least time between two ScanProgressMsgs, so a
+// fast scan doesn't flood the UI.
+const scanReportInterval = 100 * time.Millisecond
+
+// ScanProgressMsg reports how far the scan of selected folders has got.
+type ScanProgressMsg struct {
+	Folders, Files int64
+}
+
+// folderScanned counts a listed folder holding files files, reporting the
+// new totals to onScan unless it was called too recently. Several listings
+// run at once, so it's safe to call concurrently.
+func (p *downloadProgress) folderScanned(files int) {
+	folders := p.scannedFolders.Add(1)
+	found := p.scannedFiles.Add(int64(files))
+	if p.onScan == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := p.lastScanReport.Load()
+	if now-last < int64(scanReportInterval) || !p.lastScanReport.CompareAndSwap(last, now) {
+		return
+	}
+	p.onScan(ScanProgressMsg{Folders: folders, Files: found})
+}
+
+// scanFinished reports the final totals to onScan once every selected folder
+// is listed, since folderScanned may have held back the last of them.
+func (p *downloadProgress) scanFinished() {
+	folders := p.scannedFolders.Load()
+	if p.onScan == nil || folders == 0 {
+		return
+	}
+	p.onScan(ScanProgressMsg{Folders: folders, Files: p.scannedFiles.Load()})
+}
+
+// fileDone counts a finished file and reports it to onFile, if anyone is
+// listening.
+func (p *downloadProgress) fileDone(item FileItem, outcome fileOutcome) {
+	p.completed.Add(1)
+	if p.onFile != nil {
+		p.onFile(FileDoneMsg{Item: item, Outcome: outcome})
+	}
+}
+
+// startFile returns the context the next file downloads under, which
+// skipFile cancels. The caller calls done once the file is finished.
+func (p *downloadProgress) startFile() (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.cancelFile = cancel
+	p.mu.Unlock()
+	return ctx, func() {
+		p.mu.Lock()
+		p.cancelFile = nil
+		p.mu.Unlock()
+		cancel()
+	}
+}
+
+// skipFile cancels the file being downloaded, so the job moves on to the
+// next. It reports false if no file is downloading.
+func (p *downloadProgress) skipFile() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancelFile == nil {
+		return false
+	}
+	p.cancelFile()
+	p.cancelFile = nil
+	return true
+}
+
+// downloadTally is the running count of finished files the UI shows while a
+// job runs, built from FileDoneMsgs.
+type downloadTally struct {
+	downloaded, skipped, failed int
+	last                        string // path of the most recently finished file
+}
+
+// add counts one finished file.
+func (t *downloadTally) add(msg FileDoneMsg) {
+	switch msg.Outcome {
+	case fileDownloaded:
+		t.downloaded++
+	case fileSkipped:
+		t.skipped++
+	case fileFailed:
+		t.failed++
+	}
+	t.last = msg.Item.Path
+}
+
+// progressSample is the byte count observed at a point in time.
+type progressSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// newDownloadProgress starts tracking a job that begins at start.
+func newDownloadProgress(start time.Time) *downloadProgress {
+	return &downloadProgress{samples: []progressSample{{at: start}}}
+}
+
+// sample records the current byte count, dropping samples that have aged out
+// of the throughput window (always keeping at least one to measure from).
+func (p *downloadProgress) sample(now time.Time) {
+	p.samples = append(p.samples, progressSample{at: now, bytes: p.bytes.Load()})
+	for len(p.samples) > 2 && now.Sub(p.samples[1].at) >= throughputWindow {
+		p.samples = p.samples[1:]
+	}
+}
+
+// rate returns the average download speed in bytes per second across the
+// sampled window, or 0 before there's enough data.
+func (p *downloadProgress) rate() float64 {
+	if len(p.samples) < 2 {
+		return 0
+	}
+	first, last := p.samples[0], p.samples[len(p.samples)-1]
+	secs := last.at.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	return float64(last.bytes-first.bytes) / secs
+}
+
+// drop removes n bytes from the expected total, for files that turn out not to
+// need transferring (skipped, or the unreceived rest of a failed file).
+func (p *downloadProgress) drop(n int64) {
+	p.total.Add(-n)
+}
+
+// abandon drops whatever is left of a size-byte file that won't finish, given
+// the byte count from just before it started. Bytes that did arrive stay
+// counted as received.
+func (p *downloadProgress) abandon(size, before int64) {
+	p.drop(size - (p.bytes.Load() - before))
+}
+
+// fraction returns how much of the job is done, from 0 to 1, and false while
+// the total is still unknown.
+func (p *downloadProgress) fraction() (float64, bool) {
+	total := p.total.Load()
+	if total <= 0 {
+		return 0, false
+	}
+	return math.Min(1, float64(p.done())/float64(total)), true
+}
+
+// done returns the bytes of the job that are done: received, or already
+// there when a resumed job started.
+func (p *downloadProgress) done() int64 {
+	return p.bytes.Load() + p.preloaded.Load()
+}
+
+// eta estimates the time remaining at the current average speed. It reports
+// false until the total is known and enough samples have been gathered.
+func (p *downloadProgress) eta() (time.Duration, bool) {
+	total := p.total.Load()
+	rate := p.rate()
+	if total <= 0 || rate <= 0 || len(p.samples) < etaMinSamples {
+		return 0, false
+	}
+	remaining := total - p.done()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(float64(remaining) / rate * float64(time.Second)), true
+}
+
+// formatETA formats an estimate like "ETA 2m14s", or "ETA —" when there isn't
+// one yet.
+func formatETA(d time.Duration, ok bool) string {
+	if !ok {
+		return "ETA —"
+	}
+	return "ETA " + d.Round(time.Second).String()
+}
+
+// progressBar renders a fixed-width bar filled to fraction (0 to 1).
+func progressBar(fraction float64) string {
+	filled := int(fraction * progressBarWidth)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "]"
+}
+
+// countingReader adds every byte read through it to a progress counter.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// groupDigits formats n with commas between groups of three digits, e.g.
+// "3,481".
+func groupDigits(n int64) string {
+	if n < 0 {
+		return "-" + groupDigits(-n)
+	}
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// formatRate formats a speed in bytes per second, e.g. "11.8 MiB/s" when
+// binary is set, or else "12.4 MB/s" (see formatSize).
+func formatRate(bytesPerSec float64, binary bool) string {
+	return formatSize(int64(bytesPerSec), binary) + "/s"
+}
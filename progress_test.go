@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+func TestDownloadProgressRate(t *testing.T) {
+	start := time.Unix(0, 0)
+	p := newDownloadProgress(start)
+	if got := p.rate(); got != 0 {
+		t.Errorf("rate before any sample = %v, want 0", got)
+	}
+
+	// 1 MB/s for the first 10 seconds, then 3 MB/s.
+	for i := 1; i <= 10; i++ {
+		p.bytes.Add(1_000_000)
+		p.sample(start.Add(time.Duration(i) * time.Second))
+	}
+	if got := p.rate(); got != 1_000_000 {
+		t.Errorf("steady rate = %v, want 1e6", got)
+	}
+
+	for i := 11; i <= 20; i++ {
+		p.bytes.Add(3_000_000)
+		p.sample(start.Add(time.Duration(i) * time.Second))
+	}
+	// Only the last throughputWindow of samples count, so the old 1 MB/s
+	// phase has aged out entirely.
+	if got := p.rate(); got != 3_000_000 {
+		t.Errorf("rate after speed-up = %v, want 3e6", got)
+	}
+}
+
+func TestDownloadProgressETA(t *testing.T) {
+	start := time.Unix(0, 0)
+	p := newDownloadProgress(start)
+	p.total.Store(10_000_000)
+
+	p.bytes.Add(1_000_000)
+	p.sample(start.Add(time.Second))
+	if _, ok := p.eta(); ok {
+		t.Error("ETA shown before enough samples were gathered")
+	}
+
+	p.bytes.Add(1_000_000)
+	p.sample(start.Add(2 * time.Second))
+	eta, ok := p.eta()
+	if !ok {
+		t.Fatal("expected an ETA once enough samples were gathered")
+	}
+	// 8 MB left at 1 MB/s.
+	if eta != 8*time.Second {
+		t.Errorf("eta = %v, want 8s", eta)
+	}
+	if got := formatETA(eta, ok); got != "ETA 8s" {
+		t.Errorf("formatETA = %q, want \"ETA 8s\"", got)
+	}
+
+	// Skipping a file shrinks the remaining work.
+	p.drop(6_000_000)
+	if eta, _ := p.eta(); eta != 2*time.Second {
+		t.Errorf("eta after drop = %v, want 2s", eta)
+	}
+	if f, _ := p.fraction(); f != 0.5 {
+		t.Errorf("fraction = %v, want 0.5", f)
+	}
+}
+
+func TestFolderScanned(t *testing.T) {
+	dbx := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/root":   {fakeFile("/root/z.txt"), fakeFolder("/root/a")},
+		"/root/a": {fakeFile("/root/a/1.txt"), fakeFile("/root/a/2.txt")},
+	}}
+	p := newDownloadProgress(time.Now())
+	var reports []ScanProgressMsg
+	p.onScan = func(msg ScanProgressMsg) { reports = append(reports, msg) }
+
+	if _, _, _, err := getFilesToDepth(dbx, "/root", -1, defaultListConcurrency, p.folderScanned); err != nil {
+		t.Fatal(err)
+	}
+	if p.scannedFolders.Load() != 2 || p.scannedFiles.Load() != 3 {
+		t.Errorf("scanned %d folders and %d files, want 2 and 3", p.scannedFolders.Load(), p.scannedFiles.Load())
+	}
+	// The second folder is listed well within scanReportInterval of the
+	// first, so only the first is reported.
+	if len(reports) != 1 || reports[0].Folders != 1 {
+		t.Errorf("reports = %+v, want just the first folder", reports)
+	}
+
+	// Once the scan is done, the totals held back are reported.
+	p.scanFinished()
+	if last := reports[len(reports)-1]; len(reports) != 2 || last.Folders != 2 || last.Files != 3 {
+		t.Errorf("reports = %+v, want the final totals last", reports)
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{3481, "3,481"},
+		{1234567, "1,234,567"},
+		{-1000, "-1,000"},
+	}
+	for _, tt := range tests {
+		if got := groupDigits(tt.in); got != tt.want {
+			t.Errorf("groupDigits(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptKind identifies what the single-line text prompt is collecting. The
+// zero value means no prompt is open.
+type promptKind int
+
+const (
+	promptNone              promptKind = iota
+	promptSelectPattern                // glob of names to add to the selection
+	promptSearch                       // incremental search; moves the cursor as you type
+	promptPaperFormat                  // single key: export format for Paper docs being downloaded
+	promptConfirmDelete                // single key: y/n before deleting the selection
+	promptMoveDest                     // folder to move the selection into
+	promptListingFormat                // single key: format to export the folder listing in
+	promptResumeQueue                  // single key: y/n to resume an unfinished download job
+	promptConfirmEmptyTrash            // single key: y/n before emptying the trash folder
+	promptConfirmUndo                  // single key: y/n before undoing the last operation
+	promptGoTo                         // line number to move the cursor to, or folder to open
+	promptTempLink                     // single key: copy or open a temporary link
+	promptConfirmArchive               // single key: y/n before archiving the selection
+	promptConfirmQuit                  // single key: y/n before quitting with a selection
+	promptConfirmClear                 // single key: y/n before clearing the selection screen
+)
+
+// label returns the text shown before the prompt's input.
+func (k promptKind) label() string {
+	switch k {
+	case promptSelectPattern:
+		return "select pattern: "
+	case promptSearch:
+		return "/"
+	case promptGoTo:
+		return ":"
+	case promptPaperFormat:
+		return "export Paper docs as (m)arkdown or (h)tml? "
+	default:
+		return ""
+	}
+}
+
+// isChoice reports whether the prompt answers with a single key rather than
+// a line of text.
+func (k promptKind) isChoice() bool {
+	switch k {
+	case promptPaperFormat, promptConfirmDelete, promptListingFormat, promptResumeQueue, promptConfirmEmptyTrash, promptConfirmUndo, promptTempLink, promptConfirmArchive, promptConfirmQuit, promptConfirmClear:
+		return true
+	default:
+		return false
+	}
+}
+
+// promptLabel returns the label for the open prompt, filling in details for
+// prompts that describe what they act on.
+func (m Model) promptLabel() string {
+	switch m.prompt {
+	case promptConfirmDelete:
+		if m.trashes(m.pendingDelete) {
+			return fmt.Sprintf("move %s%s to the trash? (y/n) ", pluralize(len(m.pendingDelete), "item"), m.elsewhereNote(m.pendingDelete))
+		}
+		return fmt.Sprintf("delete %s%s? (y/n) ", pluralize(len(m.pendingDelete), "item"), m.elsewhereNote(m.pendingDelete))
+	case promptConfirmEmptyTrash:
+		return fmt.Sprintf("empty the trash (%s/)? (y/n) ", m.config.Trash)
+	case promptConfirmArchive:
+		return fmt.Sprintf("archive %s: download, verify, then DELETE from Dropbox? (y/n) ", pluralize(len(m.pendingArchive), "item"))
+	case promptConfirmUndo:
+		return fmt.Sprintf("undo: %s? (y/n) ", m.lastOp.describe())
+	case promptConfirmQuit:
+		return fmt.Sprintf("you have %s selected — quit and lose the selection? (y/n) ", pluralize(len(m.selected), "item"))
+	case promptConfirmClear:
+		return fmt.Sprintf("clear all %s from the selection? (y/n) ", pluralize(len(m.cart), "item"))
+	case promptMoveDest:
+		return fmt.Sprintf("move %s%s to: ", pluralize(len(m.pendingMove), "item"), m.elsewhereNote(m.pendingMove))
+	case promptListingFormat:
+		if m.exportRecursive {
+			return "export recursive listing as (c)sv or (j)son? "
+		}
+		return "export listing as (c)sv or (j)son? "
+	case promptResumeQueue:
+		return fmt.Sprintf("resume unfinished download of %s? (y/n) ", pluralize(len(m.resumeQueue.Items), "item"))
+	case promptTempLink:
+		return fmt.Sprintf("temporary link to %s (expires in %d hours): (c)opy or (o)pen? ", m.pendingLink.Name, int(tempLinkLifetime.Hours()))
+	default:
+		return m.prompt.label()
+	}
+}
+
+// openPrompt starts collecting text input for kind, replacing the status line
+// until it is submitted or cancelled.
+func (m *Model) openPrompt(kind promptKind) {
+	m.prompt = kind
+	m.promptInput = ""
+	m.promptHint = ""
+}
+
+// closePrompt dismisses the prompt and discards any input.
+func (m *Model) closePrompt() {
+	m.prompt = promptNone
+	m.promptInput = ""
+	m.promptHint = ""
+}
+
+// handlePromptKey edits the prompt input. Enter submits it and esc cancels;
+// every other printable key is appended, so bindings are inactive while typing.
+// Choice prompts instead act on the first key that answers them.
+func (m Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		return m.cancelPrompt()
+	}
+	if m.prompt.isChoice() {
+		return m.submitChoice(m.prompt, msg.String())
+	}
+
+	m.promptHint = ""
+	switch msg.Type {
+	case tea.KeyEnter:
+		kind, input := m.prompt, m.promptInput
+		m.closePrompt()
+		return m.submitPrompt(kind, input)
+	case tea.KeyBackspace:
+		if runes := []rune(m.promptInput); len(runes) > 0 {
+			m.promptInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.promptInput += string(msg.Runes)
+	case tea.KeyTab:
+		if m.prompt == promptGoTo {
+			return m.completeGoTo()
+		}
+		return m, nil
+	default:
+		return m, nil
+	}
+	// The input changed.
+	if m.prompt == promptSearch {
+		m.updateIncrementalSearch()
+	}
+	return m, nil
+}
+
+// cancelPrompt closes the prompt without acting on it, undoing anything it
+// started.
+func (m Model) cancelPrompt() (tea.Model, tea.Cmd) {
+	kind := m.prompt
+	m.closePrompt()
+	switch kind {
+	case promptSearch:
+		m.cancelSearch()
+	case promptPaperFormat:
+		m.pendingDownload, m.pendingShallow = nil, false
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Download cancelled"}
+		}
+	case promptConfirmDelete:
+		m.pendingDelete = nil
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Delete cancelled"}
+		}
+	case promptMoveDest:
+		m.pendingMove = nil
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Move cancelled"}
+		}
+	case promptConfirmEmptyTrash:
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Trash left as is"}
+		}
+	case promptConfirmUndo:
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Undo cancelled"}
+		}
+	case promptConfirmQuit, promptConfirmClear:
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Selection kept"}
+		}
+	case promptTempLink:
+		m.pendingLink = FileItem{}
+	case promptConfirmArchive:
+		m.pendingArchive = nil
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Archive cancelled"}
+		}
+	case promptResumeQueue:
+		m.resumeQueue = nil
+		return m, func() tea.Msg {
+			if err := clearDownloadQueue(); err != nil {
+				return ErrorMsg{Error: fmt.Sprintf("Failed to discard download queue: %v", err)}
+			}
+			return StatusMsg{Message: "Discarded unfinished download"}
+		}
+	}
+	return m, nil
+}
+
+// submitChoice acts on a key pressed at a choice prompt.
+func (m Model) submitChoice(kind promptKind, key string) (tea.Model, tea.Cmd) {
+	switch kind {
+	case promptPaperFormat:
+		return m.choosePaperFormat(key)
+	case promptConfirmDelete:
+		return m.answerDelete(key)
+	case promptListingFormat:
+		return m.chooseListingFormat(key)
+	case promptResumeQueue:
+		return m.answerResume(key)
+	case promptConfirmEmptyTrash:
+		return m.answerEmptyTrash(key)
+	case promptConfirmUndo:
+		return m.answerUndo(key)
+	case promptConfirmQuit:
+		return m.answerQuit(key)
+	case promptConfirmClear:
+		return m.answerClear(key)
+	case promptTempLink:
+		return m.answerTempLink(key)
+	case promptConfirmArchive:
+		return m.answerArchive(key)
+	}
+	return m, nil
+}
+
+// submitPrompt acts on the input collected by a prompt of the given kind.
+func (m Model) submitPrompt(kind promptKind, input string) (tea.Model, tea.Cmd) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return m, nil
+	}
+	switch kind {
+	case promptSelectPattern:
+		return m.selectByPattern(input)
+	case promptSearch:
+		return m.submitSearch(input)
+	case promptMoveDest:
+		return m.submitMove(input)
+	case promptGoTo:
+		return m.goTo(input)
+	}
+	return m, nil
+}
+
+// renderPrompt renders the open prompt with a cursor after the input.
+func (m Model) renderPrompt() string {
+	labelStyle := lipgloss.NewStyle().Foreground(m.config.Theme.Accent).Bold(true)
+	if m.prompt.isChoice() {
+		return labelStyle.Render(m.promptLabel())
+	}
+	prompt := labelStyle.Render(m.promptLabel()) + m.promptInput + "▏"
+	if m.promptHint != "" {
+		prompt += "  " + lipgloss.NewStyle().Foreground(m.config.Theme.Muted).Render(m.promptHint)
+	}
+	return prompt
+}
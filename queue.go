@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// downloadQueue is a download job saved to disk while it runs, so it can be
+// resumed if dbox quits before it finishes. It records what was selected
+// rather than every file: resuming re-lists selected folders, and files that
+// already finished are skipped like any existing file (they only appear under
+// their final name once complete).
+type downloadQueue struct {
+	Items       []FileItem `json:"items"`
+	PaperFormat string     `json:"paper_format,omitempty"`
+	MaxDepth    *int       `json:"max_depth,omitempty"`
+	Zip         bool       `json:"zip,omitempty"`
+}
+
+// statePath returns where dbox keeps the state file name:
+// $XDG_STATE_HOME/dbox/<name>, or ~/.local/state/dbox/<name>.
+func statePath(name string) (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(dir, "dbox", name), nil
+}
+
+// queuePath returns where the running download job is recorded.
+func queuePath() (string, error) {
+	return statePath("queue.json")
+}
+
+// saveDownloadQueue records a download job before it starts.
+func saveDownloadQueue(q downloadQueue) error {
+	path, err := queuePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadDownloadQueue returns the job left behind by an earlier run, or nil if
+// there isn't one.
+func loadDownloadQueue() (*downloadQueue, error) {
+	path, err := queuePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var q downloadQueue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if len(q.Items) == 0 {
+		return nil, nil
+	}
+	return &q, nil
+}
+
+// clearDownloadQueue removes the recorded job once it has finished (or the
+// user declines to resume it).
+func clearDownloadQueue() error {
+	path, err := queuePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// offerResume asks whether to resume a download job left over from an
+// earlier run.
+func (m *Model) offerResume(q *downloadQueue) {
+	m.resumeQueue = q
+	m.openPrompt(promptResumeQueue)
+}
+
+// answerResume handles y/n at the resume prompt: y restarts the saved job and
+// n discards it. Other keys leave the prompt open.
+func (m Model) answerResume(key string) (tea.Model, tea.Cmd) {
+	q := m.resumeQueue
+	switch key {
+	case "y":
+		m.resumeQueue = nil
+		m.closePrompt()
+		return m, func() tea.Msg {
+			return DownloadMsg{Files: q.Items, PaperFormat: q.PaperFormat, MaxDepth: q.MaxDepth, Zip: q.Zip, Resume: true}
+		}
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
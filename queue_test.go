@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDownloadQueueRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if q, err := loadDownloadQueue(); err != nil || q != nil {
+		t.Fatalf("no queue yet: got %v, %v", q, err)
+	}
+
+	want := downloadQueue{
+		Items:       []FileItem{{Name: "photos", Path: "/photos", IsFolder: true}},
+		PaperFormat: "html",
+		MaxDepth:    new(int),
+		Zip:         true,
+	}
+	if err := saveDownloadQueue(want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := loadDownloadQueue()
+	if err != nil || got == nil || !reflect.DeepEqual(*got, want) {
+		t.Fatalf("load = %+v, %v; want %+v", got, err, want)
+	}
+
+	if err := clearDownloadQueue(); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if q, _ := loadDownloadQueue(); q != nil {
+		t.Error("queue should be gone after clearing")
+	}
+	if err := clearDownloadQueue(); err != nil {
+		t.Errorf("clearing twice should be fine: %v", err)
+	}
+}
+
+func TestResumePrompt(t *testing.T) {
+	m := initialModel(&Config{})
+	items := []FileItem{{Name: "a", Path: "/a"}}
+	m.offerResume(&downloadQueue{Items: items, PaperFormat: "html", Zip: true})
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if next.(Model).prompt != promptNone || cmd == nil {
+		t.Fatal("y should close the prompt and start the download")
+	}
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || !reflect.DeepEqual(msg.Files, items) || msg.PaperFormat != "html" || !msg.Zip {
+		t.Errorf("got %#v, want the queued download", msg)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minAdaptiveRate is the slowest adaptive_rate backs off to, in requests per
+// second, so a run of 429s can't stall dbox altogether.
+const minAdaptiveRate = 1.0
+
+// backOffInterval is how soon after one back-off another 429 can halve the
+// rate again. Parallel requests that were already in flight tend to come back
+// throttled together, and should count as one signal rather than several.
+const backOffInterval = time.Second
+
+// defaultRequestsPerSecond is how many Dropbox API calls dbox makes per
+// second unless requests_per_second says otherwise. It's well under what
+// Dropbox allows, so bursts from parallel listings and downloads don't trip
+// its rate limits.
+const defaultRequestsPerSecond = 10
+
+// rateLimiter is a token bucket: it holds up to a second's worth of requests,
+// refilled at perSecond, and a request that finds it empty waits its turn.
+//
+// An adaptive limiter also moves its rate like TCP congestion control: it
+// halves when Dropbox answers 429 Too Many Requests, and creeps back up by
+// about one request a second for each second of successful requests, never
+// past ceiling.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // most tokens held at once
+	tokens float64 // negative while requests are queued
+	last   time.Time
+
+	adaptive      bool
+	ceiling       float64 // fastest an adaptive limiter ramps back up to
+	lastThrottled time.Time
+
+	log *log.Logger // where rate changes are noted (log_file); nil for none
+}
+
+// newRateLimiter allows perSecond requests a second, or returns nil (no
+// limit) if perSecond isn't positive.
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: float64(perSecond), burst: float64(perSecond), tokens: float64(perSecond)}
+}
+
+// newAdaptiveRateLimiter starts at perSecond requests a second and adapts to
+// Dropbox's throttling, never going faster than perSecond. With no limit
+// (perSecond isn't positive) it starts at defaultRequestsPerSecond and ramps
+// up without a ceiling.
+func newAdaptiveRateLimiter(perSecond int) *rateLimiter {
+	start, ceiling := float64(perSecond), float64(perSecond)
+	if perSecond <= 0 {
+		start, ceiling = defaultRequestsPerSecond, math.Inf(1)
+	}
+	return &rateLimiter{rate: start, burst: start, tokens: start, adaptive: true, ceiling: ceiling}
+}
+
+// reserve takes a token at now and returns how long to wait before using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// throttled halves an adaptive limiter's rate after Dropbox answered 429 at
+// now, unless it already backed off within backOffInterval.
+func (l *rateLimiter) throttled(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.adaptive || (!l.lastThrottled.IsZero() && now.Sub(l.lastThrottled) < backOffInterval) {
+		return
+	}
+	l.lastThrottled = now
+	if l.rate <= minAdaptiveRate {
+		return
+	}
+	l.setRate(math.Max(minAdaptiveRate, l.rate/2))
+	l.logf("Dropbox is rate limiting: slowed to %.1f requests/s", l.rate)
+}
+
+// succeeded ramps an adaptive limiter's rate back up after a request went
+// through. Each success adds 1/rate, so a second's worth adds about one
+// request a second.
+func (l *rateLimiter) succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.adaptive || l.rate >= l.ceiling {
+		return
+	}
+	before := l.rate
+	l.setRate(math.Min(l.ceiling, l.rate+1/l.rate))
+	if l.rate == l.ceiling {
+		l.logf("Back to full speed: %.1f requests/s", l.rate)
+	} else if math.Floor(l.rate) > math.Floor(before) {
+		l.logf("Speeding up: %.1f requests/s", l.rate)
+	}
+}
+
+// setRate changes the rate, and the burst with it so the bucket still holds a
+// second's worth. The caller holds mu.
+func (l *rateLimiter) setRate(rate float64) {
+	l.rate, l.burst = rate, rate
+	l.tokens = math.Min(l.tokens, l.burst)
+}
+
+// logf notes a rate change in the log, if there is one. The caller holds mu.
+func (l *rateLimiter) logf(format string, args ...any) {
+	if l.log != nil {
+		l.log.Printf(format, args...)
+	}
+}
+
+// limitedTransport waits for the limiter before sending each request.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if d := t.limiter.reserve(time.Now()); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case resp == nil:
+	case resp.StatusCode == http.StatusTooManyRequests:
+		t.limiter.throttled(time.Now())
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		// Other errors, like a 409 for a missing path, say nothing about
+		// the rate either way.
+		t.limiter.succeeded()
+	}
+	return resp, err
+}
+
+// limitRequests makes client's requests wait for limiter; nil means no limit.
+// Clients sharing a limiter are paced together.
+func limitRequests(client *http.Client, limiter *rateLimiter) *http.Client {
+	if limiter == nil {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *client
+	limited.Transport = limitedTransport{base: base, limiter: limiter}
+	return &limited
+}
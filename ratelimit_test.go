@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter(2)
+	start := time.Now()
+
+	// A second's worth of requests go straight through, then each waits its
+	// turn behind the ones already queued.
+	waits := []time.Duration{
+		l.reserve(start),
+		l.reserve(start),
+		l.reserve(start),
+		l.reserve(start),
+	}
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("request %d waits %v, want %v", i, waits[i], want[i])
+		}
+	}
+
+	// After a long pause the bucket is full again, but no fuller.
+	later := start.Add(time.Minute)
+	if d := l.reserve(later); d != 0 {
+		t.Errorf("after a pause, wait = %v, want 0", d)
+	}
+	l.reserve(later)
+	if d := l.reserve(later); d != 500*time.Millisecond {
+		t.Errorf("third request after a pause waits %v, want 500ms", d)
+	}
+
+	if newRateLimiter(0) != nil {
+		t.Error("0 requests per second should mean no limiter")
+	}
+}
+
+func TestLimitRequests(t *testing.T) {
+	client := &http.Client{}
+	if limitRequests(client, nil) != client {
+		t.Error("with no limiter the client should be used as is")
+	}
+	limited := limitRequests(client, newRateLimiter(5))
+	if _, ok := limited.Transport.(limitedTransport); !ok || client.Transport != nil {
+		t.Errorf("limited transport = %T, original changed to %T", limited.Transport, client.Transport)
+	}
+}
+
+func TestAdaptiveRateLimiter(t *testing.T) {
+	l := newAdaptiveRateLimiter(8)
+	start := time.Now()
+
+	// A 429 halves the rate; others right behind it count as the same one.
+	l.throttled(start)
+	l.throttled(start.Add(100 * time.Millisecond))
+	if l.rate != 4 || l.burst != 4 {
+		t.Fatalf("after a 429, rate = %v (burst %v), want 4", l.rate, l.burst)
+	}
+	l.throttled(start.Add(2 * time.Second))
+	if l.rate != 2 {
+		t.Fatalf("after another 429, rate = %v, want 2", l.rate)
+	}
+	for i := 0; i < 5; i++ {
+		l.throttled(start.Add(time.Duration(3+i) * time.Second))
+	}
+	if l.rate != minAdaptiveRate {
+		t.Fatalf("after a run of 429s, rate = %v, want the floor %v", l.rate, minAdaptiveRate)
+	}
+
+	// Successes ramp it back up, but no further than the configured rate.
+	for i := 0; i < 100; i++ {
+		l.succeeded()
+	}
+	if l.rate != 8 {
+		t.Errorf("after many successes, rate = %v, want back at 8", l.rate)
+	}
+
+	// A fixed limiter ignores both.
+	fixed := newRateLimiter(8)
+	fixed.throttled(start)
+	fixed.succeeded()
+	if fixed.rate != 8 {
+		t.Errorf("fixed limiter rate = %v, want 8", fixed.rate)
+	}
+
+	if unlimited := newAdaptiveRateLimiter(0); unlimited.rate != defaultRequestsPerSecond || !math.IsInf(unlimited.ceiling, 1) {
+		t.Errorf("with no limit: rate %v, ceiling %v", unlimited.rate, unlimited.ceiling)
+	}
+}
+
+// statusTransport answers every request with its status code.
+type statusTransport struct{ status *int }
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: *t.status, Body: http.NoBody, Request: req}, nil
+}
+
+func TestLimitedTransportAdapts(t *testing.T) {
+	status := http.StatusTooManyRequests
+	l := newAdaptiveRateLimiter(4)
+	tr := limitedTransport{base: statusTransport{&status}, limiter: l}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.dropboxapi.com/2/files/list_folder", nil)
+
+	if _, err := tr.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if l.rate != 2 {
+		t.Fatalf("after a 429, rate = %v, want 2", l.rate)
+	}
+	status = http.StatusInternalServerError
+	tr.RoundTrip(req)
+	if l.rate != 2 {
+		t.Fatalf("after a 500, rate = %v, want it unchanged at 2", l.rate)
+	}
+	status = http.StatusOK
+	tr.RoundTrip(req)
+	if l.rate != 2.5 {
+		t.Errorf("after a success, rate = %v, want 2.5", l.rate)
+	}
+}
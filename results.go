@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// resultsChrome is the number of lines the results screen uses around the
+// scrollable list (title, blank line, and footer).
+const resultsChrome = 4
+
+// handleResultsKey scrolls the download results screen, or retries what
+// failed; any other key dismisses it.
+func (m Model) handleResultsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "r" && len(m.failed) > 0 {
+		return m.retryFailed()
+	}
+	lines := len(m.resultLines())
+	page := m.resultsPageSize()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "down", "j":
+		m.resultsOffset++
+	case "up", "k":
+		m.resultsOffset--
+	case "ctrl+d":
+		m.resultsOffset += page / 2
+	case "ctrl+u":
+		m.resultsOffset -= page / 2
+	default:
+		m.results = nil
+		m.resultsOffset = 0
+		return m, nil
+	}
+	m.resultsOffset = max(0, min(m.resultsOffset, lines-page))
+	return m, nil
+}
+
+// retryFailed closes the results screen and downloads the entries the last
+// job failed on again, with that job's options. An archive job is retried as
+// a plain download, since deleting from Dropbox needs its own confirmation.
+func (m Model) retryFailed() (tea.Model, tea.Cmd) {
+	retry := m.lastDownload
+	retry.Files = m.failed
+	retry.Resume = false
+	retry.Archive = false
+	m.results = nil
+	m.resultsOffset = 0
+	m.failed = nil
+	return m, func() tea.Msg {
+		return retry
+	}
+}
+
+// failedItems returns the entries errors refer to, each once, leaving out
+// errors that aren't about an entry (such as failing to update the index).
+func failedItems(errors []ItemError) []FileItem {
+	var items []FileItem
+	seen := map[string]bool{}
+	for _, item := range itemsOf(errors) {
+		if item.Path == "" || seen[item.Path] {
+			continue
+		}
+		seen[item.Path] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// resultsPageSize is how many result lines fit on screen at once.
+func (m Model) resultsPageSize() int {
+	return max(1, m.height-resultsChrome)
+}
+
+// resultLine is one row of the results screen.
+type resultLine struct {
+	text  string
+	color lipgloss.Color // "" for the default
+	bold  bool
+}
+
+// resultLines lays out every section of the stored download results.
+func (m Model) resultLines() []resultLine {
+	r := m.results
+	if r == nil {
+		return nil
+	}
+
+	var lines []resultLine
+	theme := m.config.Theme
+	section := func(title string, count int, color lipgloss.Color) {
+		if len(lines) > 0 {
+			lines = append(lines, resultLine{})
+		}
+		lines = append(lines, resultLine{text: fmt.Sprintf("%s (%d)", title, count), color: color, bold: true})
+	}
+
+	section("Downloaded", len(r.Downloaded), theme.Status)
+	for _, item := range r.Downloaded {
+		lines = append(lines, resultLine{text: fmt.Sprintf("  %s  %s", item.Path, formatSize(item.Size, m.config.BinaryUnits))})
+	}
+	if len(r.Overwritten) > 0 {
+		section("Overwritten (replaced a local file)", len(r.Overwritten), theme.Status)
+		for _, item := range r.Overwritten {
+			lines = append(lines, resultLine{text: "  " + item.Path})
+		}
+	}
+	if len(r.Archived) > 0 {
+		section("Archived (verified, then deleted from Dropbox)", len(r.Archived), theme.Status)
+		for _, item := range r.Archived {
+			lines = append(lines, resultLine{text: "  " + item.Path})
+		}
+	}
+	section("Skipped (already exist, or skipped while downloading)", len(r.Skipped), theme.Muted)
+	for _, item := range r.Skipped {
+		lines = append(lines, resultLine{text: "  " + item.Path, color: theme.Muted})
+	}
+	if len(r.Ignored) > 0 {
+		section("Ignored (.dboxignore)", len(r.Ignored), theme.Muted)
+		for _, item := range r.Ignored {
+			lines = append(lines, resultLine{text: "  " + item.Path, color: theme.Muted})
+		}
+	}
+	if len(r.TooDeep) > 0 {
+		section("Not followed (deeper than max_depth)", len(r.TooDeep), theme.Muted)
+		for _, item := range r.TooDeep {
+			lines = append(lines, resultLine{text: "  " + item.Path + "/", color: theme.Muted})
+		}
+	}
+	if len(r.Renamed) > 0 {
+		section("Renamed (same name as another file)", len(r.Renamed), theme.Muted)
+		for _, items := range [][]FileItem{r.Downloaded, r.Skipped} {
+			for _, item := range items {
+				if local, ok := r.Renamed[item.Path]; ok {
+					lines = append(lines, resultLine{text: "  " + item.Path + " → " + local, color: theme.Muted})
+				}
+			}
+		}
+	}
+	section("Errors", len(r.Errors), theme.Error)
+	for _, e := range r.Errors {
+		lines = append(lines, resultLine{text: "  " + e.Err, color: theme.Error})
+	}
+	if r.ErrorLog != "" {
+		lines = append(lines, resultLine{text: "  Also written to " + r.ErrorLog, color: theme.Muted})
+	}
+	return lines
+}
+
+// renderResultsView renders the scrollable download results screen.
+func (m Model) renderResultsView() string {
+	var s strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(m.config.Theme.Accent)
+	hintStyle := lipgloss.NewStyle().Foreground(m.config.Theme.Muted)
+
+	s.WriteString(titleStyle.Render("Download complete") + "\n\n")
+
+	lines := m.resultLines()
+	end := min(len(lines), m.resultsOffset+m.resultsPageSize())
+	for _, line := range lines[m.resultsOffset:end] {
+		style := lipgloss.NewStyle().Bold(line.bold)
+		if line.color != "" {
+			style = style.Foreground(line.color)
+		}
+		s.WriteString(style.Render(line.text) + "\n")
+	}
+
+	hint := "press any key to return"
+	if len(lines) > m.resultsPageSize() {
+		hint = fmt.Sprintf("lines %d–%d of %d · j/k to scroll · any other key to return",
+			m.resultsOffset+1, end, len(lines))
+	}
+	if len(m.failed) > 0 {
+		hint = fmt.Sprintf("r to retry %s · ", pluralize(len(m.failed), "failed item")) + hint
+	}
+	s.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return s.String()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// revisionDelay is how long the cursor has to rest on a file before its
+// revisions are counted, so scrolling past files doesn't fetch each one.
+const revisionDelay = 300 * time.Millisecond
+
+// maxRevisionsListed is the most revisions Dropbox returns in one call; files
+// with that many are shown as "100+".
+const maxRevisionsListed = 100
+
+// revisionTickMsg fires once the cursor may have rested on path for
+// revisionDelay.
+type revisionTickMsg struct {
+	path string
+}
+
+// RevisionCountMsg reports how many revisions a file has. Count is -1 if
+// they couldn't be listed.
+type RevisionCountMsg struct {
+	Path  string
+	Count int
+}
+
+// revisionCmd schedules counting the revisions of the file under the cursor,
+// when show_revisions is on and it hasn't been counted yet. Only one file is
+// counted at a time; the rest wait for the cursor to come back to them.
+func (m Model) revisionCmd() tea.Cmd {
+	if !m.config.ShowRevisions || m.config.link != nil || m.revisionPending != "" || m.cursor >= len(m.visible) {
+		return nil
+	}
+	file := m.visible[m.cursor]
+	if file.IsFolder {
+		return nil
+	}
+	if _, ok := m.revisions[file.Path]; ok {
+		return nil
+	}
+	return tea.Tick(revisionDelay, func(time.Time) tea.Msg {
+		return revisionTickMsg{path: file.Path}
+	})
+}
+
+// handleRevisionTick counts the revisions of msg.path if the cursor is still
+// on it.
+func (m Model) handleRevisionTick(msg revisionTickMsg) (tea.Model, tea.Cmd) {
+	if m.revisionPending != "" || m.cursor >= len(m.visible) || m.visible[m.cursor].Path != msg.path {
+		return m, nil
+	}
+	if _, ok := m.revisions[msg.path]; ok {
+		return m, nil
+	}
+	m.revisionPending = msg.path
+	return m, revisionCountCmd(&m.config, msg.path)
+}
+
+// handleRevisionCount stores a file's revision count, then moves on to the
+// file under the cursor if that one hasn't been counted.
+func (m Model) handleRevisionCount(msg RevisionCountMsg) (tea.Model, tea.Cmd) {
+	if m.revisions == nil {
+		m.revisions = make(map[string]int)
+	}
+	m.revisions[msg.Path] = msg.Count
+	m.revisionPending = ""
+	return m, m.revisionCmd()
+}
+
+// revisionCountCmd lists the revisions of the file at p.
+func revisionCountCmd(config *Config, p string) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return RevisionCountMsg{Path: p, Count: -1}
+		}
+		return RevisionCountMsg{Path: p, Count: countRevisions(dbx, p)}
+	}
+}
+
+// countRevisions returns how many revisions the file at p has, up to
+// maxRevisionsListed, or -1 if they couldn't be listed.
+func countRevisions(dbx files.Client, p string) int {
+	arg := files.NewListRevisionsArg(p)
+	arg.Limit = maxRevisionsListed
+	res, err := dbx.ListRevisions(arg)
+	if err != nil {
+		return -1
+	}
+	return len(res.Entries)
+}
+
+// revisionLabel describes a revision count for the file list, e.g. "3 revs",
+// or "" if the count isn't known.
+func revisionLabel(count int, ok bool) string {
+	switch {
+	case !ok || count < 0:
+		return ""
+	case count >= maxRevisionsListed:
+		return fmt.Sprintf("%d+ revs", maxRevisionsListed)
+	case count == 1:
+		return "1 rev"
+	default:
+		return fmt.Sprintf("%d revs", count)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeRevisionsClient lists n revisions of any file, or fails. Any other
+// method panics via the nil embedded interface.
+type fakeRevisionsClient struct {
+	files.Client
+	n   int
+	err error
+}
+
+func (f *fakeRevisionsClient) ListRevisions(arg *files.ListRevisionsArg) (*files.ListRevisionsResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &files.ListRevisionsResult{Entries: make([]*files.FileMetadata, min(f.n, int(arg.Limit)))}, nil
+}
+
+func TestCountRevisions(t *testing.T) {
+	if got := countRevisions(&fakeRevisionsClient{n: 3}, "/a.txt"); got != 3 {
+		t.Errorf("count = %d, want 3", got)
+	}
+	if got := countRevisions(&fakeRevisionsClient{n: 250}, "/a.txt"); got != maxRevisionsListed {
+		t.Errorf("count = %d, want it capped at %d", got, maxRevisionsListed)
+	}
+	if got := countRevisions(&fakeRevisionsClient{err: errors.New("not_found")}, "/a.txt"); got != -1 {
+		t.Errorf("count = %d, want -1 on error", got)
+	}
+}
+
+func TestRevisionLabel(t *testing.T) {
+	tests := []struct {
+		count int
+		ok    bool
+		want  string
+	}{
+		{0, false, ""},
+		{-1, true, ""},
+		{1, true, "1 rev"},
+		{7, true, "7 revs"},
+		{100, true, "100+ revs"},
+	}
+	for _, tt := range tests {
+		if got := revisionLabel(tt.count, tt.ok); got != tt.want {
+			t.Errorf("revisionLabel(%d, %v) = %q, want %q", tt.count, tt.ok, got, tt.want)
+		}
+	}
+}
+
+func TestRevisionsOnDemand(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a.txt", Path: "/a.txt"}, {Name: "b.txt", Path: "/b.txt"}})
+	if m.revisionCmd() != nil {
+		t.Fatal("revisions shouldn't be counted unless show_revisions is on")
+	}
+
+	m.config.ShowRevisions = true
+	if m.revisionCmd() == nil {
+		t.Fatal("the file under the cursor should be scheduled for counting")
+	}
+
+	// The cursor moved on before the tick: nothing is fetched.
+	m.cursor = 1
+	next, cmd := m.handleRevisionTick(revisionTickMsg{path: "/a.txt"})
+	if m = next.(Model); cmd != nil || m.revisionPending != "" {
+		t.Error("a file the cursor left shouldn't be counted")
+	}
+
+	next, cmd = m.handleRevisionTick(revisionTickMsg{path: "/b.txt"})
+	if m = next.(Model); cmd == nil || m.revisionPending != "/b.txt" {
+		t.Fatal("the file under the cursor should be counted")
+	}
+	next, _ = m.handleRevisionCount(RevisionCountMsg{Path: "/b.txt", Count: 4})
+	m = next.(Model)
+	if m.revisionPending != "" || m.revisionCmd() != nil {
+		t.Error("a counted file shouldn't be counted again")
+	}
+	if list := m.renderFileList(); !strings.Contains(list, "4 revs") {
+		t.Errorf("list should show the count:\n%s", list)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// startSearch opens the search prompt, remembering where the cursor was so
+// cancelling can put it back.
+func (m *Model) startSearch() {
+	m.searchOrigin = m.cursor
+	m.searchTerm = ""
+	m.openPrompt(promptSearch)
+}
+
+// updateIncrementalSearch moves the cursor to the first match of the text typed
+// so far, starting from where the search began. The list itself is untouched.
+func (m *Model) updateIncrementalSearch() {
+	if m.promptInput == "" {
+		m.cursor = m.searchOrigin
+		return
+	}
+	if i := m.findMatch(m.promptInput, m.searchOrigin, 1); i >= 0 {
+		m.cursor = i
+	}
+}
+
+// cancelSearch abandons an in-progress search and restores the cursor.
+func (m *Model) cancelSearch() {
+	m.cursor = m.searchOrigin
+	m.searchTerm = ""
+}
+
+// submitSearch keeps term for n/N and highlighting.
+func (m Model) submitSearch(term string) (tea.Model, tea.Cmd) {
+	m.searchTerm = term
+	if m.findMatch(term, m.cursor, 1) < 0 {
+		m.cursor = m.searchOrigin
+		m.error = "Pattern not found: " + term
+		m.errorTime = time.Now()
+	}
+	return m, nil
+}
+
+// nextMatch moves the cursor to the next (dir 1) or previous (dir -1) entry
+// matching the current search term, wrapping around the list.
+func (m Model) nextMatch(dir int) (tea.Model, tea.Cmd) {
+	if m.searchTerm == "" || len(m.visible) == 0 {
+		return m, nil
+	}
+	i := m.findMatch(m.searchTerm, m.cursor+dir, dir)
+	if i < 0 {
+		m.error = "Pattern not found: " + m.searchTerm
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.cursor = i
+	matches := m.searchMatches(m.searchTerm)
+	for n, idx := range matches {
+		if idx == i {
+			m.status = fmt.Sprintf("/%s: match %d of %d", m.searchTerm, n+1, len(matches))
+			m.statusTime = time.Now()
+		}
+	}
+	return m, nil
+}
+
+// findMatch returns the index of the first visible entry matching term,
+// scanning from start in direction dir (1 or -1) and wrapping around, or -1 if
+// nothing matches.
+func (m Model) findMatch(term string, start, dir int) int {
+	n := len(m.visible)
+	if n == 0 {
+		return -1
+	}
+	for step := 0; step < n; step++ {
+		i := ((start+dir*step)%n + n) % n
+		if matchesSearch(m.visible[i].Name, term) {
+			return i
+		}
+	}
+	return -1
+}
+
+// searchMatches returns the indices of every visible entry matching term.
+func (m Model) searchMatches(term string) []int {
+	var matches []int
+	for i, file := range m.visible {
+		if matchesSearch(file.Name, term) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// activeSearchTerm is the term to highlight: what's being typed while the
+// search prompt is open, otherwise the last submitted search.
+func (m Model) activeSearchTerm() string {
+	if m.prompt == promptSearch {
+		return m.promptInput
+	}
+	return m.searchTerm
+}
+
+// matchesSearch reports whether name contains term, ignoring case.
+func matchesSearch(name, term string) bool {
+	return term != "" && strings.Contains(strings.ToLower(name), strings.ToLower(term))
+}
+
+// highlightMatches renders name with every case-insensitive occurrence of term
+// in highlight and the rest in base.
+func highlightMatches(name, term string, base, highlight lipgloss.Style) string {
+	if term == "" {
+		return base.Render(name)
+	}
+	lowerName, lowerTerm := strings.ToLower(name), strings.ToLower(term)
+	// Only highlight when lowercasing kept byte offsets aligned; otherwise
+	// fall back to plain rendering rather than slicing mid-character.
+	if len(lowerName) != len(name) {
+		return base.Render(name)
+	}
+
+	var s strings.Builder
+	for {
+		i := strings.Index(lowerName, lowerTerm)
+		if i < 0 {
+			s.WriteString(base.Render(name))
+			return s.String()
+		}
+		if i > 0 {
+			s.WriteString(base.Render(name[:i]))
+		}
+		end := i + len(lowerTerm)
+		s.WriteString(highlight.Render(name[i:end]))
+		name, lowerName = name[end:], lowerName[end:]
+		if name == "" {
+			return s.String()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIncrementalSearch(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{
+		{Name: "alpha.txt"},
+		{Name: "notes"},
+		{Name: "Report-2023.pdf"},
+		{Name: "report-2024.pdf"},
+	})
+	m.cursor = 1
+
+	send := func(msg tea.KeyMsg) {
+		updated, _ := m.handleKeyPress(msg)
+		m = updated.(Model)
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	typeText("/rep")
+	// Searching starts from the cursor, case-insensitively.
+	if m.cursor != 2 {
+		t.Fatalf("incremental match: cursor = %d, want 2", m.cursor)
+	}
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searchTerm != "rep" {
+		t.Fatalf("searchTerm = %q, want rep", m.searchTerm)
+	}
+
+	typeText("n")
+	if m.cursor != 3 {
+		t.Errorf("n should go to the next match: cursor = %d, want 3", m.cursor)
+	}
+	typeText("n")
+	if m.cursor != 2 {
+		t.Errorf("n should wrap around: cursor = %d, want 2", m.cursor)
+	}
+	typeText("N")
+	if m.cursor != 3 {
+		t.Errorf("N should go back, wrapping: cursor = %d, want 3", m.cursor)
+	}
+
+	// Escape restores the cursor to where the search began.
+	m.cursor = 0
+	typeText("/notes")
+	if m.cursor != 1 {
+		t.Fatalf("cursor = %d, want 1", m.cursor)
+	}
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.cursor != 0 || m.searchTerm != "" {
+		t.Errorf("after esc: cursor = %d, term = %q; want 0 and empty", m.cursor, m.searchTerm)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// isSelected reports whether file is in the selection.
+func (m Model) isSelected(file FileItem) bool {
+	_, ok := m.selected[file.Path]
+	return ok
+}
+
+// toggleSelected adds file to the selection, or takes it out if it's there.
+func (m *Model) toggleSelected(file FileItem) {
+	if m.isSelected(file) {
+		delete(m.selected, file.Path)
+	} else {
+		m.selected[file.Path] = file
+	}
+}
+
+// deselect takes items out of the selection, along with anything selected
+// inside a folder among them, which the folder stood for.
+func (m *Model) deselect(items []FileItem) {
+	for _, item := range items {
+		delete(m.selected, item.Path)
+		if !item.IsFolder {
+			continue
+		}
+		for p := range m.selected {
+			if withinRemote(p, item.Path) {
+				delete(m.selected, p)
+			}
+		}
+	}
+}
+
+// pruneSelection drops selected entries of the current folder that are no
+// longer listed in it, and refreshes the rest from the listing.
+func (m *Model) pruneSelection() {
+	listed := make(map[string]FileItem, len(m.visible))
+	for _, file := range m.visible {
+		listed[file.Path] = file
+	}
+	for p, item := range m.selected {
+		if parentPath(item.Path) != m.currentPath {
+			continue
+		}
+		if file, ok := listed[p]; ok {
+			m.selected[p] = file
+		} else {
+			delete(m.selected, p)
+		}
+	}
+}
+
+// selectedItems returns the selection: entries of the current folder in
+// display order, then those selected in other folders, sorted by path.
+// Entries inside a selected folder are left out, since the folder brings
+// them along; acting on both would download or move them twice.
+func (m Model) selectedItems() []FileItem {
+	var items, elsewhere []FileItem
+	here := make(map[string]bool)
+	for _, file := range m.visible {
+		if m.isSelected(file) && !m.inSelectedFolder(file.Path) {
+			items = append(items, file)
+			here[file.Path] = true
+		}
+	}
+	for p, file := range m.selected {
+		if !here[p] && !m.inSelectedFolder(p) {
+			elsewhere = append(elsewhere, file)
+		}
+	}
+	sort.Slice(elsewhere, func(i, j int) bool { return elsewhere[i].Path < elsewhere[j].Path })
+	return append(items, elsewhere...)
+}
+
+// inSelectedFolder reports whether p is inside a folder that's selected.
+func (m Model) inSelectedFolder(p string) bool {
+	for dir := parentPath(p); dir != ""; dir = parentPath(dir) {
+		if item, ok := m.selected[dir]; ok && item.IsFolder {
+			return true
+		}
+	}
+	return false
+}
+
+// elsewhereNote returns " (n in other folders)" for the items that aren't in
+// the current folder, so a prompt acting on them says they're included, or ""
+// if all are here.
+func (m Model) elsewhereNote(items []FileItem) string {
+	n := 0
+	for _, item := range items {
+		if parentPath(item.Path) != m.currentPath {
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d in other folders)", n)
+}
+
+// selectByPattern adds every entry whose name matches the glob pattern to the
+// selection, keeping anything already selected.
+func (m Model) selectByPattern(pattern string) (tea.Model, tea.Cmd) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		m.error = fmt.Sprintf("Invalid pattern %q: %v", pattern, err)
+		m.errorTime = time.Now()
+		return m, nil
+	}
+
+	added := 0
+	for _, file := range m.visible {
+		if ok, _ := filepath.Match(pattern, file.Name); ok && !m.isSelected(file) {
+			m.selected[file.Path] = file
+			added++
+		}
+	}
+
+	m.status = fmt.Sprintf("Selected %d more item(s) matching %s (%d selected)", added, pattern, len(m.selected))
+	m.statusTime = time.Now()
+	return m, nil
+}
+
+// markRange starts a range selection at the entry under the cursor or, if one
+// is already started, selects every entry from its start to the cursor.
+func (m *Model) markRange() {
+	if m.markPath != "" {
+		m.selectRange()
+		return
+	}
+	if m.cursor >= len(m.visible) {
+		return
+	}
+	m.markPath = m.visible[m.cursor].Path
+	m.status = fmt.Sprintf("Range started; move and press %s again to select", m.keys.describe(actionMarkRange))
+	m.statusTime = time.Now()
+}
+
+// selectRange selects every entry between the range's start and the cursor,
+// inclusive, and ends the range.
+func (m *Model) selectRange() {
+	mark := -1
+	for i, file := range m.visible {
+		if file.Path == m.markPath {
+			mark = i
+		}
+	}
+	m.markPath = ""
+	if mark < 0 || m.cursor >= len(m.visible) {
+		m.error = "The range's start is no longer in the list"
+		m.errorTime = time.Now()
+		return
+	}
+	from, to := min(mark, m.cursor), max(mark, m.cursor)
+	for _, file := range m.visible[from : to+1] {
+		m.selected[file.Path] = file
+	}
+	m.status = fmt.Sprintf("Selected %d item(s) (%d selected)", to-from+1, len(m.selected))
+	m.statusTime = time.Now()
+}
+
+// invertSelection selects every visible entry that isn't selected and
+// deselects the rest. Entries selected in other folders stay selected.
+func (m *Model) invertSelection() {
+	for _, file := range m.visible {
+		m.toggleSelected(file)
+	}
+	m.status = fmt.Sprintf("Inverted selection (%d selected)", len(m.selected))
+	m.statusTime = time.Now()
+}
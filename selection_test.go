@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// selectAt selects the visible entries at the given indexes.
+func selectAt(m *Model, indexes ...int) {
+	for _, i := range indexes {
+		m.selected[m.visible[i].Path] = m.visible[i]
+	}
+}
+
+// selectedPaths lists the selection's paths, sorted and space-separated.
+func selectedPaths(m Model) string {
+	var paths []string
+	for p := range m.selected {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, " ")
+}
+
+func TestSelectByPattern(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{
+		{Name: "a.pdf", Path: "/a.pdf"},
+		{Name: "b.txt", Path: "/b.txt"},
+		{Name: "c.pdf", Path: "/c.pdf"},
+		{Name: "notes", Path: "/notes"},
+	})
+	selectAt(&m, 1) // existing selections are kept
+
+	updated, _ := m.selectByPattern("*.pdf")
+	got := updated.(Model)
+	for _, i := range []int{0, 1, 2} {
+		if !got.isSelected(got.visible[i]) {
+			t.Errorf("index %d should be selected", i)
+		}
+	}
+	if got.isSelected(got.visible[3]) {
+		t.Error("non-matching entry was selected")
+	}
+
+	updated, _ = m.selectByPattern("[")
+	if updated.(Model).error == "" {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestInvertSelection(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}, {Name: "c", Path: "/c"}})
+	selectAt(&m, 1)
+	m.selected["/elsewhere/x"] = FileItem{Name: "x", Path: "/elsewhere/x"}
+
+	m.invertSelection()
+	if got := selectedPaths(m); got != "/a /c /elsewhere/x" {
+		t.Errorf("selection = %s, want a and c, and x from another folder kept", got)
+	}
+	if m.status != "Inverted selection (3 selected)" {
+		t.Errorf("status = %q", m.status)
+	}
+
+	m.invertSelection()
+	if got := selectedPaths(m); got != "/b /elsewhere/x" {
+		t.Errorf("inverting twice: selection = %s, want b and x", got)
+	}
+}
+
+func TestMarkRange(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a", Path: "/a"}, {Name: "b", Path: "/b"}, {Name: "c", Path: "/c"}, {Name: "d", Path: "/d"}})
+	m.cursor = 3
+
+	next, _ := m.runAction(actionMarkRange, 0)
+	m = next.(Model)
+	if m.markPath != "/d" {
+		t.Fatalf("mark = %q, want /d", m.markPath)
+	}
+	m.cursor = 1
+	next, _ = m.runAction(actionSelect, 0)
+	m = next.(Model)
+	if got := selectedPaths(m); m.markPath != "" || got != "/b /c /d" {
+		t.Errorf("space should select b through d and end the range: mark %q, selection %s", m.markPath, got)
+	}
+
+	// Marking and selecting in place selects just that entry.
+	m.selected = map[string]FileItem{}
+	m.markRange()
+	m.markRange()
+	if got := selectedPaths(m); got != "/b" {
+		t.Errorf("selection = %s, want just b", got)
+	}
+
+	// Changing folders drops an unfinished range.
+	m.markRange()
+	m.setFiles("/other", nil)
+	if m.markPath != "" {
+		t.Error("opening another folder should drop the range")
+	}
+}
+
+func TestSelectedItemsInsideSelectedFolder(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("/docs", []FileItem{{Name: "a.txt", Path: "/docs/a.txt"}, {Name: "b.txt", Path: "/docs/b.txt"}})
+	selectAt(&m, 0)
+	m.selected["/docs"] = FileItem{Name: "docs", Path: "/docs", IsFolder: true}
+	m.selected["/docsx.txt"] = FileItem{Name: "docsx.txt", Path: "/docsx.txt"}
+
+	// a.txt comes with docs, so it isn't listed (and downloaded) again.
+	var got []string
+	for _, item := range m.selectedItems() {
+		got = append(got, item.Path)
+	}
+	if strings.Join(got, " ") != "/docs /docsx.txt" {
+		t.Errorf("selectedItems = %v, want /docs /docsx.txt", got)
+	}
+
+	// Deselecting the folder takes what's selected inside it too.
+	m.deselect([]FileItem{m.selected["/docs"]})
+	if got := selectedPaths(m); got != "/docsx.txt" {
+		t.Errorf("after deselecting /docs: %s", got)
+	}
+}
+
+func TestSelectionIntegrity(t *testing.T) {
+	m := initialModel(&Config{DownloadCursor: true})
+	m.setFiles("", []FileItem{
+		{Name: "a.txt", Path: "/a.txt"},
+		{Name: "b.txt", Path: "/b.txt", Size: 1},
+		{Name: "c.txt", Path: "/c.txt"},
+	})
+	selectAt(&m, 1, 2)
+	m.cursor = 0
+
+	// The folder changes under the selection: an entry is added ahead of
+	// it, one selected entry goes away and the other is updated.
+	next, _ := m.Update(FilesLoadedMsg{Path: "", Files: []FileItem{
+		{Name: "a.txt", Path: "/a.txt"},
+		{Name: "aa.txt", Path: "/aa.txt"},
+		{Name: "b.txt", Path: "/b.txt", Size: 2},
+	}})
+	m = next.(Model)
+
+	_, cmd := m.runAction(actionDownload, 1)
+	msg, ok := cmd().(DownloadMsg)
+	if !ok || len(msg.Files) != 1 || msg.Files[0].Path != "/b.txt" || msg.Files[0].Size != 2 {
+		t.Fatalf("got %#v, want only the still-listed selection, as listed now", cmd())
+	}
+}
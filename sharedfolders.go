@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/sharing"
+)
+
+// sharedChrome is the number of lines the shared folders screen uses around
+// its list (title, blank lines, hint, and message).
+const sharedChrome = 6
+
+// sharedFolder is a folder someone has shared with the user. Path is where
+// it's mounted in their Dropbox, or "" if it isn't.
+type sharedFolder struct {
+	Name   string
+	Path   string
+	ID     string
+	Owners string
+}
+
+// SharedFoldersMsg carries the folders shared with the user, mounted or not.
+type SharedFoldersMsg struct {
+	Folders []sharedFolder
+}
+
+// FolderMountedMsg reports that a shared folder was added to the user's
+// Dropbox.
+type FolderMountedMsg struct {
+	Folder sharedFolder
+}
+
+// listSharedFoldersCmd lists the shared folders in the user's Dropbox and
+// the ones they can add to it.
+func listSharedFoldersCmd(config *Config) tea.Cmd {
+	return func() tea.Msg {
+		sc, err := newSharingClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		folders, err := listSharedFolders(sc)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to list shared folders: %v", err)}
+		}
+		return SharedFoldersMsg{Folders: folders}
+	}
+}
+
+// listSharedFolders gathers every page of mounted and mountable shared
+// folders, sorted by name. A folder listed as both is kept as mounted.
+func listSharedFolders(sc sharing.Client) ([]sharedFolder, error) {
+	byID := make(map[string]sharedFolder)
+	add := func(entries []*sharing.SharedFolderMetadata) {
+		for _, e := range entries {
+			if prev, ok := byID[e.SharedFolderId]; ok && prev.Path != "" {
+				continue
+			}
+			byID[e.SharedFolderId] = sharedFolder{
+				Name:   e.Name,
+				Path:   e.PathLower,
+				ID:     e.SharedFolderId,
+				Owners: strings.Join(e.OwnerDisplayNames, ", "),
+			}
+		}
+	}
+
+	res, err := sc.ListFolders(sharing.NewListFoldersArgs())
+	if err != nil {
+		return nil, err
+	}
+	add(res.Entries)
+	for res.Cursor != "" {
+		if res, err = sc.ListFoldersContinue(sharing.NewListFoldersContinueArg(res.Cursor)); err != nil {
+			return nil, err
+		}
+		add(res.Entries)
+	}
+
+	res, err = sc.ListMountableFolders(sharing.NewListFoldersArgs())
+	if err != nil {
+		return nil, err
+	}
+	add(res.Entries)
+	for res.Cursor != "" {
+		if res, err = sc.ListMountableFoldersContinue(sharing.NewListFoldersContinueArg(res.Cursor)); err != nil {
+			return nil, err
+		}
+		add(res.Entries)
+	}
+
+	folders := make([]sharedFolder, 0, len(byID))
+	for _, f := range byID {
+		folders = append(folders, f)
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		return strings.ToLower(folders[i].Name) < strings.ToLower(folders[j].Name)
+	})
+	return folders, nil
+}
+
+// mountFolderCmd adds a shared folder to the user's Dropbox.
+func mountFolderCmd(config *Config, folder sharedFolder) tea.Cmd {
+	return func() tea.Msg {
+		sc, err := newSharingClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		meta, err := sc.MountFolder(sharing.NewMountFolderArg(folder.ID))
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to add %s to your Dropbox: %v", folder.Name, err)}
+		}
+		folder.Path = meta.PathLower
+		return FolderMountedMsg{Folder: folder}
+	}
+}
+
+// openSharedFolders switches to the shared folders screen, listing them
+// afresh.
+func (m Model) openSharedFolders() (tea.Model, tea.Cmd) {
+	m.shared = []sharedFolder{}
+	m.sharedCursor = 0
+	m.status = "Listing shared folders..."
+	m.statusTime = time.Now()
+	return m, listSharedFoldersCmd(&m.config)
+}
+
+// handleSharedFolders shows the listed folders, if the screen is still open.
+func (m Model) handleSharedFolders(msg SharedFoldersMsg) (tea.Model, tea.Cmd) {
+	if m.shared == nil {
+		return m, nil
+	}
+	m.shared = msg.Folders
+	m.sharedCursor = min(m.sharedCursor, max(0, len(m.shared)-1))
+	m.status = ""
+	return m, nil
+}
+
+// handleFolderMounted marks a newly mounted folder as such. Its parent's
+// cached listing no longer shows everything, so the cache is cleared.
+func (m Model) handleFolderMounted(msg FolderMountedMsg) (tea.Model, tea.Cmd) {
+	for i, f := range m.shared {
+		if f.ID == msg.Folder.ID {
+			m.shared[i] = msg.Folder
+		}
+	}
+	m.clearCache()
+	m.status = fmt.Sprintf("Added %s to your Dropbox at %s", msg.Folder.Name, msg.Folder.Path)
+	m.statusTime = time.Now()
+	return m, nil
+}
+
+// handleSharedKey moves through the shared folders screen. enter opens a
+// mounted folder in the file list, m mounts one that isn't, and esc or q
+// goes back.
+func (m Model) handleSharedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.shared = nil
+	case "down", "j":
+		m.sharedCursor = min(m.sharedCursor+1, max(0, len(m.shared)-1))
+	case "up", "k":
+		m.sharedCursor = max(m.sharedCursor-1, 0)
+	case "enter":
+		if m.sharedCursor >= len(m.shared) {
+			break
+		}
+		folder := m.shared[m.sharedCursor]
+		if folder.Path == "" {
+			m.error = folder.Name + " isn't in your Dropbox yet; press m to add it"
+			m.errorTime = time.Now()
+			break
+		}
+		m.shared = nil
+		if cachedFiles, exists := m.cachedFolder(folder.Path); exists {
+			m.setFiles(folder.Path, cachedFiles)
+			return m, nil
+		}
+		cmd := m.load(folder.Path)
+		return m, cmd
+	case "m":
+		if m.sharedCursor < len(m.shared) && m.shared[m.sharedCursor].Path == "" {
+			folder := m.shared[m.sharedCursor]
+			m.status = "Adding " + folder.Name + " to your Dropbox..."
+			m.statusTime = time.Now()
+			return m, mountFolderCmd(&m.config, folder)
+		}
+	}
+	return m, nil
+}
+
+// renderSharedView renders the shared folders screen.
+func (m Model) renderSharedView() string {
+	theme := m.config.Theme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	cursorStyle := lipgloss.NewStyle().Foreground(theme.Cursor).Bold(true)
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Shared with you") + "\n\n")
+	if len(m.shared) == 0 {
+		s.WriteString(mutedStyle.Render("No shared folders") + "\n")
+	}
+
+	page := max(1, m.height-sharedChrome)
+	offset := max(0, m.sharedCursor-page+1)
+	for i := offset; i < min(len(m.shared), offset+page); i++ {
+		f := m.shared[i]
+		where := "not in your Dropbox"
+		if f.Path != "" {
+			where = f.Path
+		}
+		if f.Owners != "" {
+			where += " · " + f.Owners
+		}
+		line := "📁 " + f.Name
+		if i == m.sharedCursor {
+			line = cursorStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		s.WriteString(line + "  " + mutedStyle.Render(where) + "\n")
+	}
+
+	s.WriteString("\n" + mutedStyle.Render("enter to open · m to add to your Dropbox · esc to go back") + "\n")
+	if m.error != "" && shown(m.errorTime, m.config.ErrorTimeout) {
+		s.WriteString(lipgloss.NewStyle().Foreground(theme.Error).Render("❌ "+m.error) + "\n")
+	} else if m.status != "" && shown(m.statusTime, m.config.StatusTimeout) {
+		s.WriteString(lipgloss.NewStyle().Foreground(theme.Status).Render(m.status) + "\n")
+	}
+	return s.String()
+}
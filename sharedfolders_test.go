@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/sharing"
+)
+
+// fakeSharedFoldersClient pages through mounted and mountable folders.
+type fakeSharedFoldersClient struct {
+	sharing.Client
+	mounted, mountable [][]*sharing.SharedFolderMetadata
+}
+
+func folderMeta(id, name, path string) *sharing.SharedFolderMetadata {
+	meta := &sharing.SharedFolderMetadata{Name: name, SharedFolderId: id}
+	meta.PathLower = path
+	return meta
+}
+
+func folderPage(pages [][]*sharing.SharedFolderMetadata, i int) *sharing.ListFoldersResult {
+	res := &sharing.ListFoldersResult{Entries: pages[i]}
+	if i+1 < len(pages) {
+		res.Cursor = string(rune('0' + i + 1))
+	}
+	return res
+}
+
+func (f *fakeSharedFoldersClient) ListFolders(*sharing.ListFoldersArgs) (*sharing.ListFoldersResult, error) {
+	return folderPage(f.mounted, 0), nil
+}
+
+func (f *fakeSharedFoldersClient) ListFoldersContinue(arg *sharing.ListFoldersContinueArg) (*sharing.ListFoldersResult, error) {
+	return folderPage(f.mounted, int(arg.Cursor[0]-'0')), nil
+}
+
+func (f *fakeSharedFoldersClient) ListMountableFolders(*sharing.ListFoldersArgs) (*sharing.ListFoldersResult, error) {
+	return folderPage(f.mountable, 0), nil
+}
+
+func (f *fakeSharedFoldersClient) ListMountableFoldersContinue(arg *sharing.ListFoldersContinueArg) (*sharing.ListFoldersResult, error) {
+	return folderPage(f.mountable, int(arg.Cursor[0]-'0')), nil
+}
+
+func TestListSharedFolders(t *testing.T) {
+	sc := &fakeSharedFoldersClient{
+		mounted: [][]*sharing.SharedFolderMetadata{
+			{folderMeta("1", "Team", "/team")},
+			{folderMeta("2", "budget", "/work/budget")},
+		},
+		mountable: [][]*sharing.SharedFolderMetadata{
+			{folderMeta("3", "Photos", "")},
+			{folderMeta("1", "Team", "")}, // mounted, so listed as such
+		},
+	}
+	folders, err := listSharedFolders(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sharedFolder{
+		{Name: "budget", Path: "/work/budget", ID: "2"},
+		{Name: "Photos", ID: "3"},
+		{Name: "Team", Path: "/team", ID: "1"},
+	}
+	if len(folders) != len(want) {
+		t.Fatalf("folders = %+v, want %+v", folders, want)
+	}
+	for i := range want {
+		if folders[i] != want[i] {
+			t.Errorf("folder %d = %+v, want %+v", i, folders[i], want[i])
+		}
+	}
+}
+
+func TestSharedFoldersKeys(t *testing.T) {
+	m := initialModel(&Config{})
+	next, _ := m.openSharedFolders()
+	next, _ = next.(Model).handleSharedFolders(SharedFoldersMsg{Folders: []sharedFolder{
+		{Name: "Photos", ID: "3"},
+		{Name: "Team", Path: "/team", ID: "1"},
+	}})
+	m = next.(Model)
+
+	// An unmounted folder can't be opened, only added.
+	next, cmd := m.handleSharedKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(Model); m.shared == nil || cmd != nil || m.error == "" {
+		t.Fatalf("enter on an unmounted folder: shared = %v, error = %q", m.shared, m.error)
+	}
+	if _, cmd = m.handleSharedKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}); cmd == nil {
+		t.Error("m on an unmounted folder should mount it")
+	}
+	next, _ = m.handleFolderMounted(FolderMountedMsg{Folder: sharedFolder{Name: "Photos", Path: "/photos", ID: "3"}})
+	if m = next.(Model); m.shared[0].Path != "/photos" {
+		t.Errorf("after mounting, folder = %+v", m.shared[0])
+	}
+
+	// A mounted folder opens in the file list.
+	next, _ = m.handleSharedKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, cmd = next.(Model).handleSharedKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(Model); m.shared != nil || cmd == nil || !m.loading {
+		t.Errorf("enter on a mounted folder should close the screen and load it")
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/async"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/sharing"
+)
+
+// linkRoot is a shared link being browsed with `dbox link`.
+type linkRoot struct {
+	URL      string
+	Password string
+	Name     string // the shared file or folder's name
+}
+
+// errLinkReadOnly is returned for operations a shared link doesn't allow.
+var errLinkReadOnly = errors.New("not available when browsing a shared link")
+
+// linkReadOnlyCmd reports that a shared link can't be changed.
+func linkReadOnlyCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ErrorMsg{Error: "Shared links are read-only"}
+	}
+}
+
+// sharedLinkClient serves the files.Client calls browse mode makes from a
+// shared link. The rest of files.Client (in sharedlink_unsupported.go)
+// refuses with errLinkReadOnly, so a call that isn't guarded against links
+// fails with an error rather than panicking.
+type sharedLinkClient struct {
+	account files.Client // only used for ListFolder, which takes the link
+	sharing sharing.Client
+	link    *linkRoot
+}
+
+// newSharedLinkClient builds a client for link from stored credentials.
+func newSharedLinkClient(link *linkRoot, config *Config) (files.Client, error) {
+	cfg, err := newConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedLinkClient{account: files.New(cfg), sharing: sharing.New(cfg), link: link}, nil
+}
+
+// metadataArg builds a sharing request for a path inside the link.
+func (c *sharedLinkClient) metadataArg(p string) *sharing.GetSharedLinkMetadataArg {
+	arg := sharing.NewGetSharedLinkMetadataArg(c.link.URL)
+	arg.Path = p
+	arg.LinkPassword = c.link.Password
+	return arg
+}
+
+// ListFolder lists a folder inside the link. Entries of a shared link have no
+// account path, so one is filled in relative to the link root. A later page
+// wouldn't say which folder it's from, so every page is fetched here and the
+// listing returned whole.
+func (c *sharedLinkClient) ListFolder(arg *files.ListFolderArg) (*files.ListFolderResult, error) {
+	arg.SharedLink = &files.SharedLink{Url: c.link.URL, Password: c.link.Password}
+	res, err := c.account.ListFolder(arg)
+	if err != nil {
+		return nil, err
+	}
+	for res.HasMore {
+		more, err := c.account.ListFolderContinue(files.NewListFolderContinueArg(res.Cursor))
+		if err != nil {
+			return nil, err
+		}
+		res.Entries = append(res.Entries, more.Entries...)
+		res.Cursor, res.HasMore = more.Cursor, more.HasMore
+	}
+	for _, entry := range res.Entries {
+		switch v := entry.(type) {
+		case *files.FileMetadata:
+			v.PathDisplay = path.Join("/", arg.Path, v.Name)
+			v.PathLower = strings.ToLower(v.PathDisplay)
+		case *files.FolderMetadata:
+			v.PathDisplay = path.Join("/", arg.Path, v.Name)
+			v.PathLower = strings.ToLower(v.PathDisplay)
+		}
+	}
+	return res, nil
+}
+
+// ListFolderContinue refuses: ListFolder never leaves pages to continue.
+func (c *sharedLinkClient) ListFolderContinue(*files.ListFolderContinueArg) (*files.ListFolderResult, error) {
+	return nil, errors.New("shared link listings aren't paged")
+}
+
+// Download fetches a file inside the link. The sharing endpoint doesn't take
+// a Range header, so when resuming the bytes already on disk are skipped.
+func (c *sharedLinkClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	res, contents, err := c.sharing.GetSharedLinkFile(c.metadataArg(arg.Path))
+	if err != nil {
+		return nil, nil, err
+	}
+	var offset int64
+	if r := arg.ExtraHeaders["Range"]; r != "" {
+		fmt.Sscanf(r, "bytes=%d-", &offset)
+	}
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, contents, offset); err != nil {
+			contents.Close()
+			return nil, nil, err
+		}
+	}
+	meta, ok := linkMetadata(res, arg.Path).(*files.FileMetadata)
+	if !ok {
+		contents.Close()
+		return nil, nil, fmt.Errorf("%s is not a file", arg.Path)
+	}
+	return meta, contents, nil
+}
+
+// GetMetadata looks up a file or folder inside the link.
+func (c *sharedLinkClient) GetMetadata(arg *files.GetMetadataArg) (files.IsMetadata, error) {
+	res, err := c.sharing.GetSharedLinkMetadata(c.metadataArg(arg.Path))
+	if err != nil {
+		return nil, err
+	}
+	meta := linkMetadata(res, arg.Path)
+	if meta == nil {
+		return nil, fmt.Errorf("not a file or folder")
+	}
+	return meta, nil
+}
+
+// DeleteBatch refuses: shared links are read-only.
+func (c *sharedLinkClient) DeleteBatch(*files.DeleteBatchArg) (*files.DeleteBatchLaunch, error) {
+	return nil, errLinkReadOnly
+}
+
+// DeleteBatchCheck refuses: shared links are read-only.
+func (c *sharedLinkClient) DeleteBatchCheck(*async.PollArg) (*files.DeleteBatchJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// MoveBatchV2 refuses: shared links are read-only.
+func (c *sharedLinkClient) MoveBatchV2(*files.MoveBatchArg) (*files.RelocationBatchV2Launch, error) {
+	return nil, errLinkReadOnly
+}
+
+// MoveBatchCheckV2 refuses: shared links are read-only.
+func (c *sharedLinkClient) MoveBatchCheckV2(*async.PollArg) (*files.RelocationBatchV2JobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// Export refuses: Paper docs can't be exported through a shared link.
+func (c *sharedLinkClient) Export(*files.ExportArg) (*files.ExportResult, io.ReadCloser, error) {
+	return nil, nil, errLinkReadOnly
+}
+
+// linkMetadata converts shared link metadata for the entry at p into the
+// files metadata the rest of dbox works with, or nil if it's neither a file
+// nor a folder.
+func linkMetadata(res sharing.IsSharedLinkMetadata, p string) files.IsMetadata {
+	switch v := res.(type) {
+	case *sharing.FileLinkMetadata:
+		meta := files.NewFileMetadata(v.Name, v.Id, v.ClientModified, v.ServerModified, v.Rev, v.Size)
+		meta.PathDisplay = p
+		meta.PathLower = strings.ToLower(p)
+		meta.IsDownloadable = true
+		return meta
+	case *sharing.FolderLinkMetadata:
+		meta := files.NewFolderMetadata(v.Name, v.Id)
+		meta.PathDisplay = p
+		meta.PathLower = strings.ToLower(p)
+		return meta
+	default:
+		return nil
+	}
+}
+
+// runLink implements `dbox link [--password pw] <url>`. A folder link is
+// returned to be opened in browse mode; a file link is downloaded straight
+// away and nil is returned. Downloads go under <download dir>/shared so they
+// don't mix with files from the account.
+func runLink(args []string, config *Config) (*linkRoot, error) {
+	fs := flag.NewFlagSet("link", flag.ContinueOnError)
+	password := fs.String("password", "", "password for a password-protected link")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox link [--password pw] <url>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return nil, fmt.Errorf("expected one shared link URL")
+	}
+
+	link := &linkRoot{URL: fs.Arg(0), Password: *password}
+	dbx, err := newSharedLinkClient(link, config)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := dbx.GetMetadata(files.NewGetMetadataArg(""))
+	if err != nil {
+		return nil, fmt.Errorf("could not open link: %w", err)
+	}
+	switch v := meta.(type) {
+	case *files.FileMetadata:
+		return nil, downloadLinkedFile(dbx, v, config)
+	case *files.FolderMetadata:
+		link.Name = v.Name
+	}
+	return link, nil
+}
+
+// downloadLinkedFile downloads the file a file link points at into
+// <download dir>/shared.
+func downloadLinkedFile(dbx files.Client, file *files.FileMetadata, config *Config) error {
+	localPath, err := localDownloadPath(linkDownloadDir(config.DownloadPath), "/"+file.Name)
+	if err == nil {
+		localPath, err = fitPathLimits(localPath, runtime.GOOS)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(localPath); err == nil {
+		fmt.Printf("%s already exists\n", localPath)
+		return nil
+	}
+	if err := os.MkdirAll(linkDownloadDir(config.DownloadPath), config.dirMode()); err != nil {
+		return err
+	}
+	var counter, preloaded atomic.Int64
+	if err := downloadToFile(dbx, "", int64(file.Size), localPath, config.fileMode(), &counter, &preloaded); err != nil {
+		return err
+	}
+	fmt.Printf("Downloaded %s to %s\n", file.Name, localPath)
+	return nil
+}
+
+// linkDownloadDir is where downloads from shared links go.
+func linkDownloadDir(downloadDir string) string {
+	return filepath.Join(downloadDir, "shared")
+}
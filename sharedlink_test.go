@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/sharing"
+)
+
+// fakeSharingClient serves one shared file's contents. Any other method
+// panics via the nil embedded interface.
+type fakeSharingClient struct {
+	sharing.Client
+	content string
+	paths   []string // paths requested
+}
+
+func (f *fakeSharingClient) GetSharedLinkFile(arg *sharing.GetSharedLinkMetadataArg) (sharing.IsSharedLinkMetadata, io.ReadCloser, error) {
+	f.paths = append(f.paths, arg.Path)
+	meta := sharing.NewFileLinkMetadata(arg.Url, "notes.txt", nil, time.Time{}, time.Time{}, "rev1", uint64(len(f.content)))
+	return meta, io.NopCloser(strings.NewReader(f.content)), nil
+}
+
+func TestSharedLinkListFolderPaths(t *testing.T) {
+	// Link entries come back without account paths; they're filled in
+	// relative to the link root.
+	account := &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/Docs": {
+			&files.FileMetadata{Metadata: files.Metadata{Name: "Notes.txt"}},
+			&files.FolderMetadata{Metadata: files.Metadata{Name: "Old"}},
+		},
+	}}
+	link := &linkRoot{URL: "https://www.dropbox.com/sh/abc", Password: "pw"}
+	dbx := &sharedLinkClient{account: account, link: link}
+
+	entries, err := listFolderEntries(dbx, "/Docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	if want := "/docs/notes.txt,/docs/old"; strings.Join(paths, ",") != want {
+		t.Errorf("paths = %v, want %s", paths, want)
+	}
+}
+
+// pagedFilesClient lists one folder in pages, continued by page number.
+type pagedFilesClient struct {
+	files.Client
+	pages [][]files.IsMetadata
+}
+
+func (f *pagedFilesClient) ListFolder(*files.ListFolderArg) (*files.ListFolderResult, error) {
+	return f.page(0), nil
+}
+
+func (f *pagedFilesClient) ListFolderContinue(arg *files.ListFolderContinueArg) (*files.ListFolderResult, error) {
+	var i int
+	fmt.Sscan(arg.Cursor, &i)
+	return f.page(i), nil
+}
+
+func (f *pagedFilesClient) page(i int) *files.ListFolderResult {
+	return &files.ListFolderResult{Entries: f.pages[i], Cursor: fmt.Sprint(i + 1), HasMore: i+1 < len(f.pages)}
+}
+
+func TestSharedLinkListFolderPages(t *testing.T) {
+	account := &pagedFilesClient{pages: [][]files.IsMetadata{
+		{&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt"}}},
+		{&files.FileMetadata{Metadata: files.Metadata{Name: "b.txt"}}},
+	}}
+	dbx := &sharedLinkClient{account: account, link: &linkRoot{URL: "https://www.dropbox.com/sh/abc"}}
+
+	items, cursor, err := listFolderPage(dbx, "/docs", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].Path != "/docs/b.txt" || cursor != "" {
+		t.Errorf("got %v with cursor %q, want both pages at once with link paths", items, cursor)
+	}
+}
+
+func TestSharedLinkDownloadResumes(t *testing.T) {
+	// The sharing endpoint has no Range support, so bytes already in the .part
+	// file are skipped from the full response.
+	dir := t.TempDir()
+	local := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(local+partSuffix, []byte("hello "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sc := &fakeSharingClient{content: "hello world"}
+	dbx := &sharedLinkClient{sharing: sc, link: &linkRoot{URL: "https://www.dropbox.com/sh/abc"}}
+
+	var counter, preloaded atomic.Int64
+	if err := downloadToFile(dbx, "/notes.txt", 11, local, 0644, &counter, &preloaded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello world" {
+		t.Errorf("contents = %q, want %q", got, "hello world")
+	}
+	if len(sc.paths) != 1 || sc.paths[0] != "/notes.txt" {
+		t.Errorf("requested paths = %v, want [/notes.txt]", sc.paths)
+	}
+}
+
+func TestLinkMetadata(t *testing.T) {
+	file := sharing.NewFileLinkMetadata("u", "Report.PDF", nil, time.Time{}, time.Time{}, "rev1", 42)
+	meta, ok := linkMetadata(file, "/Sub/Report.PDF").(*files.FileMetadata)
+	if !ok {
+		t.Fatalf("file link converted to %T", meta)
+	}
+	if meta.PathLower != "/sub/report.pdf" || meta.Size != 42 || !meta.IsDownloadable {
+		t.Errorf("file metadata = %+v", meta)
+	}
+
+	folder := &sharing.FolderLinkMetadata{SharedLinkMetadata: sharing.SharedLinkMetadata{Name: "Sub"}}
+	if _, ok := linkMetadata(folder, "/Sub").(*files.FolderMetadata); !ok {
+		t.Error("folder link didn't convert to folder metadata")
+	}
+	if linkMetadata(&sharing.SharedLinkMetadata{}, "") != nil {
+		t.Error("unknown link metadata should convert to nil")
+	}
+}
+
+func TestSharedLinkRefusesUnsupported(t *testing.T) {
+	dbx := &sharedLinkClient{link: &linkRoot{URL: "https://www.dropbox.com/sh/abc"}}
+	if _, _, err := dbx.DownloadZip(files.NewDownloadZipArg("/a")); !errors.Is(err, errLinkReadOnly) {
+		t.Errorf("DownloadZip = %v, want errLinkReadOnly", err)
+	}
+	if _, err := dbx.DeleteV2(files.NewDeleteArg("/a")); !errors.Is(err, errLinkReadOnly) {
+		t.Errorf("DeleteV2 = %v, want errLinkReadOnly", err)
+	}
+}
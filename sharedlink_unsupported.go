@@ -0,0 +1,309 @@
+package main
+
+import (
+	"io"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/async"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/file_properties"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// AlphaGetMetadata refuses: shared links don't support it.
+func (c *sharedLinkClient) AlphaGetMetadata(*files.AlphaGetMetadataArg) (files.IsMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// AlphaUpload refuses: shared links don't support it.
+func (c *sharedLinkClient) AlphaUpload(*files.UploadArg, io.Reader) (*files.FileMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyV2(*files.RelocationArg) (*files.RelocationResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// Copy refuses: shared links don't support it.
+func (c *sharedLinkClient) Copy(*files.RelocationArg) (files.IsMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyBatchV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyBatchV2(*files.RelocationBatchArgBase) (*files.RelocationBatchV2Launch, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyBatch(*files.RelocationBatchArg) (*files.RelocationBatchLaunch, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyBatchCheckV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyBatchCheckV2(*async.PollArg) (*files.RelocationBatchV2JobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyBatchCheck refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyBatchCheck(*async.PollArg) (*files.RelocationBatchJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyReferenceGet refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyReferenceGet(*files.GetCopyReferenceArg) (*files.GetCopyReferenceResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// CopyReferenceSave refuses: shared links don't support it.
+func (c *sharedLinkClient) CopyReferenceSave(*files.SaveCopyReferenceArg) (*files.SaveCopyReferenceResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// CreateFolderV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) CreateFolderV2(*files.CreateFolderArg) (*files.CreateFolderResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// CreateFolder refuses: shared links don't support it.
+func (c *sharedLinkClient) CreateFolder(*files.CreateFolderArg) (*files.FolderMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// CreateFolderBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) CreateFolderBatch(*files.CreateFolderBatchArg) (*files.CreateFolderBatchLaunch, error) {
+	return nil, errLinkReadOnly
+}
+
+// CreateFolderBatchCheck refuses: shared links don't support it.
+func (c *sharedLinkClient) CreateFolderBatchCheck(*async.PollArg) (*files.CreateFolderBatchJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// DeleteV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) DeleteV2(*files.DeleteArg) (*files.DeleteResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// Delete refuses: shared links don't support it.
+func (c *sharedLinkClient) Delete(*files.DeleteArg) (files.IsMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// DownloadZip refuses: shared links don't support it.
+func (c *sharedLinkClient) DownloadZip(*files.DownloadZipArg) (*files.DownloadZipResult, io.ReadCloser, error) {
+	return nil, nil, errLinkReadOnly
+}
+
+// GetFileLockBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) GetFileLockBatch(*files.LockFileBatchArg) (*files.LockFileBatchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// GetPreview refuses: shared links don't support it.
+func (c *sharedLinkClient) GetPreview(*files.PreviewArg) (*files.FileMetadata, io.ReadCloser, error) {
+	return nil, nil, errLinkReadOnly
+}
+
+// GetTemporaryLink refuses: shared links don't support it.
+func (c *sharedLinkClient) GetTemporaryLink(*files.GetTemporaryLinkArg) (*files.GetTemporaryLinkResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// GetTemporaryUploadLink refuses: shared links don't support it.
+func (c *sharedLinkClient) GetTemporaryUploadLink(*files.GetTemporaryUploadLinkArg) (*files.GetTemporaryUploadLinkResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// GetThumbnail refuses: shared links don't support it.
+func (c *sharedLinkClient) GetThumbnail(*files.ThumbnailArg) (*files.FileMetadata, io.ReadCloser, error) {
+	return nil, nil, errLinkReadOnly
+}
+
+// GetThumbnailV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) GetThumbnailV2(*files.ThumbnailV2Arg) (*files.PreviewResult, io.ReadCloser, error) {
+	return nil, nil, errLinkReadOnly
+}
+
+// GetThumbnailBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) GetThumbnailBatch(*files.GetThumbnailBatchArg) (*files.GetThumbnailBatchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// ListFolderGetLatestCursor refuses: shared links don't support it.
+func (c *sharedLinkClient) ListFolderGetLatestCursor(*files.ListFolderArg) (*files.ListFolderGetLatestCursorResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// ListFolderLongpoll refuses: shared links don't support it.
+func (c *sharedLinkClient) ListFolderLongpoll(*files.ListFolderLongpollArg) (*files.ListFolderLongpollResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// ListRevisions refuses: shared links don't support it.
+func (c *sharedLinkClient) ListRevisions(*files.ListRevisionsArg) (*files.ListRevisionsResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// LockFileBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) LockFileBatch(*files.LockFileBatchArg) (*files.LockFileBatchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// MoveV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) MoveV2(*files.RelocationArg) (*files.RelocationResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// Move refuses: shared links don't support it.
+func (c *sharedLinkClient) Move(*files.RelocationArg) (files.IsMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// MoveBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) MoveBatch(*files.RelocationBatchArg) (*files.RelocationBatchLaunch, error) {
+	return nil, errLinkReadOnly
+}
+
+// MoveBatchCheck refuses: shared links don't support it.
+func (c *sharedLinkClient) MoveBatchCheck(*async.PollArg) (*files.RelocationBatchJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// PaperCreate refuses: shared links don't support it.
+func (c *sharedLinkClient) PaperCreate(*files.PaperCreateArg, io.Reader) (*files.PaperCreateResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// PaperUpdate refuses: shared links don't support it.
+func (c *sharedLinkClient) PaperUpdate(*files.PaperUpdateArg, io.Reader) (*files.PaperUpdateResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// PermanentlyDelete refuses: shared links don't support it.
+func (c *sharedLinkClient) PermanentlyDelete(*files.DeleteArg) error {
+	return errLinkReadOnly
+}
+
+// PropertiesAdd refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesAdd(*file_properties.AddPropertiesArg) error {
+	return errLinkReadOnly
+}
+
+// PropertiesOverwrite refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesOverwrite(*file_properties.OverwritePropertyGroupArg) error {
+	return errLinkReadOnly
+}
+
+// PropertiesRemove refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesRemove(*file_properties.RemovePropertiesArg) error {
+	return errLinkReadOnly
+}
+
+// PropertiesTemplateGet refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesTemplateGet(*file_properties.GetTemplateArg) (*file_properties.GetTemplateResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// PropertiesTemplateList refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesTemplateList() (*file_properties.ListTemplateResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// PropertiesUpdate refuses: shared links don't support it.
+func (c *sharedLinkClient) PropertiesUpdate(*file_properties.UpdatePropertiesArg) error {
+	return errLinkReadOnly
+}
+
+// Restore refuses: shared links don't support it.
+func (c *sharedLinkClient) Restore(*files.RestoreArg) (*files.FileMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// SaveUrl refuses: shared links don't support it.
+func (c *sharedLinkClient) SaveUrl(*files.SaveUrlArg) (*files.SaveUrlResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// SaveUrlCheckJobStatus refuses: shared links don't support it.
+func (c *sharedLinkClient) SaveUrlCheckJobStatus(*async.PollArg) (*files.SaveUrlJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// Search refuses: shared links don't support it.
+func (c *sharedLinkClient) Search(*files.SearchArg) (*files.SearchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// SearchV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) SearchV2(*files.SearchV2Arg) (*files.SearchV2Result, error) {
+	return nil, errLinkReadOnly
+}
+
+// SearchContinueV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) SearchContinueV2(*files.SearchV2ContinueArg) (*files.SearchV2Result, error) {
+	return nil, errLinkReadOnly
+}
+
+// TagsAdd refuses: shared links don't support it.
+func (c *sharedLinkClient) TagsAdd(*files.AddTagArg) error {
+	return errLinkReadOnly
+}
+
+// TagsGet refuses: shared links don't support it.
+func (c *sharedLinkClient) TagsGet(*files.GetTagsArg) (*files.GetTagsResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// TagsRemove refuses: shared links don't support it.
+func (c *sharedLinkClient) TagsRemove(*files.RemoveTagArg) error {
+	return errLinkReadOnly
+}
+
+// UnlockFileBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) UnlockFileBatch(*files.UnlockFileBatchArg) (*files.LockFileBatchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// Upload refuses: shared links don't support it.
+func (c *sharedLinkClient) Upload(*files.UploadArg, io.Reader) (*files.FileMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionAppendV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionAppendV2(*files.UploadSessionAppendArg, io.Reader) error {
+	return errLinkReadOnly
+}
+
+// UploadSessionAppend refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionAppend(*files.UploadSessionCursor, io.Reader) error {
+	return errLinkReadOnly
+}
+
+// UploadSessionFinish refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionFinish(*files.UploadSessionFinishArg, io.Reader) (*files.FileMetadata, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionFinishBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionFinishBatch(*files.UploadSessionFinishBatchArg) (*files.UploadSessionFinishBatchLaunch, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionFinishBatchV2 refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionFinishBatchV2(*files.UploadSessionFinishBatchArg) (*files.UploadSessionFinishBatchResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionFinishBatchCheck refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionFinishBatchCheck(*async.PollArg) (*files.UploadSessionFinishBatchJobStatus, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionStart refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionStart(*files.UploadSessionStartArg, io.Reader) (*files.UploadSessionStartResult, error) {
+	return nil, errLinkReadOnly
+}
+
+// UploadSessionStartBatch refuses: shared links don't support it.
+func (c *sharedLinkClient) UploadSessionStartBatch(*files.UploadSessionStartBatchArg) (*files.UploadSessionStartBatchResult, error) {
+	return nil, errLinkReadOnly
+}
@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// formatSize formats a byte count for display: in KiB, MiB, ... (powers of
+// 1024) when binary, as most file managers show, e.g. "1.5 MiB", or else in
+// KB, MB, ... (powers of 1000), e.g. "1.6 MB".
+func formatSize(size int64, binary bool) string {
+	unit, suffix := int64(1000), "B"
+	if binary {
+		unit, suffix = 1024, "iB"
+	}
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %c%s", float64(size)/float64(div), "KMGTPE"[exp], suffix)
+}
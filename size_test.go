@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size           int64
+		binary, metric string
+	}{
+		{0, "0 B", "0 B"},
+		{999, "999 B", "999 B"},
+		{1000, "1000 B", "1.0 KB"},
+		{1536, "1.5 KiB", "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MiB", "5.2 MB"},
+		{3_000_000_000, "2.8 GiB", "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.size, true); got != tt.binary {
+			t.Errorf("binary formatSize(%d) = %q, want %q", tt.size, got, tt.binary)
+		}
+		if got := formatSize(tt.size, false); got != tt.metric {
+			t.Errorf("decimal formatSize(%d) = %q, want %q", tt.size, got, tt.metric)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// compareEntries orders two entries for display, returning a negative number
+// when a comes first. With foldersFirst, folders sort ahead of files and each
+// group is ordered by name; otherwise folders and files are mixed together.
+// Names compare case-insensitively, falling back to exact case for ties so the
+// order is stable.
+func compareEntries(a, b FileItem, foldersFirst bool) int {
+	if foldersFirst && a.IsFolder != b.IsFolder {
+		if a.IsFolder {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// sortEntries sorts files in place into display order (see compareEntries).
+func sortEntries(files []FileItem, foldersFirst bool) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return compareEntries(files[i], files[j], foldersFirst) < 0
+	})
+}
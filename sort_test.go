@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortEntries(t *testing.T) {
+	entries := func() []FileItem {
+		return []FileItem{
+			{Name: "b.txt"},
+			{Name: "Zeta", IsFolder: true},
+			{Name: "a.txt"},
+			{Name: "alpha", IsFolder: true},
+			{Name: "B.txt"},
+		}
+	}
+	names := func(files []FileItem) []string {
+		var out []string
+		for _, f := range files {
+			out = append(out, f.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		foldersFirst bool
+		want         []string
+	}{
+		{true, []string{"alpha", "Zeta", "a.txt", "B.txt", "b.txt"}},
+		{false, []string{"a.txt", "alpha", "B.txt", "b.txt", "Zeta"}},
+	}
+	for _, tt := range tests {
+		files := entries()
+		sortEntries(files, tt.foldersFirst)
+		if got := names(files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("foldersFirst=%v: got %v, want %v", tt.foldersFirst, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// spinnerFrames animate the loading indicator, one per spinnerInterval.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how often the loading indicator advances.
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerTickMsg advances the loading indicator.
+type spinnerTickMsg struct{}
+
+// spinnerTickCmd schedules the next spinner frame.
+func spinnerTickCmd() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// load starts loading the folder at p, animating the loading indicator until
+// it arrives.
+func (m *Model) load(p string) tea.Cmd {
+	m.loading = true
+	return tea.Batch(loadFilesCmd(&m.config, p), m.startSpinner())
+}
+
+// startSpinner starts the loading indicator's ticks, unless they're already
+// running.
+func (m *Model) startSpinner() tea.Cmd {
+	if m.spinning {
+		return nil
+	}
+	m.spinning = true
+	return spinnerTickCmd()
+}
+
+// handleSpinnerTick advances the loading indicator, stopping once nothing is
+// loading or being measured.
+func (m Model) handleSpinnerTick() (tea.Model, tea.Cmd) {
+	if !m.loading && m.sizing == nil {
+		m.spinning = false
+		return m, nil
+	}
+	m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
+	return m, spinnerTickCmd()
+}
+
+// loadingLine describes what's loading, with the current spinner frame. Until
+// the first folder arrives it says dbox is connecting, since the first call
+// can take a while on a slow link.
+func (m Model) loadingLine() string {
+	msg := "Loading files..."
+	if !m.connected {
+		msg = "Connecting to Dropbox..."
+	}
+	return spinnerFrames[m.spinnerFrame] + " " + msg
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// tempLinkLifetime is how long Dropbox keeps a temporary link working.
+const tempLinkLifetime = 4 * time.Hour
+
+// promptTempLink asks whether to copy or open a temporary link to the file
+// under the cursor, for streaming it instead of downloading it.
+func (m Model) promptTempLink() (tea.Model, tea.Cmd) {
+	if m.cursor >= len(m.visible) {
+		return m, nil
+	}
+	file := m.visible[m.cursor]
+	var reason string
+	switch {
+	case m.config.link != nil:
+		reason = "Temporary links can't be made for files in a shared link"
+	case file.IsFolder:
+		reason = "Temporary links are for files; " + file.Name + " is a folder"
+	case file.Exportable:
+		reason = "Temporary links can't be made for Paper docs"
+	}
+	if reason != "" {
+		return m, func() tea.Msg {
+			return StatusMsg{Message: reason}
+		}
+	}
+	m.pendingLink = file
+	m.openPrompt(promptTempLink)
+	return m, nil
+}
+
+// answerTempLink handles a key pressed at the temporary link prompt: c copies
+// the link, o opens it. Other keys leave the prompt open.
+func (m Model) answerTempLink(key string) (tea.Model, tea.Cmd) {
+	if key != "c" && key != "o" {
+		return m, nil
+	}
+	file := m.pendingLink
+	m.pendingLink = FileItem{}
+	m.closePrompt()
+	return m, tempLinkCmd(&m.config, file, key == "o")
+}
+
+// tempLinkCmd gets a temporary link to file, then opens it with the default
+// application (a browser or media player, for streaming) if open is set, or
+// copies it to the clipboard.
+func tempLinkCmd(config *Config, file FileItem, open bool) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return ErrorMsg{Error: err.Error()}
+		}
+		link, err := temporaryLink(dbx, file.Path)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to get a temporary link to %s: %v", file.Name, err)}
+		}
+		expires := time.Now().Add(tempLinkLifetime).Format("15:04")
+		if open {
+			if err := openBrowser(link); err != nil {
+				return ErrorMsg{Error: fmt.Sprintf("Failed to open the link: %v", err)}
+			}
+			return StatusMsg{Message: fmt.Sprintf("Opened a temporary link to %s (it expires at %s)", file.Name, expires)}
+		}
+		if err := copyToClipboard(link); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to copy the link: %v", err)}
+		}
+		return StatusMsg{Message: fmt.Sprintf("Copied a temporary link to %s (it expires at %s)", file.Name, expires)}
+	}
+}
+
+// temporaryLink returns a direct link to the file at p that works without
+// signing in, for tempLinkLifetime.
+func temporaryLink(dbx files.Client, p string) (string, error) {
+	res, err := dbx.GetTemporaryLink(files.NewGetTemporaryLinkArg(p))
+	if err != nil {
+		return "", err
+	}
+	return res.Link, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeLinkClient hands out a temporary link for any path, or fails. Any other
+// method panics via the nil embedded interface.
+type fakeLinkClient struct {
+	files.Client
+	err error
+}
+
+func (f *fakeLinkClient) GetTemporaryLink(arg *files.GetTemporaryLinkArg) (*files.GetTemporaryLinkResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &files.GetTemporaryLinkResult{Link: "https://dl.dropboxusercontent.com/temp" + arg.Path}, nil
+}
+
+func TestTemporaryLink(t *testing.T) {
+	link, err := temporaryLink(&fakeLinkClient{}, "/movie.mkv")
+	if err != nil || link != "https://dl.dropboxusercontent.com/temp/movie.mkv" {
+		t.Errorf("link = %q, %v", link, err)
+	}
+	if _, err := temporaryLink(&fakeLinkClient{err: errors.New("boom")}, "/movie.mkv"); err == nil {
+		t.Error("expected the error to be passed on")
+	}
+}
+
+func TestTempLinkPrompt(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "clips", Path: "/clips", IsFolder: true}, {Name: "movie.mkv", Path: "/movie.mkv"}})
+
+	// Folders have no link to stream.
+	updated, cmd := m.runAction(actionTempLink, 1)
+	m = updated.(Model)
+	if msg, ok := cmd().(StatusMsg); !ok || m.prompt != promptNone || !strings.Contains(msg.Message, "is a folder") {
+		t.Errorf("a folder should be refused, got %#v", cmd())
+	}
+
+	m.cursor = 1
+	updated, _ = m.runAction(actionTempLink, 1)
+	m = updated.(Model)
+	if m.prompt != promptTempLink || !strings.Contains(m.promptLabel(), "movie.mkv (expires in 4 hours)") {
+		t.Fatalf("prompt = %v, label %q", m.prompt, m.promptLabel())
+	}
+	// Other keys leave the prompt open; esc closes it.
+	updated, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if m = updated.(Model); m.prompt != promptTempLink {
+		t.Error("an unrelated key shouldn't close the prompt")
+	}
+	updated, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = updated.(Model); m.prompt != promptNone || m.pendingLink.Path != "" {
+		t.Error("esc should close the prompt and forget the file")
+	}
+
+	updated, _ = m.runAction(actionTempLink, 1)
+	m = updated.(Model)
+	updated, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if m = updated.(Model); m.prompt != promptNone || cmd == nil {
+		t.Error("c should close the prompt and fetch the link")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "github.com/mattn/go-runewidth"
+
+// truncateWidth shortens s to at most width terminal cells, ending it with an
+// ellipsis when anything was cut. Widths are display cells, so wide glyphs
+// (emoji, CJK) count as two and combining marks as none.
+func truncateWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(s, width, "…")
+}
+
+// padWidth truncates s to width cells and pads it with spaces to exactly that
+// width, for aligning columns where fmt's %-Ns would count runes instead.
+func padWidth(s string, width int) string {
+	return runewidth.FillRight(truncateWidth(s, width), width)
+}
+
+// truncateMiddle shortens s to at most width cells by cutting from the middle,
+// so both the start and the end (a file's extension, a path's last folder)
+// stay visible: "holiday-photos-…-final.zip".
+func truncateMiddle(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	if width <= 2 {
+		return truncateWidth(s, width)
+	}
+	keep := width - 1 // one cell for the ellipsis
+	tailWidth := keep / 2
+	head := runewidth.Truncate(s, keep-tailWidth, "")
+
+	runes := []rune(s)
+	start, w := len(runes), 0
+	for start > 0 {
+		rw := runewidth.RuneWidth(runes[start-1])
+		if w+rw > tailWidth {
+			break
+		}
+		w += rw
+		start--
+	}
+	return head + "…" + string(runes[start:])
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"report.pdf", 20, "report.pdf"},
+		{"report.pdf", 7, "report…"},
+		{"日本語のファイル.txt", 9, "日本語の…"},
+		{"🎉🎉🎉.txt", 5, "🎉🎉…"},
+		{"café.txt", 5, "café…"},
+		{"anything", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncateWidth(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("truncateWidth(%q, %d) is %d cells wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"report.pdf", 20, "report.pdf"},
+		{"holiday-photos-final.zip", 11, "holid…l.zip"},
+		{"日本語のファイル.txt", 10, "日本….txt"},
+		{"/photos/2024/summer", 12, "/photo…ummer"},
+		{"abc", 1, "…"},
+	}
+	for _, tt := range tests {
+		got := truncateMiddle(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("truncateMiddle(%q, %d) is %d cells wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+func TestPadWidth(t *testing.T) {
+	for _, s := range []string{"a.txt", "日本.txt", "🎉.txt", "a-very-long-file-name.txt"} {
+		if w := runewidth.StringWidth(padWidth(s, 12)); w != 12 {
+			t.Errorf("padWidth(%q, 12) is %d cells wide, want 12", s, w)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names the colors browse mode draws with. Values are anything
+// lipgloss.Color accepts: an ANSI 256 code like "63" or a hex color like
+// "#5f5fff".
+type Theme struct {
+	Cursor   lipgloss.Color `yaml:"cursor"`   // entry under the cursor
+	Selected lipgloss.Color `yaml:"selected"` // selected entries, key names in help
+	Error    lipgloss.Color `yaml:"error"`    // error messages and failures
+	Status   lipgloss.Color `yaml:"status"`   // status messages and successes
+	Path     lipgloss.Color `yaml:"path"`     // the current folder above the list
+	Accent   lipgloss.Color `yaml:"accent"`   // titles, prompts, panel borders
+	Muted    lipgloss.Color `yaml:"muted"`    // hints and secondary text
+	Match    lipgloss.Color `yaml:"match"`    // background of search matches
+}
+
+// themes are the built-in themes, selected with the settings file's theme key.
+var themes = map[string]Theme{
+	"dark": {
+		Cursor:   "63",
+		Selected: "156",
+		Error:    "203",
+		Status:   "156",
+		Path:     "240",
+		Accent:   "63",
+		Muted:    "240",
+		Match:    "214",
+	},
+	"light": {
+		Cursor:   "26",
+		Selected: "28",
+		Error:    "160",
+		Status:   "28",
+		Path:     "243",
+		Accent:   "26",
+		Muted:    "243",
+		Match:    "220",
+	},
+}
+
+// defaultTheme is used when the settings file doesn't choose one.
+const defaultTheme = "dark"
+
+// resolveTheme returns the built-in theme called name with any colors set in
+// overrides replacing its own.
+func resolveTheme(name string, overrides Theme) (Theme, error) {
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (use dark or light)", name)
+	}
+	override := func(dst *lipgloss.Color, src lipgloss.Color) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	override(&theme.Cursor, overrides.Cursor)
+	override(&theme.Selected, overrides.Selected)
+	override(&theme.Error, overrides.Error)
+	override(&theme.Status, overrides.Status)
+	override(&theme.Path, overrides.Path)
+	override(&theme.Accent, overrides.Accent)
+	override(&theme.Muted, overrides.Muted)
+	override(&theme.Match, overrides.Match)
+	return theme, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultListTimeout bounds listing and metadata calls unless list_timeout
+// says otherwise. Downloads have no timeout by default, since a large file
+// can take as long as it takes.
+const defaultListTimeout = 30 * time.Second
+
+// contentHost serves the API calls that move file contents (downloads,
+// exports, and uploads); the rest go to api.dropboxapi.com.
+const contentHost = "content.dropboxapi.com"
+
+// timeoutTransport gives each request its own deadline, which covers reading
+// the response body too: download for those that move file contents, and list
+// for all others. 0 means no timeout.
+type timeoutTransport struct {
+	base     http.RoundTripper
+	list     time.Duration
+	download time.Duration
+}
+
+// callTimeout returns the timeout for one API request.
+func (t timeoutTransport) callTimeout(req *http.Request) time.Duration {
+	if req.URL.Host == contentHost {
+		return t.download
+	}
+	return t.list
+}
+
+func (t timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	d := t.callTimeout(req)
+	if d <= 0 {
+		return t.base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), d)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a request's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// limitCallTimes makes client's requests time out: those that move file
+// contents after download, and the rest after list (the list_timeout and
+// download_timeout settings).
+func limitCallTimes(client *http.Client, list, download time.Duration) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *client
+	limited.Transport = timeoutTransport{base: base, list: list, download: download}
+	return &limited
+}
+
+// isTimeoutError reports whether err is an API call running out of time. The
+// SDK sometimes flattens errors to text, so the message is checked too.
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), context.DeadlineExceeded.Error())
+}
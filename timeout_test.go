@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hangingTransport answers nothing until the request's context ends.
+type hangingTransport struct{}
+
+func (hangingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+// bodyTransport answers at once with a fixed body.
+type bodyTransport struct{}
+
+func (bodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	client := limitCallTimes(&http.Client{Transport: hangingTransport{}}, 10*time.Millisecond, 0)
+	_, err := client.Get("https://api.dropboxapi.com/2/files/list_folder")
+	if !isTimeoutError(err) {
+		t.Errorf("a hung listing should time out, got %v", err)
+	}
+
+	// Downloads have no timeout here, so the call is still waiting when
+	// the test gives up on it.
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "POST", "https://"+contentHost+"/2/files/download", nil)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Do(req)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Errorf("a download shouldn't time out with download_timeout 0s, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	<-done
+
+	// The body stays readable after RoundTrip returns.
+	client = limitCallTimes(&http.Client{Transport: bodyTransport{}}, 10*time.Millisecond, 0)
+	resp, err := client.Get("https://api.dropboxapi.com/2/files/get_metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v", body, err)
+	}
+}
+
+func TestIsTimeoutError(t *testing.T) {
+	if isTimeoutError(nil) || isTimeoutError(fmt.Errorf("path/not_found/")) {
+		t.Error("other errors aren't timeouts")
+	}
+	if !isTimeoutError(fmt.Errorf(`Post "https://api.dropboxapi.com/2/files/list_folder": context deadline exceeded`)) {
+		t.Error("a flattened deadline error is a timeout")
+	}
+}
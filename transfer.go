@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+	"golang.org/x/oauth2"
+)
+
+// Environment variables holding the credentials of the account `dbox
+// transfer` copies into. The app key defaults to DROPBOX_APP_KEY, since one
+// app can be authorized by both accounts.
+const (
+	envDestAppKey       = "DROPBOX_DEST_APP_KEY"
+	envDestAppSecret    = "DROPBOX_DEST_APP_SECRET"
+	envDestRefreshToken = "DROPBOX_DEST_REFRESH_TOKEN"
+)
+
+// errTransfersFailed is returned by runTransfer when some files couldn't be
+// copied, so the process exits non-zero after reporting them.
+var errTransfersFailed = errors.New("some files failed to transfer")
+
+// transferJob is one file to copy: its path in the source account and the
+// path it's written to in the destination account.
+type transferJob struct {
+	Item FileItem
+	To   string
+}
+
+// runTransfer implements `dbox transfer <path>... <dest-folder>`: it copies
+// files and folders from the account dbox is logged in to into another
+// account, streaming each file from one to the other without saving it
+// locally. It's experimental.
+func runTransfer(args []string) error {
+	fs := flag.NewFlagSet("transfer", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbox transfer <path>... <dest-folder>")
+		fmt.Fprintf(fs.Output(), "Copies into the account whose refresh token is in %s (experimental).\n", envDestRefreshToken)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return fmt.Errorf("give at least one path to copy and a destination folder")
+	}
+	sources, dest := fs.Args()[:fs.NArg()-1], normalizeRemotePath(fs.Arg(fs.NArg()-1))
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	src, err := newFilesClient(config)
+	if err != nil {
+		return err
+	}
+	dst, err := newDestFilesClient(config)
+	if err != nil {
+		return err
+	}
+
+	var jobs []transferJob
+	var errs []ItemError
+	for _, p := range sources {
+		root, err := lookupFileItem(src, p)
+		if err == nil {
+			var planned []transferJob
+			planned, err = planTransfer(src, root, dest, config.ListConcurrency)
+			jobs = append(jobs, planned...)
+		}
+		if err != nil {
+			errs = append(errs, ItemError{
+				Item: FileItem{Name: path.Base(p), Path: p},
+				Err:  fmt.Sprintf("Failed to list %s: %v", p, err),
+			})
+		}
+	}
+
+	copied, failed := transferFiles(src, dst, jobs, os.Stdout, config.BinaryUnits)
+	errs = append(errs, failed...)
+	for _, e := range errs {
+		fmt.Fprintf(os.Stdout, "error   %s\n", e.Err)
+	}
+	fmt.Fprintf(os.Stdout, "Transfer complete. Copied: %d, Errors: %d\n", copied, len(errs))
+	if len(errs) > 0 {
+		return errTransfersFailed
+	}
+	return nil
+}
+
+// newDestFilesClient builds a files client for the account `dbox transfer`
+// copies into, from the DROPBOX_DEST_* variables.
+func newDestFilesClient(config *Config) (files.Client, error) {
+	appKey := credentialValue(envDestAppKey)
+	if appKey == "" {
+		appKey = credentialValue(envAppKey)
+	}
+	refreshToken := credentialValue(envDestRefreshToken)
+	if appKey == "" || refreshToken == "" {
+		return nil, fmt.Errorf(`missing destination credentials: export %s (and %s if it's another app).
+Run "dbox login" as the destination account and export the refresh token it
+prints under that name`, envDestRefreshToken, envDestAppKey)
+	}
+	cfg := oauthConfig(appKey, credentialValue(envDestAppSecret))
+	client := cfg.Client(context.Background(), &oauth2.Token{RefreshToken: refreshToken})
+	return files.New(dropbox.Config{Client: config.httpClient(client)}), nil
+}
+
+// planTransfer lists the files to copy for root: the file itself, or every
+// file inside a folder. Each keeps its path relative to root's parent under
+// dest, with its original casing, so /Photos copied to /Backup lands in
+// /Backup/Photos. concurrency is passed to getAllFilesInFolder.
+func planTransfer(dbx files.Client, root FileItem, dest string, concurrency int) ([]transferJob, error) {
+	items := []FileItem{root}
+	if root.IsFolder {
+		var err error
+		if items, err = getAllFilesInFolder(dbx, root.Path, concurrency); err != nil {
+			return nil, err
+		}
+	}
+	parent := path.Dir(root.DisplayPath)
+	var jobs []transferJob
+	for _, item := range items {
+		if item.IsFolder {
+			continue
+		}
+		rel := strings.TrimPrefix(item.DisplayPath, parent)
+		jobs = append(jobs, transferJob{Item: item, To: path.Join("/", dest, rel)})
+	}
+	return jobs, nil
+}
+
+// transferFiles copies each job from src to dst, printing a line to out as
+// each file finishes. Files already at their destination aren't replaced;
+// they're reported as errors. Sizes are printed in binary units when binary is
+// set.
+func transferFiles(src, dst files.Client, jobs []transferJob, out io.Writer, binary bool) (copied int, errs []ItemError) {
+	for _, job := range jobs {
+		if err := transferFile(src, dst, job); err != nil {
+			errs = append(errs, ItemError{Item: job.Item, Err: fmt.Sprintf("Failed to copy %s: %v", job.Item.DisplayPath, err)})
+			continue
+		}
+		copied++
+		fmt.Fprintf(out, "copied  %s → %s (%s)\n", job.Item.DisplayPath, job.To, formatSize(job.Item.Size, binary))
+	}
+	return copied, errs
+}
+
+// transferFile streams one file from src into dst. Dropbox checks the upload
+// against the source's content hash, so a corrupted copy is rejected.
+func transferFile(src, dst files.Client, job transferJob) error {
+	if job.Item.Exportable {
+		return fmt.Errorf("Paper docs can't be downloaded as they are, only exported")
+	}
+	_, contents, err := src.Download(files.NewDownloadArg(job.Item.Path))
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer contents.Close()
+
+	if job.Item.Size >= uploadSessionThreshold {
+		return uploadSession(dst, contents, job.To, job.Item.ContentHash, job.Item.Size, addMode())
+	}
+	arg := files.NewUploadArg(job.To)
+	arg.ContentHash = job.Item.ContentHash
+	_, err = dst.Upload(arg, contents)
+	return err
+}
+
+// addMode returns a WriteMode that fails rather than replace an existing file.
+func addMode() *files.WriteMode {
+	return &files.WriteMode{Tagged: dropbox.Tagged{Tag: files.WriteModeAdd}}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeTransferClient serves downloads of "contents of <path>" and records
+// uploads, failing any to a path in taken.
+type fakeTransferClient struct {
+	*fakeFilesClient
+	uploads map[string]string
+	taken   map[string]bool
+}
+
+func (f *fakeTransferClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	return nil, io.NopCloser(strings.NewReader("contents of " + arg.Path)), nil
+}
+
+func (f *fakeTransferClient) Upload(arg *files.UploadArg, content io.Reader) (*files.FileMetadata, error) {
+	if f.taken[arg.Path] {
+		return nil, io.ErrClosedPipe
+	}
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	f.uploads[arg.Path] = string(body)
+	return &files.FileMetadata{}, nil
+}
+
+func TestTransfer(t *testing.T) {
+	a, trip, b := fakeFile("/photos/a.jpg"), fakeFolder("/photos/trip"), fakeFile("/photos/trip/b.jpg")
+	a.PathDisplay, trip.PathDisplay, b.PathDisplay = "/Photos/A.jpg", "/Photos/Trip", "/Photos/Trip/b.jpg"
+	src := &fakeTransferClient{fakeFilesClient: &fakeFilesClient{tree: map[string][]files.IsMetadata{
+		"/photos":      {a, trip},
+		"/photos/trip": {b},
+	}}}
+	root := FileItem{Name: "Photos", Path: "/photos", DisplayPath: "/Photos", IsFolder: true}
+
+	jobs, err := planTransfer(src, root, "/From personal", defaultListConcurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, job := range jobs {
+		got = append(got, job.To)
+	}
+	if want := "/From personal/Photos/A.jpg,/From personal/Photos/Trip/b.jpg"; strings.Join(got, ",") != want {
+		t.Fatalf("destinations = %v, want %s", got, want)
+	}
+
+	dst := &fakeTransferClient{uploads: map[string]string{}, taken: map[string]bool{"/From personal/Photos/A.jpg": true}}
+	jobs = append(jobs, transferJob{Item: FileItem{Path: "/notes.paper", DisplayPath: "/notes.paper", Exportable: true}, To: "/From personal/notes.paper"})
+	var out bytes.Buffer
+	copied, errs := transferFiles(src, dst, jobs, &out, true)
+	if copied != 1 || len(errs) != 2 {
+		t.Fatalf("copied %d with errors %v, want 1 copied and 2 errors", copied, errs)
+	}
+	if body := dst.uploads["/From personal/Photos/Trip/b.jpg"]; body != "contents of /photos/trip/b.jpg" {
+		t.Errorf("uploaded %q", body)
+	}
+	if !strings.Contains(out.String(), "copied  /Photos/Trip/b.jpg → /From personal/Photos/Trip/b.jpg") {
+		t.Errorf("output = %q", out.String())
+	}
+}
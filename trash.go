@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trashes reports whether deleting items should move them to the trash
+// folder instead: one is set, and none of them are already in it or the trash
+// folder itself (deleting those deletes for real).
+func (m Model) trashes(items []FileItem) bool {
+	trash := strings.ToLower(m.config.Trash)
+	if trash == "" || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if inTrash(item.Path, trash) {
+			return false
+		}
+	}
+	return true
+}
+
+// inTrash reports whether p is the trash folder or inside it.
+func inTrash(p, trash string) bool {
+	return p == trash || withinRemote(p, trash)
+}
+
+// mixesTrash reports whether items are partly inside the trash folder and
+// partly outside it, so one delete would have to both trash and really
+// delete.
+func (m Model) mixesTrash(items []FileItem) bool {
+	trash := strings.ToLower(m.config.Trash)
+	if trash == "" {
+		return false
+	}
+	inside := 0
+	for _, item := range items {
+		if inTrash(item.Path, trash) {
+			inside++
+		}
+	}
+	return inside > 0 && inside < len(items)
+}
+
+// moveToTrash moves items into the trash folder as a batch move. Names
+// already in the trash are renamed by Dropbox rather than clashing.
+func (m Model) moveToTrash(items []FileItem) (tea.Model, tea.Cmd) {
+	trash := strings.ToLower(m.config.Trash)
+	toMove, rejected := movable(items, trash)
+	if len(toMove) == 0 {
+		return m, func() tea.Msg {
+			return MoveCompleteMsg{Dest: trash, Errors: rejected}
+		}
+	}
+	m.job = "move"
+	m.status = fmt.Sprintf("Moving %s to the trash...", pluralize(len(toMove), "item"))
+	m.statusTime = time.Now()
+	return m, moveBatchCmd(&m.config, toMove, trash, rejected, true)
+}
+
+// confirmEmptyTrash asks before emptying the trash folder.
+func (m Model) confirmEmptyTrash() (tea.Model, tea.Cmd) {
+	if m.config.Trash == "" {
+		m.error = "No trash folder is set (see trash in the settings file)"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	if m.job != "" {
+		m.error = "Wait for the current " + m.job + " to finish"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.openPrompt(promptConfirmEmptyTrash)
+	return m, nil
+}
+
+// answerEmptyTrash handles y/n at the empty trash prompt: y deletes the trash
+// folder and everything in it. Other keys leave the prompt open.
+func (m Model) answerEmptyTrash(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		m.closePrompt()
+		trash := FileItem{Name: path.Base(m.config.Trash), Path: strings.ToLower(m.config.Trash), IsFolder: true}
+		m.job = "delete"
+		m.status = "Emptying the trash..."
+		m.statusTime = time.Now()
+		return m, deleteBatchCmd(&m.config, []FileItem{trash})
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
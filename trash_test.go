@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTrashes(t *testing.T) {
+	m := initialModel(&Config{})
+	items := []FileItem{{Name: "a.txt", Path: "/docs/a.txt"}}
+	if m.trashes(items) {
+		t.Error("without a trash folder, deletes should delete")
+	}
+
+	m.config.Trash = "/.Dbox-Trash"
+	if !m.trashes(items) {
+		t.Error("with a trash folder, deletes should move to it")
+	}
+	if m.trashes([]FileItem{{Name: "a.txt", Path: "/.dbox-trash/a.txt"}}) {
+		t.Error("deleting from inside the trash should delete for real")
+	}
+	if m.trashes([]FileItem{{Name: ".Dbox-Trash", Path: "/.dbox-trash", IsFolder: true}}) {
+		t.Error("deleting the trash folder itself shouldn't move it into itself")
+	}
+
+	// Every item counts, not just the first.
+	mixed := []FileItem{{Name: "a.txt", Path: "/docs/a.txt"}, {Name: "b.txt", Path: "/.dbox-trash/b.txt"}}
+	if m.trashes(mixed) || !m.mixesTrash(mixed) {
+		t.Error("a selection partly in the trash shouldn't be trashed as a whole")
+	}
+	next, _ := m.confirmDelete(mixed)
+	if m = next.(Model); m.prompt != promptNone || m.error == "" {
+		t.Error("deleting a selection partly in the trash should explain why not")
+	}
+}
+
+func TestDeleteMovesToTrash(t *testing.T) {
+	m := initialModel(&Config{Trash: "/.dbox-trash"})
+	m.setFiles("", []FileItem{
+		{Name: ".dbox-trash", Path: "/.dbox-trash", IsFolder: true},
+		{Name: "a.txt", Path: "/a.txt"},
+	})
+	m.showHidden = true
+	m.refreshVisible()
+
+	next, _ := m.confirmDelete([]FileItem{m.files[1]})
+	m = next.(Model)
+	if label := m.promptLabel(); !strings.Contains(label, "trash") {
+		t.Errorf("prompt = %q, want it to mention the trash", label)
+	}
+	next, cmd := m.answerDelete("y")
+	m = next.(Model)
+	if m.job != "move" || cmd == nil {
+		t.Errorf("job = %q, want a move into the trash", m.job)
+	}
+
+	// The trash folder itself can't be moved into itself, so it's deleted
+	// for real.
+	m.job = ""
+	next, _ = m.confirmDelete([]FileItem{m.files[0]})
+	if label := next.(Model).promptLabel(); strings.Contains(label, "trash") {
+		t.Errorf("prompt = %q, want a plain delete", label)
+	}
+	next, _ = next.(Model).answerDelete("y")
+	if m = next.(Model); m.job != "delete" {
+		t.Errorf("job = %q, want the trash folder deleted", m.job)
+	}
+}
+
+func TestEmptyTrash(t *testing.T) {
+	m := initialModel(&Config{})
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if m = next.(Model); m.prompt != promptNone || m.error == "" {
+		t.Error("emptying without a trash folder should explain why not")
+	}
+
+	m = initialModel(&Config{Trash: "/.dbox-trash"})
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if m = next.(Model); m.prompt != promptConfirmEmptyTrash {
+		t.Fatal("T should ask before emptying the trash")
+	}
+	next, cmd := m.answerEmptyTrash("y")
+	if m = next.(Model); m.job != "delete" || cmd == nil {
+		t.Errorf("job = %q, want the trash folder deleted", m.job)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeAheadTimeout is how long the type-ahead buffer waits for the next
+// letter before starting over.
+const typeAheadTimeout = time.Second
+
+// typeAheadTimeoutMsg clears the type-ahead buffer if no letter has been
+// typed since the one numbered seq.
+type typeAheadTimeoutMsg struct {
+	seq int
+}
+
+// typeAhead adds runes (typed with alt) to the type-ahead buffer and moves the
+// cursor to the first entry whose name starts with it, ignoring case.
+func (m Model) typeAhead(runes []rune) (tea.Model, tea.Cmd) {
+	m.typeAheadBuf += string(runes)
+	m.typeAheadSeq++
+	seq := m.typeAheadSeq
+	timeout := tea.Tick(typeAheadTimeout, func(time.Time) tea.Msg {
+		return typeAheadTimeoutMsg{seq: seq}
+	})
+
+	if i := m.findPrefix(m.typeAheadBuf); i >= 0 {
+		m.cursor = i
+		m.status = "jump: " + m.typeAheadBuf
+		m.statusTime = time.Now()
+	} else {
+		m.error = "No entry starts with " + m.typeAheadBuf
+		m.errorTime = time.Now()
+	}
+	return m, timeout
+}
+
+// findPrefix returns the index of the first visible entry whose name starts
+// with prefix, ignoring case, or -1 if none does.
+func (m Model) findPrefix(prefix string) int {
+	prefix = strings.ToLower(prefix)
+	for i, file := range m.visible {
+		if strings.HasPrefix(strings.ToLower(file.Name), prefix) {
+			return i
+		}
+	}
+	return -1
+}
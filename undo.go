@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// undoOp is the most recent delete, move, or duplicate, kept so that u can
+// reverse it. Only one is kept; each new operation replaces it.
+type undoOp struct {
+	kind string // "delete", "move", or "duplicate"
+
+	// move: where each entry went, to move it back
+	moves []relocation
+	// delete: deleted files to restore by rev, and how many folders were
+	// deleted too (Dropbox can't restore a folder by rev)
+	restores []restoreTarget
+	folders  int
+	// duplicate: the copies to delete
+	copies []string
+}
+
+// relocation is one entry's move, from its old path to its new one.
+type relocation struct {
+	from, to string
+}
+
+// restoreTarget is a deleted file and the revision to bring back.
+type restoreTarget struct {
+	path, rev string
+}
+
+// UndoCompleteMsg reports the outcome of undoing an operation.
+type UndoCompleteMsg struct {
+	Undone int
+	Errors []string
+}
+
+// deleteUndo records a finished delete, or returns nil if nothing was deleted.
+func deleteUndo(msg DeleteCompleteMsg) *undoOp {
+	if len(msg.Deleted) == 0 {
+		return nil
+	}
+	op := &undoOp{kind: "delete"}
+	for _, item := range msg.Deleted {
+		if rev, ok := msg.Revs[item.Path]; ok && !item.IsFolder {
+			op.restores = append(op.restores, restoreTarget{path: item.Path, rev: rev})
+		} else {
+			op.folders++
+		}
+	}
+	return op
+}
+
+// moveUndo records a finished move, or returns nil if nothing was moved.
+func moveUndo(msg MoveCompleteMsg) *undoOp {
+	if len(msg.Moved) == 0 {
+		return nil
+	}
+	op := &undoOp{kind: "move"}
+	for _, item := range msg.Moved {
+		op.moves = append(op.moves, relocation{from: item.Path, to: msg.NewPaths[item.Path]})
+	}
+	return op
+}
+
+// duplicateUndo records finished copies, or returns nil if none were made.
+func duplicateUndo(msg DuplicateCompleteMsg) *undoOp {
+	if len(msg.Copies) == 0 {
+		return nil
+	}
+	return &undoOp{kind: "duplicate", copies: msg.Copies}
+}
+
+// describe says what undoing op will do, for the confirmation prompt.
+func (op *undoOp) describe() string {
+	switch op.kind {
+	case "move":
+		return fmt.Sprintf("move %s back out of %s/", pluralize(len(op.moves), "item"), parentPath(op.moves[0].to))
+	case "delete":
+		s := fmt.Sprintf("restore %s", pluralize(len(op.restores), "deleted file"))
+		if op.folders > 0 {
+			s += fmt.Sprintf(" (%s can only be restored on the Dropbox website)", pluralize(op.folders, "folder"))
+		}
+		return s
+	case "duplicate":
+		return fmt.Sprintf("delete %s", pluralize(len(op.copies), "duplicate"))
+	}
+	return op.kind
+}
+
+// confirmUndo shows what u would undo and asks before doing it.
+func (m Model) confirmUndo() (tea.Model, tea.Cmd) {
+	if m.lastOp == nil {
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Nothing to undo"}
+		}
+	}
+	if m.job != "" {
+		m.error = "Wait for the current " + m.job + " to finish"
+		m.errorTime = time.Now()
+		return m, nil
+	}
+	m.openPrompt(promptConfirmUndo)
+	return m, nil
+}
+
+// answerUndo handles y/n at the undo prompt. Other keys leave the prompt
+// open.
+func (m Model) answerUndo(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "y":
+		op := m.lastOp
+		m.lastOp = nil
+		m.closePrompt()
+		m.job = "undo"
+		m.status = "Undoing: " + op.describe() + "..."
+		m.statusTime = time.Now()
+		return m, undoCmd(&m.config, op)
+	case "n":
+		return m.cancelPrompt()
+	}
+	return m, nil
+}
+
+// undoCmd reverses op one entry at a time.
+func undoCmd(config *Config, op *undoOp) tea.Cmd {
+	return func() tea.Msg {
+		dbx, err := newFilesClient(config)
+		if err != nil {
+			return UndoCompleteMsg{Errors: []string{err.Error()}}
+		}
+		return undo(dbx, op)
+	}
+}
+
+// undo reverses op: moved entries are moved back, deleted files restored to
+// their last revision, and copies deleted.
+func undo(dbx files.Client, op *undoOp) UndoCompleteMsg {
+	var msg UndoCompleteMsg
+	record := func(p string, err error) {
+		if err != nil {
+			msg.Errors = append(msg.Errors, fmt.Sprintf("%s: %v", p, err))
+			return
+		}
+		msg.Undone++
+	}
+	for _, r := range op.moves {
+		_, err := dbx.MoveV2(files.NewRelocationArg(r.to, r.from))
+		record(r.to, err)
+	}
+	for _, r := range op.restores {
+		_, err := dbx.Restore(files.NewRestoreArg(r.path, r.rev))
+		record(r.path, err)
+	}
+	for _, p := range op.copies {
+		_, err := dbx.DeleteV2(files.NewDeleteArg(p))
+		record(p, err)
+	}
+	sort.Strings(msg.Errors)
+	return msg
+}
+
+// handleUndoComplete reports the undo and reloads the folder. Undone entries
+// may have touched any folder, so the whole cache is dropped.
+func (m Model) handleUndoComplete(msg UndoCompleteMsg) (tea.Model, tea.Cmd) {
+	m.job = ""
+	m.clearCache()
+	m.status = "Undid " + pluralize(msg.Undone, "change")
+	m.statusTime = time.Now()
+	if len(msg.Errors) > 0 {
+		m.error = fmt.Sprintf("Failed to undo %d: %s", len(msg.Errors), strings.Join(msg.Errors, ", "))
+		m.errorTime = time.Now()
+	}
+	return m, m.load(m.currentPath)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeUndoClient records the calls undo makes. Any other method panics via
+// the nil embedded interface.
+type fakeUndoClient struct {
+	files.Client
+	calls []string
+	fail  string // path whose call fails
+}
+
+func (f *fakeUndoClient) call(name, p string) error {
+	f.calls = append(f.calls, name+" "+p)
+	if p == f.fail {
+		return errors.New("not_found")
+	}
+	return nil
+}
+
+func (f *fakeUndoClient) MoveV2(arg *files.RelocationArg) (*files.RelocationResult, error) {
+	return nil, f.call("move", arg.FromPath+" -> "+arg.ToPath)
+}
+
+func (f *fakeUndoClient) Restore(arg *files.RestoreArg) (*files.FileMetadata, error) {
+	return nil, f.call("restore", arg.Path+"@"+arg.Rev)
+}
+
+func (f *fakeUndoClient) DeleteV2(arg *files.DeleteArg) (*files.DeleteResult, error) {
+	return nil, f.call("delete", arg.Path)
+}
+
+func TestUndoOps(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       *undoOp
+		describe string
+		calls    string
+	}{
+		{
+			name: "move",
+			op: moveUndo(MoveCompleteMsg{
+				Dest:     "/archive",
+				Moved:    []FileItem{{Name: "a.txt", Path: "/docs/a.txt"}},
+				NewPaths: map[string]string{"/docs/a.txt": "/archive/a (1).txt"},
+			}),
+			describe: "move 1 item back out of /archive/",
+			calls:    "move /archive/a (1).txt -> /docs/a.txt",
+		},
+		{
+			name: "delete",
+			op: deleteUndo(DeleteCompleteMsg{
+				Deleted: []FileItem{{Name: "a.txt", Path: "/a.txt"}, {Name: "old", Path: "/old", IsFolder: true}},
+				Revs:    map[string]string{"/a.txt": "015f"},
+			}),
+			describe: "restore 1 deleted file (1 folder can only be restored on the Dropbox website)",
+			calls:    "restore /a.txt@015f",
+		},
+		{
+			name:     "duplicate",
+			op:       duplicateUndo(DuplicateCompleteMsg{Copies: []string{"/a (copy).txt", "/b (copy).txt"}}),
+			describe: "delete 2 duplicates",
+			calls:    "delete /a (copy).txt,delete /b (copy).txt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.op.describe(); got != tt.describe {
+				t.Errorf("describe() = %q, want %q", got, tt.describe)
+			}
+			dbx := &fakeUndoClient{}
+			msg := undo(dbx, tt.op)
+			if got := strings.Join(dbx.calls, ","); got != tt.calls {
+				t.Errorf("calls = %q, want %q", got, tt.calls)
+			}
+			if len(msg.Errors) > 0 {
+				t.Errorf("errors = %v", msg.Errors)
+			}
+		})
+	}
+}
+
+func TestUndoNothingDone(t *testing.T) {
+	if deleteUndo(DeleteCompleteMsg{Errors: []ItemError{{Err: "x"}}}) != nil {
+		t.Error("a delete that deleted nothing shouldn't be undoable")
+	}
+	if moveUndo(MoveCompleteMsg{Dest: "/x"}) != nil {
+		t.Error("a move that moved nothing shouldn't be undoable")
+	}
+	if duplicateUndo(DuplicateCompleteMsg{}) != nil {
+		t.Error("a duplicate that copied nothing shouldn't be undoable")
+	}
+}
+
+func TestUndoReportsFailures(t *testing.T) {
+	dbx := &fakeUndoClient{fail: "/b (copy).txt"}
+	msg := undo(dbx, &undoOp{kind: "duplicate", copies: []string{"/a (copy).txt", "/b (copy).txt"}})
+	if msg.Undone != 1 || len(msg.Errors) != 1 {
+		t.Errorf("undone = %d, errors = %v; want 1 and 1", msg.Undone, msg.Errors)
+	}
+}
+
+func TestUndoPrompt(t *testing.T) {
+	m := initialModel(&Config{})
+	next, cmd := m.confirmUndo()
+	if m = next.(Model); m.prompt != promptNone || cmd == nil {
+		t.Fatal("with nothing to undo, u should just say so")
+	}
+
+	next, _ = m.Update(DuplicateCompleteMsg{Copies: []string{"/a (copy).txt"}})
+	m = next.(Model)
+	next, _ = m.confirmUndo()
+	m = next.(Model)
+	if label := m.promptLabel(); label != "undo: delete 1 duplicate? (y/n) " {
+		t.Errorf("prompt = %q", label)
+	}
+	next, cmd = m.answerUndo("y")
+	m = next.(Model)
+	if m.job != "undo" || m.lastOp != nil || cmd == nil {
+		t.Errorf("job = %q, lastOp = %v; want the undo started and forgotten", m.job, m.lastOp)
+	}
+}
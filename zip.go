@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// startZipDownload downloads each folder in selected as a single .zip in the
+// download folder. Files among them download as usual.
+func (m Model) startZipDownload(selected []FileItem) (tea.Model, tea.Cmd) {
+	if m.config.link != nil {
+		return m, func() tea.Msg {
+			return StatusMsg{Message: "Shared links can't be downloaded as zips; press " + m.keys.describe(actionDownload) + " instead"}
+		}
+	}
+	for _, file := range selected {
+		if file.IsFolder {
+			return m, func() tea.Msg {
+				return DownloadMsg{Files: selected, Zip: true}
+			}
+		}
+	}
+	return m, func() tea.Msg {
+		return StatusMsg{Message: "Only folders download as zips; press " + m.keys.describe(actionDownload) + " for files"}
+	}
+}
+
+// downloadZipsThenFiles downloads each folder in fileItems as a zip, then the
+// rest (files, and folders too big to zip) one file at a time.
+func downloadZipsThenFiles(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress) DownloadCompleteMsg {
+	result, rest := downloadZips(dbx, fileItems, config, progress)
+	if len(rest) == 0 {
+		return result
+	}
+	more := downloadWithIndex(dbx, rest, config, progress)
+	result.Downloaded = append(result.Downloaded, more.Downloaded...)
+	result.Overwritten = append(result.Overwritten, more.Overwritten...)
+	result.Skipped = append(result.Skipped, more.Skipped...)
+	result.Ignored = append(result.Ignored, more.Ignored...)
+	result.TooDeep = append(result.TooDeep, more.TooDeep...)
+	result.Errors = append(result.Errors, more.Errors...)
+	for p, local := range more.Renamed {
+		result.Renamed[p] = local
+	}
+	return result
+}
+
+// downloadZips downloads each folder in fileItems as <name>.zip in the
+// download folder, recording where in Renamed. Folders with the same name get
+// their parent folder's name added, as in "photos (2023).zip", like files
+// that would collide (see renameCollisions). It returns the items left to
+// download file by file: anything not a folder, and folders Dropbox won't zip
+// because they're too large or hold too many files.
+func downloadZips(dbx files.Client, fileItems []FileItem, config *Config, progress *downloadProgress) (result DownloadCompleteMsg, rest []FileItem) {
+	result.Renamed = map[string]string{}
+	taken := make(map[string]bool)
+	for _, item := range fileItems {
+		if !item.IsFolder || item.Path == "" {
+			rest = append(rest, item)
+			continue
+		}
+		zipName := claimLocalPath(filepath.Join(config.DownloadPath, item.Name+".zip"), item, taken, nil)
+		zipPath, err := fitPathLimits(zipName, runtime.GOOS)
+		if err == nil {
+			err = os.MkdirAll(config.DownloadPath, config.dirMode())
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, ItemError{Item: item, Err: fmt.Sprintf("Skipped %s: %v", item.Name, err)})
+			continue
+		}
+		_, statErr := os.Stat(zipPath)
+		existed := statErr == nil
+
+		progress.files.Add(1)
+		before := progress.bytes.Load()
+		ctx, done := progress.startFile()
+		err = downloadZipToFile(cancellableClient{Client: dbx, ctx: ctx}, item.Path, zipPath, config.fileMode(), &progress.bytes)
+		cancelled := ctx.Err() != nil
+		done()
+		// A zip's size isn't known until it's here, so it joins the job's
+		// total only then, and files downloaded after it are measured right.
+		progress.total.Add(progress.bytes.Load() - before)
+		switch {
+		case err != nil && zipTooBig(err):
+			progress.files.Add(-1)
+			rest = append(rest, item)
+			continue
+		case err != nil && cancelled:
+			// Skipped with the skip_file key.
+			result.Skipped = append(result.Skipped, item)
+			progress.fileDone(item, fileSkipped)
+			continue
+		case err != nil:
+			result.Errors = append(result.Errors, ItemError{Item: item, Err: fmt.Sprintf("Failed to download %s as a zip: %v", item.Name, err)})
+		default:
+			result.Downloaded = append(result.Downloaded, item)
+			result.Renamed[item.Path] = zipPath
+			if existed {
+				result.Overwritten = append(result.Overwritten, item)
+			}
+			progress.fileDone(item, fileDownloaded)
+			continue
+		}
+		progress.fileDone(item, fileFailed)
+	}
+	return result, rest
+}
+
+// downloadZipToFile streams the folder at dropboxPath, zipped by Dropbox, to
+// zipPath, adding each byte received to counter. The zip is written to a
+// .part file that's renamed into place once complete.
+func downloadZipToFile(dbx files.Client, dropboxPath, zipPath string, mode os.FileMode, counter *atomic.Int64) error {
+	_, contents, err := dbx.DownloadZip(files.NewDownloadZipArg(dropboxPath))
+	if err != nil {
+		return err
+	}
+	partPath := zipPath + partSuffix
+	if err := writeLocalFile(contents, partPath, mode, counter); err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	return os.Rename(partPath, zipPath)
+}
+
+// zipTooBig reports whether Dropbox refused to zip a folder because it's too
+// large or holds too many files.
+func zipTooBig(err error) bool {
+	apiErr, ok := err.(files.DownloadZipAPIError)
+	if !ok || apiErr.EndpointError == nil {
+		return false
+	}
+	tag := apiErr.EndpointError.Tag
+	return tag == files.DownloadZipErrorTooLarge || tag == files.DownloadZipErrorTooManyFiles
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeZipClient zips any folder as zipped, except those in tooBig, which
+// Dropbox refuses. Listings come from tree and every file downloads as
+// "hello". Any other method panics via the nil embedded interface.
+type fakeZipClient struct {
+	files.Client
+	tree   *fakeFilesClient
+	tooBig map[string]bool
+	zipped string
+}
+
+func (f *fakeZipClient) DownloadZip(arg *files.DownloadZipArg) (*files.DownloadZipResult, io.ReadCloser, error) {
+	if f.tooBig[arg.Path] {
+		return nil, nil, files.DownloadZipAPIError{EndpointError: &files.DownloadZipError{
+			Tagged: dropbox.Tagged{Tag: files.DownloadZipErrorTooLarge},
+		}}
+	}
+	return &files.DownloadZipResult{}, io.NopCloser(strings.NewReader(f.zipped)), nil
+}
+
+func (f *fakeZipClient) ListFolder(arg *files.ListFolderArg) (*files.ListFolderResult, error) {
+	return f.tree.ListFolder(arg)
+}
+
+func (f *fakeZipClient) Download(arg *files.DownloadArg) (*files.FileMetadata, io.ReadCloser, error) {
+	return &files.FileMetadata{Size: 5}, io.NopCloser(strings.NewReader("hello")), nil
+}
+
+func TestDownloadZipsThenFiles(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	huge := fakeFile("/huge/a.txt")
+	huge.Size = 5
+	dbx := &fakeZipClient{
+		tree:   &fakeFilesClient{tree: map[string][]files.IsMetadata{"/huge": {huge}}},
+		tooBig: map[string]bool{"/huge": true},
+		zipped: "PK-zip-bytes",
+	}
+	items := []FileItem{
+		{Name: "Photos", Path: "/photos", IsFolder: true},
+		{Name: "huge", Path: "/huge", IsFolder: true},
+		{Name: "note.txt", Path: "/note.txt", Size: 5},
+	}
+	progress := newDownloadProgress(time.Now())
+	result := downloadZipsThenFiles(dbx, items, &Config{DownloadPath: dir}, progress)
+
+	if len(result.Errors) > 0 {
+		t.Fatalf("errors: %+v", result.Errors)
+	}
+	zipPath := filepath.Join(dir, "Photos.zip")
+	if data, _ := os.ReadFile(zipPath); string(data) != "PK-zip-bytes" {
+		t.Errorf("Photos.zip = %q, want the zip Dropbox sent", data)
+	}
+	if got := result.localPath(&Config{DownloadPath: dir}, items[0]); got != zipPath {
+		t.Errorf("zipped folder's local path = %q, want %q", got, zipPath)
+	}
+	// The folder too big to zip, and the plain file, download file by file.
+	for _, rel := range []string{"huge/a.txt", "note.txt"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, rel)); string(data) != "hello" {
+			t.Errorf("%s = %q, want it downloaded on its own", rel, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "huge.zip")); err == nil {
+		t.Error("a folder too big to zip shouldn't leave a zip behind")
+	}
+	// The zip's bytes join the total once it's here.
+	if total, done := progress.total.Load(), progress.done(); total != int64(len("PK-zip-bytes"))+10 || done != total {
+		t.Errorf("progress = %d of %d bytes", done, total)
+	}
+	if got := progress.files.Load(); got != 3 {
+		t.Errorf("files = %d, want the zip and two files", got)
+	}
+}
+
+func TestDownloadZipsSameName(t *testing.T) {
+	dir := t.TempDir()
+	dbx := &fakeZipClient{zipped: "PK"}
+	items := []FileItem{
+		{Name: "photos", Path: "/2023/photos", IsFolder: true},
+		{Name: "Photos", Path: "/2024/photos", IsFolder: true},
+	}
+	result, rest := downloadZips(dbx, items, &Config{DownloadPath: dir}, newDownloadProgress(time.Now()))
+	if len(result.Errors) > 0 || len(rest) > 0 {
+		t.Fatalf("errors %+v, rest %+v", result.Errors, rest)
+	}
+	first, second := result.Renamed["/2023/photos"], result.Renamed["/2024/photos"]
+	if first != filepath.Join(dir, "photos.zip") || second != filepath.Join(dir, "Photos (2024).zip") {
+		t.Errorf("zips = %q and %q, want the second named for its parent", first, second)
+	}
+	if len(result.Overwritten) != 0 {
+		t.Errorf("overwritten = %+v, want neither zip replacing the other", result.Overwritten)
+	}
+}
+
+func TestZipDownloadNeedsAFolder(t *testing.T) {
+	m := initialModel(&Config{})
+	m.setFiles("", []FileItem{{Name: "a.txt", Path: "/a.txt"}, {Name: "docs", Path: "/docs", IsFolder: true}})
+
+	_, cmd := m.runAction(actionDownloadZip, 1)
+	if msg, ok := cmd().(StatusMsg); !ok || !strings.Contains(msg.Message, "Only folders") {
+		t.Errorf("zipping a file should explain why not, got %#v", cmd())
+	}
+	m.cursor = 1
+	_, cmd = m.runAction(actionDownloadZip, 1)
+	if msg, ok := cmd().(DownloadMsg); !ok || !msg.Zip || len(msg.Files) != 1 {
+		t.Errorf("zipping a folder should start a zip download, got %#v", cmd())
+	}
+}